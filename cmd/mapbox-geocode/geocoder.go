@@ -0,0 +1,578 @@
+/**
+ * go-mapbox Bulk Geocoder
+ * Geocodes records with bounded concurrency under a rate limit, writing results in input order
+ * Progress is checkpointed so an interrupted run can be resumed
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/geocode"
+)
+
+// config configures a bulk geocoding run
+type config struct {
+	Input           string
+	Output          string
+	Failures        string
+	Checkpoint      string
+	Format          string
+	Columns         []string
+	Concurrency     int
+	Rate            float64       // Requests per second, zero for no limit
+	BatchSize       int           // Queries per v6 batch request, zero to use the v5 forward endpoint
+	Retries         int           // Retries for rate limited and network errors
+	Backoff         time.Duration // Delay before the first retry, doubled for each subsequent retry
+	Resume          bool
+	CheckpointEvery int // Rows between checkpoints
+	Country         string
+	Types           []geocode.Type
+	Language        []string
+	Permanent       bool
+	Interrupt       <-chan os.Signal // Stops the run, saving progress, when signalled
+}
+
+// stats summarises a bulk geocoding run
+type stats struct {
+	Skipped   int // Rows completed by a previous run
+	Succeeded int
+	Failed    int
+}
+
+// checkpoint records the number of input rows written to the output and failure files,
+// along with the length of each file so rows written after the checkpoint can be discarded on resume
+type checkpoint struct {
+	Input    string `json:"input"`
+	Rows     int    `json:"rows"`
+	Output   int64  `json:"output"`
+	Failures int64  `json:"failures"`
+	Complete bool   `json:"complete"`
+}
+
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cp := checkpoint{}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("Error parsing checkpoint %s (%s)", path, err)
+	}
+	return &cp, nil
+}
+
+// save writes the checkpoint via a temporary file so a crash never leaves it partially written
+func (cp *checkpoint) save(path string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// failuresPath derives the default failures file from the output file, eg. out.csv -> out.failures.csv
+func failuresPath(output string) string {
+	ext := filepath.Ext(output)
+	return strings.TrimSuffix(output, ext) + ".failures" + ext
+}
+
+// errAborted is returned for requests abandoned when a run is stopped
+var errAborted = errors.New("Geocoding aborted")
+
+// task is a group of records geocoded in a single request
+type task struct {
+	seq     int
+	records []*record
+}
+
+// outcome is the result of a task, with either a result or an error per record
+// A fatal error aborts the run without writing the task, so it is retried on resume
+type outcome struct {
+	seq      int
+	records  []*record
+	results  []*result
+	failures []error
+	fatal    error
+}
+
+// geocoder executes tasks against the API
+type geocoder struct {
+	g       *geocode.Geocode
+	c       config
+	limiter <-chan time.Time
+	done    <-chan struct{}
+}
+
+// wait blocks for the rate limiter, returning false if the run was aborted
+func (gc *geocoder) wait() bool {
+	if gc.limiter == nil {
+		select {
+		case <-gc.done:
+			return false
+		default:
+			return true
+		}
+	}
+	select {
+	case <-gc.limiter:
+		return true
+	case <-gc.done:
+		return false
+	}
+}
+
+// retryable returns true for errors that may succeed if retried
+func retryable(err error) bool {
+	if err == base.ErrorAPILimitExceeded {
+		return true
+	}
+	_, ok := err.(*url.Error)
+	return ok
+}
+
+// fatal returns true for errors that would fail every remaining record
+func fatal(err error) bool {
+	return err == base.ErrorAPIUnauthorized || err == errAborted || retryable(err)
+}
+
+// call makes a rate limited request, retrying with exponential backoff
+func (gc *geocoder) call(fn func() error) error {
+	backoff := gc.c.Backoff
+	for attempt := 0; ; attempt++ {
+		if !gc.wait() {
+			return errAborted
+		}
+		err := fn()
+		if err == nil || !retryable(err) || attempt >= gc.c.Retries {
+			return err
+		}
+		select {
+		case <-time.After(backoff):
+		case <-gc.done:
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (gc *geocoder) run(t *task) *outcome {
+	o := &outcome{
+		seq:      t.seq,
+		records:  t.records,
+		results:  make([]*result, len(t.records)),
+		failures: make([]error, len(t.records)),
+	}
+
+	pending := []int{}
+	for i, rec := range t.records {
+		if rec.query == "" {
+			o.failures[i] = fmt.Errorf("Empty query")
+		} else {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return o
+	}
+
+	if gc.c.BatchSize > 0 {
+		gc.batch(o, pending)
+	} else {
+		for _, i := range pending {
+			gc.forward(o, i)
+			if o.fatal != nil {
+				break
+			}
+		}
+	}
+	return o
+}
+
+func (gc *geocoder) forward(o *outcome, i int) {
+	opts := geocode.ForwardRequestOpts{
+		Filter:    base.Filter{Country: gc.c.Country, Language: gc.c.Language},
+		Types:     gc.c.Types,
+		Limit:     1,
+		Permanent: gc.c.Permanent,
+	}
+
+	var resp *geocode.ForwardResponse
+	err := gc.call(func() (err error) {
+		resp, err = gc.g.Forward(o.records[i].query, &opts)
+		return err
+	})
+
+	switch {
+	case err != nil && fatal(err):
+		o.fatal = err
+	case err != nil:
+		o.failures[i] = err
+	case resp.FeatureCollection == nil || len(resp.Features) == 0:
+		o.failures[i] = fmt.Errorf("No results")
+	default:
+		f := resp.Features[0]
+		res := &result{PlaceName: f.PlaceName, Relevance: f.Relevance}
+		if len(f.Center) == 2 {
+			res.Longitude, res.Latitude = f.Center[0], f.Center[1]
+		}
+		if len(f.PlaceType) > 0 {
+			res.MatchType = f.PlaceType[0]
+		}
+		o.results[i] = res
+	}
+}
+
+func (gc *geocoder) batch(o *outcome, pending []int) {
+	queries := make([]geocode.BatchQuery, len(pending))
+	for j, i := range pending {
+		q := geocode.NewForwardBatchQuery(o.records[i].query)
+		q.Country = gc.c.Country
+		q.Types = gc.c.Types
+		q.Limit = 1
+		if len(gc.c.Language) > 0 {
+			q.Language = gc.c.Language[0]
+		}
+		queries[j] = q
+	}
+
+	var resp *geocode.BatchResponseV6
+	err := gc.call(func() (err error) {
+		resp, err = gc.g.BatchV6(queries, gc.c.Permanent)
+		return err
+	})
+	if err != nil && fatal(err) {
+		o.fatal = err
+		return
+	}
+
+	for j, i := range pending {
+		if err != nil {
+			o.failures[i] = err
+			continue
+		}
+		features := resp.Batch[j].Features
+		if len(features) == 0 {
+			o.failures[i] = fmt.Errorf("No results")
+			continue
+		}
+		f := features[0]
+		loc := f.Location()
+		res := &result{
+			Latitude:  loc.Latitude,
+			Longitude: loc.Longitude,
+			PlaceName: f.Properties.FullAddress,
+			MatchType: string(f.Properties.FeatureType),
+		}
+		if res.PlaceName == "" {
+			res.PlaceName = strings.TrimSuffix(f.Properties.Name+", "+f.Properties.PlaceFormatted, ", ")
+		}
+		if f.Properties.MatchCode != nil {
+			res.Confidence = string(f.Properties.MatchCode.Confidence)
+		}
+		o.results[i] = res
+	}
+}
+
+// openOutput opens an output file, or when resuming truncates it to the checkpointed length and appends
+func openOutput(path string, resume bool, offset int64) (*os.File, error) {
+	if !resume {
+		return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.Size() < offset {
+		err = fmt.Errorf("File %s is shorter than its checkpoint (%d < %d bytes)", path, info.Size(), offset)
+	}
+	if err == nil {
+		err = f.Truncate(offset)
+	}
+	if err == nil {
+		_, err = f.Seek(offset, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// flushOutput flushes buffered records and syncs the file, returning its length
+func flushOutput(w writer, f *os.File) (int64, error) {
+	if err := w.Flush(); err != nil {
+		return 0, err
+	}
+	if err := f.Sync(); err != nil {
+		return 0, err
+	}
+	return f.Seek(0, io.SeekCurrent)
+}
+
+// run geocodes the input file, writing results, failures and checkpoints
+func run(g *geocode.Geocode, c config) (*stats, error) {
+	if c.Concurrency < 1 {
+		c.Concurrency = 1
+	}
+	if c.CheckpointEvery < 1 {
+		c.CheckpointEvery = 100
+	}
+	if c.BatchSize > geocode.MaxBatchQueries {
+		return nil, fmt.Errorf("Batch size (%d) exceeds maximum of %d", c.BatchSize, geocode.MaxBatchQueries)
+	}
+	if c.Failures == "" {
+		c.Failures = failuresPath(c.Output)
+	}
+	if c.Checkpoint == "" {
+		c.Checkpoint = c.Output + ".checkpoint"
+	}
+	if c.Format == "" {
+		f, err := formatFromPath(c.Input)
+		if err != nil {
+			return nil, err
+		}
+		c.Format = f
+	}
+
+	cp := &checkpoint{Input: c.Input}
+	if c.Resume {
+		prev, err := loadCheckpoint(c.Checkpoint)
+		switch {
+		case os.IsNotExist(err):
+			c.Resume = false
+		case err != nil:
+			return nil, err
+		case prev.Input != c.Input:
+			return nil, fmt.Errorf("Checkpoint %s is for input %s", c.Checkpoint, prev.Input)
+		default:
+			cp = prev
+		}
+	}
+
+	s := &stats{Skipped: cp.Rows}
+	if cp.Complete {
+		return s, nil
+	}
+
+	in, err := os.Open(c.Input)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	r, err := newReader(in, c.Format, c.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := openOutput(c.Output, c.Resume, cp.Output)
+	if err != nil {
+		return nil, err
+	}
+	defer out.Close()
+	failOut, err := openOutput(c.Failures, c.Resume, cp.Failures)
+	if err != nil {
+		return nil, err
+	}
+	defer failOut.Close()
+
+	results, _ := newWriter(out, c.Format, false)
+	failures, _ := newWriter(failOut, c.Format, true)
+	if !c.Resume {
+		var header []string
+		if cr, ok := r.(*csvReader); ok {
+			header = cr.header
+		}
+		if err := results.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if err := failures.WriteHeader(header); err != nil {
+			return nil, err
+		}
+	}
+
+	done := make(chan struct{})
+	var abort sync.Once
+	stop := func() { abort.Do(func() { close(done) }) }
+	defer stop()
+
+	// Interrupted runs stop issuing requests, then write completed rows and save the checkpoint
+	if c.Interrupt != nil {
+		go func() {
+			select {
+			case <-c.Interrupt:
+				stop()
+			case <-done:
+			}
+		}()
+	}
+
+	gc := &geocoder{g: g, c: c, done: done}
+	if c.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / c.Rate))
+		defer ticker.Stop()
+		gc.limiter = ticker.C
+	}
+
+	// Producer groups unprocessed records into tasks
+	skip := cp.Rows
+	tasks := make(chan *task, c.Concurrency)
+	var readErr error
+	go func() {
+		defer close(tasks)
+		size := c.BatchSize
+		if size < 1 {
+			size = 1
+		}
+		seq := 0
+		t := &task{}
+		send := func() bool {
+			if len(t.records) == 0 {
+				return true
+			}
+			select {
+			case tasks <- t:
+			case <-done:
+				return false
+			}
+			seq++
+			t = &task{seq: seq}
+			return true
+		}
+		for {
+			rec, err := r.Read()
+			if err == io.EOF {
+				send()
+				return
+			}
+			if err != nil {
+				readErr = err
+				send()
+				return
+			}
+			if rec.index < skip {
+				continue
+			}
+			t.records = append(t.records, rec)
+			if len(t.records) >= size && !send() {
+				return
+			}
+		}
+	}()
+
+	outcomes := make(chan *outcome, c.Concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < c.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range tasks {
+				outcomes <- gc.run(t)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	// Files are synced before the checkpoint is saved so the recorded lengths are always on disk
+	flush := func() (err error) {
+		if cp.Output, err = flushOutput(results, out); err != nil {
+			return err
+		}
+		if cp.Failures, err = flushOutput(failures, failOut); err != nil {
+			return err
+		}
+		return cp.save(c.Checkpoint)
+	}
+
+	// Outcomes are written in task order so the checkpoint is always a contiguous prefix of the input
+	var runErr error
+	pending := map[int]*outcome{}
+	next, sinceCheckpoint := 0, 0
+	for o := range outcomes {
+		if runErr != nil {
+			continue
+		}
+		pending[o.seq] = o
+		for p, ok := pending[next]; ok; p, ok = pending[next] {
+			delete(pending, next)
+			next++
+
+			if p.fatal != nil {
+				runErr = p.fatal
+				stop()
+				break
+			}
+			for i, rec := range p.records {
+				var werr error
+				if p.failures[i] != nil {
+					werr = failures.Write(rec, nil, p.failures[i])
+					s.Failed++
+				} else {
+					werr = results.Write(rec, p.results[i], nil)
+					s.Succeeded++
+				}
+				if werr != nil {
+					runErr = werr
+					stop()
+					break
+				}
+			}
+			if runErr != nil {
+				break
+			}
+
+			cp.Rows = p.records[len(p.records)-1].index + 1
+			sinceCheckpoint += len(p.records)
+			if sinceCheckpoint >= c.CheckpointEvery {
+				if runErr = flush(); runErr != nil {
+					stop()
+					break
+				}
+				sinceCheckpoint = 0
+			}
+		}
+	}
+
+	if runErr == nil {
+		runErr = readErr
+	}
+	if runErr == nil {
+		// The producer stops reading input once the run is interrupted
+		select {
+		case <-done:
+			runErr = errAborted
+		default:
+		}
+	}
+	cp.Complete = runErr == nil
+	if err := flush(); err != nil && runErr == nil {
+		runErr = err
+	}
+
+	return s, runErr
+}
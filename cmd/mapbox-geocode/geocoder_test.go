@@ -0,0 +1,313 @@
+/**
+ * go-mapbox Bulk Geocoder Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+	"github.com/tumasgiu/go-mapbox/lib/geocode"
+)
+
+const testForwardPrefix = "/geocoding/v5/mapbox.places/"
+
+// forwardHandler responds to v5 forward requests with a single feature, or no features for queries containing nowhere
+func forwardHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	q := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, testForwardPrefix), ".json")
+
+	features := []map[string]interface{}{}
+	if !strings.Contains(q, "nowhere") {
+		features = append(features, map[string]interface{}{
+			"place_name": q,
+			"place_type": []string{"address"},
+			"relevance":  0.9,
+			"center":     []float64{float64(len(q)), 10},
+		})
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"type": "FeatureCollection", "features": features})
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	f, err := os.Open(path)
+	require.Nil(t, err)
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	require.Nil(t, err)
+	return rows
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+	return info.Size()
+}
+
+// assertIDs checks the output contains each of the remaining rows exactly once, in order
+func assertIDs(t *testing.T, path string) {
+	ids := []string{}
+	for _, r := range readCSV(t, path)[1:] {
+		ids = append(ids, r[0])
+	}
+	assert.EqualValues(t, []string{"0", "1", "2", "3", "4", "6", "7", "8", "10", "11", "12", "13", "14", "15", "16", "17", "18", "19"}, ids)
+}
+
+func TestBulkGeocode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mapbox-geocode")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "in.csv")
+	rows := []string{"id,street,city"}
+	for i := 0; i < 20; i++ {
+		street := fmt.Sprintf("%d Main St", i)
+		switch i {
+		case 5:
+			street = "nowhere"
+		case 9:
+			street = ""
+		}
+		city := "Springfield"
+		if i == 9 {
+			city = ""
+		}
+		rows = append(rows, fmt.Sprintf("%d,%s,%s", i, street, city))
+	}
+	require.Nil(t, ioutil.WriteFile(input, []byte(strings.Join(rows, "\n")+"\n"), 0644))
+
+	output := filepath.Join(dir, "out.csv")
+	c := config{
+		Input:           input,
+		Output:          output,
+		Columns:         []string{"street", "city"},
+		Concurrency:     4,
+		CheckpointEvery: 1,
+	}
+
+	t.Run("Writes results in order with failures separated", func(t *testing.T) {
+		b, done := basetest.NewBase(t, forwardHandler)
+		g := geocode.NewGeocode(b)
+		defer done()
+
+		s, err := run(g, c)
+		require.Nil(t, err)
+		assert.EqualValues(t, &stats{Succeeded: 18, Failed: 2}, s)
+
+		results := readCSV(t, output)
+		require.Len(t, results, 19)
+		assert.EqualValues(t, append([]string{"id", "street", "city"}, resultColumns...), results[0])
+		assert.EqualValues(t, []string{"0", "0 Main St", "Springfield", "0 Main St, Springfield", "10", "22", "0 Main St, Springfield", "address", "0.9", ""}, results[1])
+		assertIDs(t, output)
+
+		failures := readCSV(t, failuresPath(output))
+		assert.EqualValues(t, [][]string{
+			{"id", "street", "city", "query", "error"},
+			{"5", "nowhere", "Springfield", "nowhere, Springfield", "No results"},
+			{"9", "", "", "", "Empty query"},
+		}, failures)
+
+		cp, err := loadCheckpoint(output + ".checkpoint")
+		require.Nil(t, err)
+		assert.EqualValues(t, &checkpoint{
+			Input:    input,
+			Rows:     20,
+			Output:   fileSize(t, output),
+			Failures: fileSize(t, failuresPath(output)),
+			Complete: true,
+		}, cp)
+	})
+
+	t.Run("Resumes after a fatal error", func(t *testing.T) {
+		b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Path, "12 Main") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			forwardHandler(w, r)
+		})
+		g := geocode.NewGeocode(b)
+		s, err := run(g, c)
+		done()
+		assert.EqualValues(t, base.ErrorAPIUnauthorized, err)
+		assert.Equal(t, 12, s.Succeeded+s.Failed)
+
+		cp, err := loadCheckpoint(output + ".checkpoint")
+		require.Nil(t, err)
+		assert.Equal(t, 12, cp.Rows)
+		assert.False(t, cp.Complete)
+
+		var requests int32
+		b, done = basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			forwardHandler(w, r)
+		})
+		g = geocode.NewGeocode(b)
+		defer done()
+
+		resumed := c
+		resumed.Resume = true
+		s, err = run(g, resumed)
+		require.Nil(t, err)
+		assert.EqualValues(t, &stats{Skipped: 12, Succeeded: 8}, s)
+		assert.EqualValues(t, 8, requests)
+
+		results := readCSV(t, output)
+		require.Len(t, results, 19)
+		for i, r := range results[11:] {
+			assert.Equal(t, fmt.Sprint(i+12), r[0])
+		}
+		assert.Len(t, readCSV(t, failuresPath(output)), 3)
+
+		// A completed run is not repeated
+		s, err = run(g, resumed)
+		require.Nil(t, err)
+		assert.EqualValues(t, &stats{Skipped: 20}, s)
+		assert.EqualValues(t, 8, requests)
+	})
+
+	t.Run("Discards rows written after the checkpoint", func(t *testing.T) {
+		b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Path, "12 Main") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			forwardHandler(w, r)
+		})
+		g := geocode.NewGeocode(b)
+		_, err := run(g, c)
+		done()
+		require.NotNil(t, err)
+
+		// Simulate a crash after buffered rows were flushed but before the checkpoint was saved,
+		// leaving duplicate and torn rows in both files
+		for _, path := range []string{output, failuresPath(output)} {
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+			require.Nil(t, err)
+			_, err = f.WriteString("12,12 Main St,Springfield,12 Main St\n13,\"13 Ma")
+			require.Nil(t, err)
+			require.Nil(t, f.Close())
+		}
+
+		b, done = basetest.NewBase(t, forwardHandler)
+		g = geocode.NewGeocode(b)
+		defer done()
+
+		resumed := c
+		resumed.Resume = true
+		s, err := run(g, resumed)
+		require.Nil(t, err)
+		assert.EqualValues(t, &stats{Skipped: 12, Succeeded: 8}, s)
+
+		assertIDs(t, output)
+		assert.Len(t, readCSV(t, failuresPath(output)), 3)
+	})
+
+	t.Run("Saves progress when interrupted", func(t *testing.T) {
+		interrupt := make(chan os.Signal, 1)
+		b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			if strings.Contains(r.URL.Path, "12 Main") {
+				interrupt <- os.Interrupt
+			}
+			forwardHandler(w, r)
+		})
+		g := geocode.NewGeocode(b)
+		interrupted := c
+		interrupted.Interrupt = interrupt
+		s, err := run(g, interrupted)
+		done()
+		assert.Equal(t, errAborted, err)
+
+		cp, err := loadCheckpoint(output + ".checkpoint")
+		require.Nil(t, err)
+		assert.False(t, cp.Complete)
+		assert.Equal(t, s.Succeeded+s.Failed, cp.Rows)
+		assert.True(t, cp.Rows < 20)
+		assert.Equal(t, fileSize(t, output), cp.Output)
+		assert.Equal(t, fileSize(t, failuresPath(output)), cp.Failures)
+
+		b, done = basetest.NewBase(t, forwardHandler)
+		g = geocode.NewGeocode(b)
+		defer done()
+
+		resumed := c
+		resumed.Resume = true
+		s, err = run(g, resumed)
+		require.Nil(t, err)
+		assert.Equal(t, 20, s.Skipped+s.Succeeded+s.Failed)
+		assertIDs(t, output)
+	})
+
+	t.Run("Batches JSONL records", func(t *testing.T) {
+		b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/search/geocode/v6/batch", r.URL.Path)
+			queries := []geocode.BatchQuery{}
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&queries))
+
+			resp := geocode.BatchResponseV6{}
+			for _, q := range queries {
+				fc := geocode.FeatureCollectionV6{Type: "FeatureCollection"}
+				if !strings.Contains(q.Q, "nowhere") {
+					f := geocode.FeatureV6{}
+					f.Properties.FeatureType = geocode.Address
+					f.Properties.FullAddress = q.Q
+					f.Properties.Coordinates = geocode.CoordinatesV6{Longitude: 1, Latitude: 2}
+					f.Properties.MatchCode = &geocode.MatchCode{Confidence: geocode.ConfidenceExact}
+					fc.Features = append(fc.Features, f)
+				}
+				resp.Batch = append(resp.Batch, fc)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&resp)
+		})
+		g := geocode.NewGeocode(b)
+		defer done()
+
+		input := filepath.Join(dir, "in.jsonl")
+		lines := `{"id": 1, "address": "1 Main St"}
+{"id": 2, "address": "nowhere"}
+
+{"id": 3, "address": "3 Main St"}
+`
+		require.Nil(t, ioutil.WriteFile(input, []byte(lines), 0644))
+
+		output := filepath.Join(dir, "out.jsonl")
+		s, err := run(g, config{Input: input, Output: output, Columns: []string{"address"}, BatchSize: 2})
+		require.Nil(t, err)
+		assert.EqualValues(t, &stats{Succeeded: 2, Failed: 1}, s)
+
+		data, err := ioutil.ReadFile(output)
+		require.Nil(t, err)
+		assert.Equal(t, `{"address":"1 Main St","geocode":{"latitude":2,"longitude":1,"place_name":"1 Main St","match_type":"address","confidence":"exact"},"id":1}
+{"address":"3 Main St","geocode":{"latitude":2,"longitude":1,"place_name":"3 Main St","match_type":"address","confidence":"exact"},"id":3}
+`, string(data))
+
+		data, err = ioutil.ReadFile(failuresPath(output))
+		require.Nil(t, err)
+		assert.Equal(t, `{"address":"nowhere","error":"No results","id":2}`+"\n", string(data))
+	})
+
+	t.Run("Rejects missing columns", func(t *testing.T) {
+		bad := c
+		bad.Columns = []string{"postcode"}
+		_, err := run(nil, bad)
+		assert.NotNil(t, err)
+	})
+}
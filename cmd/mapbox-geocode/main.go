@@ -0,0 +1,101 @@
+/**
+ * go-mapbox Bulk Geocoder
+ * Geocodes CSV or JSONL files of addresses, writing results and failures to separate files
+ * The token is read from the MAPBOX_TOKEN environment variable
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/geocode"
+)
+
+// splitList splits a comma separated flag value, ignoring empty entries
+func splitList(s string) []string {
+	out := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func main() {
+	input := flag.String("input", "", "Input file (.csv or .jsonl)")
+	output := flag.String("output", "", "Output file, in the same format as the input")
+	failuresFlag := flag.String("failures", "", "Failures file (default <output>.failures.<ext>)")
+	checkpointFlag := flag.String("checkpoint", "", "Checkpoint file (default <output>.checkpoint)")
+	format := flag.String("format", "", "Input format, csv or jsonl (default from the input extension)")
+	columns := flag.String("columns", "address", "Comma separated columns joined to form the query")
+	concurrency := flag.Int("concurrency", 4, "Number of concurrent requests")
+	rate := flag.Float64("rate", 10, "Maximum requests per second (0 for no limit)")
+	batchSize := flag.Int("batch", 0, "Queries per v6 batch request (default 0 uses the v5 forward endpoint)")
+	retries := flag.Int("retries", 3, "Retries for rate limited and network errors")
+	backoff := flag.Duration("backoff", time.Second, "Delay before the first retry, doubled for each retry")
+	resume := flag.Bool("resume", false, "Resume from the checkpoint, appending to the output and failures files")
+	checkpointEvery := flag.Int("checkpoint-every", 100, "Rows between checkpoints")
+	country := flag.String("country", "", "Comma separated ISO 3166 alpha 2 country codes to limit results to")
+	types := flag.String("types", "", "Comma separated feature types to limit results to")
+	language := flag.String("language", "", "Comma separated IETF language tags for results")
+	permanent := flag.Bool("permanent", false, "Use permanent geocoding, required to store results")
+	debug := flag.Bool("debug", false, "Enable API debug output")
+	flag.Parse()
+
+	if *input == "" || *output == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	b, err := base.NewBase(os.Getenv("MAPBOX_TOKEN"))
+	if err != nil {
+		log.Fatalf("Error creating API client (%s), set MAPBOX_TOKEN", err)
+	}
+	b.SetDebug(*debug)
+
+	c := config{
+		Input:           *input,
+		Output:          *output,
+		Failures:        *failuresFlag,
+		Checkpoint:      *checkpointFlag,
+		Format:          *format,
+		Columns:         splitList(*columns),
+		Concurrency:     *concurrency,
+		Rate:            *rate,
+		BatchSize:       *batchSize,
+		Retries:         *retries,
+		Backoff:         *backoff,
+		Resume:          *resume,
+		CheckpointEvery: *checkpointEvery,
+		Country:         *country,
+		Language:        splitList(*language),
+		Permanent:       *permanent,
+	}
+	for _, t := range splitList(*types) {
+		c.Types = append(c.Types, geocode.Type(t))
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	c.Interrupt = interrupt
+
+	s, err := run(geocode.NewGeocode(b), c)
+	if s != nil {
+		log.Printf("Geocoded %d rows (%d failed, %d skipped from a previous run)", s.Succeeded+s.Failed, s.Failed, s.Skipped)
+	}
+	if err != nil {
+		log.Fatalf("Geocoding stopped (%s), rerun with -resume to continue", err)
+	}
+}
@@ -0,0 +1,259 @@
+/**
+ * go-mapbox Bulk Geocoder Records
+ * Reads input rows and writes results and failures as CSV or JSONL
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// formatFromPath infers the record format from a file extension
+func formatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return formatCSV, nil
+	case ".jsonl", ".ndjson":
+		return formatJSONL, nil
+	default:
+		return "", fmt.Errorf("Unable to infer format from %s, set -format to csv or jsonl", path)
+	}
+}
+
+// record is a single input row
+type record struct {
+	index  int
+	fields []string               // CSV values
+	object map[string]interface{} // JSONL object
+	query  string
+}
+
+// result is the outcome of geocoding a record
+type result struct {
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	PlaceName  string  `json:"place_name"`
+	MatchType  string  `json:"match_type"`
+	Relevance  float64 `json:"relevance,omitempty"`
+	Confidence string  `json:"confidence,omitempty"`
+}
+
+// reader reads records, returning io.EOF once complete
+type reader interface {
+	Read() (*record, error)
+}
+
+// newReader creates a record reader building queries from the provided columns
+func newReader(r io.Reader, format string, columns []string) (reader, error) {
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("At least one query column is required")
+	}
+
+	switch format {
+	case formatCSV:
+		c := csv.NewReader(r)
+		c.FieldsPerRecord = -1
+		header, err := c.Read()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CSV header (%s)", err)
+		}
+		indices := make([]int, len(columns))
+		for i, name := range columns {
+			indices[i] = -1
+			for j, h := range header {
+				if strings.EqualFold(strings.TrimSpace(h), name) {
+					indices[i] = j
+				}
+			}
+			if indices[i] < 0 {
+				return nil, fmt.Errorf("Column %s not found in CSV header", name)
+			}
+		}
+		return &csvReader{reader: c, header: header, indices: indices}, nil
+
+	case formatJSONL:
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		return &jsonlReader{scanner: s, columns: columns}, nil
+
+	default:
+		return nil, fmt.Errorf("Unsupported format (%s)", format)
+	}
+}
+
+// joinQuery joins non-empty query components
+func joinQuery(parts []string) string {
+	out := []string{}
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+type csvReader struct {
+	reader  *csv.Reader
+	header  []string
+	indices []int
+	count   int
+}
+
+func (r *csvReader) Read() (*record, error) {
+	fields, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(r.indices))
+	for i, j := range r.indices {
+		if j < len(fields) {
+			parts[i] = fields[j]
+		}
+	}
+
+	rec := &record{index: r.count, fields: fields, query: joinQuery(parts)}
+	r.count++
+	return rec, nil
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	columns []string
+	count   int
+}
+
+func (r *jsonlReader) Read() (*record, error) {
+	for r.scanner.Scan() {
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rec := &record{index: r.count}
+		r.count++
+
+		if err := json.Unmarshal([]byte(line), &rec.object); err != nil {
+			return nil, fmt.Errorf("Error parsing JSONL record %d (%s)", rec.index, err)
+		}
+
+		parts := make([]string, len(r.columns))
+		for i, c := range r.columns {
+			if v, ok := rec.object[c]; ok && v != nil {
+				parts[i] = fmt.Sprint(v)
+			}
+		}
+		rec.query = joinQuery(parts)
+
+		return rec, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// writer writes geocoded records, or failed records with their error
+type writer interface {
+	WriteHeader(header []string) error
+	Write(rec *record, res *result, failure error) error
+	Flush() error
+}
+
+var (
+	resultColumns  = []string{"query", "latitude", "longitude", "place_name", "match_type", "relevance", "confidence"}
+	failureColumns = []string{"query", "error"}
+)
+
+func newWriter(w io.Writer, format string, failures bool) (writer, error) {
+	switch format {
+	case formatCSV:
+		columns := resultColumns
+		if failures {
+			columns = failureColumns
+		}
+		return &csvWriter{writer: csv.NewWriter(w), columns: columns}, nil
+	case formatJSONL:
+		return &jsonlWriter{writer: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported format (%s)", format)
+	}
+}
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []string
+}
+
+func (w *csvWriter) WriteHeader(header []string) error {
+	return w.writer.Write(append(append([]string{}, header...), w.columns...))
+}
+
+func (w *csvWriter) Write(rec *record, res *result, failure error) error {
+	row := append(append([]string{}, rec.fields...), rec.query)
+	if failure != nil {
+		row = append(row, failure.Error())
+	} else {
+		relevance := ""
+		if res.Relevance != 0 {
+			relevance = strconv.FormatFloat(res.Relevance, 'f', -1, 64)
+		}
+		row = append(row,
+			strconv.FormatFloat(res.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(res.Longitude, 'f', -1, 64),
+			res.PlaceName, res.MatchType, relevance, res.Confidence)
+	}
+	return w.writer.Write(row)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type jsonlWriter struct {
+	writer *bufio.Writer
+}
+
+func (w *jsonlWriter) WriteHeader(header []string) error {
+	return nil
+}
+
+func (w *jsonlWriter) Write(rec *record, res *result, failure error) error {
+	out := make(map[string]interface{}, len(rec.object)+1)
+	for k, v := range rec.object {
+		out[k] = v
+	}
+	if failure != nil {
+		out["error"] = failure.Error()
+	} else {
+		out["geocode"] = res
+	}
+
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	w.writer.Write(data)
+	return w.writer.WriteByte('\n')
+}
+
+func (w *jsonlWriter) Flush() error {
+	return w.writer.Flush()
+}
@@ -0,0 +1,159 @@
+/**
+ * go-mapbox Tile Proxy
+ * Serves cached XYZ map tiles over HTTP without exposing the API token
+ * The token is read from the MAPBOX_TOKEN environment variable
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+	"github.com/tumasgiu/go-mapbox/lib/tileserver"
+)
+
+func parseBBox(s string) (base.BoundingBox, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("Bounding box must be west,south,east,north (received %s)", s)
+	}
+	bbox := make(base.BoundingBox, 4)
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid bounding box value (%s)", p)
+		}
+		bbox[i] = v
+	}
+	return bbox, nil
+}
+
+// parseTilesets parses a comma separated list of mapID:format[@2x] tilesets for TileJSON and WMTS
+func parseTilesets(s string, minZoom, maxZoom uint64, bbox base.BoundingBox) ([]tileserver.Tileset, error) {
+	if s == "" {
+		return nil, nil
+	}
+	tilesets := []tileserver.Tileset{}
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		sep := strings.LastIndex(v, ":")
+		if sep <= 0 || sep == len(v)-1 {
+			return nil, fmt.Errorf("Tileset must be mapID:format[@2x] (received %s)", v)
+		}
+		t := tileserver.Tileset{MapID: maps.MapID(v[:sep]), MinZoom: minZoom, MaxZoom: maxZoom, BBox: bbox}
+		format := v[sep+1:]
+		if strings.HasSuffix(format, "@2x") {
+			t.HighDPI = true
+			format = strings.TrimSuffix(format, "@2x")
+		}
+		t.Format = maps.MapFormat(format)
+		tilesets = append(tilesets, t)
+	}
+	return tilesets, nil
+}
+
+// defaultCacheSize is the default number of tiles held by the memory cache
+const defaultCacheSize = 1024
+
+// newCache creates a tile cache from a flag value of none, memory or file:<path>
+// Memory caches hold at most size tiles, evicting the least recently used
+func newCache(s string, size int) (maps.Cache, error) {
+	switch {
+	case s == "none":
+		return nil, nil
+	case s == "memory":
+		if size <= 0 {
+			return nil, fmt.Errorf("Memory cache size must be positive (received %d)", size)
+		}
+		return maps.NewBoundedMemoryCache(size), nil
+	case strings.HasPrefix(s, "file:"):
+		return maps.NewFileCache(strings.TrimPrefix(s, "file:"))
+	default:
+		return nil, fmt.Errorf("Unrecognised cache (%s), expected none, memory or file:<path>", s)
+	}
+}
+
+func main() {
+	listen := flag.String("listen", ":8080", "Address to listen on")
+	cacheFlag := flag.String("cache", "memory", "Tile cache (none, memory or file:<path>)")
+	cacheSize := flag.Int("cache-size", defaultCacheSize, "Maximum number of tiles held by the memory cache")
+	mapIDs := flag.String("map-ids", "", "Comma separated list of allowed map IDs (default all)")
+	minZoom := flag.Uint64("min-zoom", 0, "Minimum zoom level served")
+	maxZoom := flag.Uint64("max-zoom", tileserver.DefaultMaxZoom, "Maximum zoom level served")
+	bboxFlag := flag.String("bbox", "", "Bounding box restriction as west,south,east,north (default none)")
+	maxAge := flag.Duration("max-age", tileserver.DefaultMaxAge, "Cache-Control max age for tiles")
+	tilesetsFlag := flag.String("tilesets", "", "Comma separated mapID:format[@2x] tilesets published via TileJSON and WMTS")
+	baseURL := flag.String("base-url", "", "Public base URL for TileJSON and WMTS documents (default request host)")
+	debug := flag.Bool("debug", false, "Enable API debug output")
+	flag.Parse()
+
+	b, err := base.NewBase(os.Getenv("MAPBOX_TOKEN"))
+	if err != nil {
+		log.Fatalf("Error creating API client (%s), set MAPBOX_TOKEN", err)
+	}
+	b.SetDebug(*debug)
+
+	cache, err := newCache(*cacheFlag, *cacheSize)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bbox, err := parseBBox(*bboxFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tilesets, err := parseTilesets(*tilesetsFlag, *minZoom, *maxZoom, bbox)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config := tileserver.Config{
+		MinZoom:  *minZoom,
+		MaxZoom:  *maxZoom,
+		BBox:     bbox,
+		MaxAge:   *maxAge,
+		Tilesets: tilesets,
+		BaseURL:  *baseURL,
+	}
+	if *mapIDs != "" {
+		for _, id := range strings.Split(*mapIDs, ",") {
+			config.MapIDs = append(config.MapIDs, maps.MapID(strings.TrimSpace(id)))
+		}
+	}
+
+	m := maps.NewMaps(b)
+	if cache != nil {
+		m.SetCache(cache)
+	}
+
+	server, err := tileserver.NewServer(m, config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := &http.Server{
+		Addr:         *listen,
+		Handler:      server,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+
+	log.Printf("Serving tiles on %s", *listen)
+	log.Fatal(s.ListenAndServe())
+}
@@ -6,4 +6,5 @@ require (
 	github.com/google/go-querystring v1.0.0
 	github.com/sirupsen/logrus v1.4.2
 	github.com/stretchr/testify v1.4.0
+	golang.org/x/image v0.0.0-20190802002840-cff245a6509b
 )
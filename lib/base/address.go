@@ -0,0 +1,112 @@
+/**
+ * go-mapbox Base Module Addresses
+ * Parses typed address components from geocoding features and their context
+ * See https://www.mapbox.com/api-documentation/#geocoding for API information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"regexp"
+	"strings"
+)
+
+// houseNumberPattern matches house numbers such as 12, 12A or 12-14, but not ordinals such as 5th
+var houseNumberPattern = regexp.MustCompile(`^[0-9]+[a-zA-Z]?([-/][0-9]+[a-zA-Z]?)?$`)
+
+// splitHouseNumber splits a leading house number from an address line, eg. 1600 Pennsylvania Ave NW
+func splitHouseNumber(line string) (houseNumber, street string) {
+	fields := strings.Fields(line)
+	if len(fields) > 1 && houseNumberPattern.MatchString(fields[0]) {
+		return fields[0], strings.Join(fields[1:], " ")
+	}
+	return "", strings.TrimSpace(line)
+}
+
+// Address contains the components of a geocoded address
+type Address struct {
+	HouseNumber  string
+	Street       string
+	Neighborhood string
+	Locality     string
+	Place        string
+	District     string
+	Postcode     string
+	Region       string
+	RegionCode   string // ISO 3166-2 subdivision code, eg. US-DC
+	Country      string
+	CountryCode  string // ISO 3166-1 alpha 2 code, eg. US
+}
+
+// Type returns the type of a context entry from its ID, eg. postcode for postcode.123
+func (c *Context) Type() string {
+	return idType(c.ID)
+}
+
+// FeatureType returns the type of a feature, from the place type or the ID prefix
+func (f *Feature) FeatureType() string {
+	if len(f.PlaceType) > 0 {
+		return f.PlaceType[0]
+	}
+	return idType(f.ID)
+}
+
+func idType(id string) string {
+	if i := strings.Index(id, "."); i >= 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// set fills the address component for a type with a name and (optional) short code
+func (a *Address) set(t, name, shortCode string) {
+	switch t {
+	case "address":
+		a.Street = name
+	case "neighborhood":
+		a.Neighborhood = name
+	case "locality":
+		a.Locality = name
+	case "place":
+		a.Place = name
+	case "district":
+		a.District = name
+	case "postcode":
+		a.Postcode = name
+	case "region":
+		a.Region = name
+		if shortCode != "" {
+			a.RegionCode = strings.ToUpper(shortCode)
+		}
+	case "country":
+		a.Country = name
+		if shortCode != "" {
+			a.CountryCode = strings.ToUpper(shortCode)
+		}
+	}
+}
+
+// Address returns the typed address components of a feature, filled from the feature text and
+// properties, and the context of features containing it
+func (f *Feature) Address() Address {
+	a := Address{}
+
+	for _, c := range f.Context {
+		a.set(c.Type(), c.Text, c.ShortCode)
+	}
+
+	switch t := f.FeatureType(); t {
+	case "address":
+		a.HouseNumber = f.HouseNumber
+		a.Street = f.Text
+	case "poi":
+		a.HouseNumber, a.Street = splitHouseNumber(f.Properties.Address)
+	default:
+		a.set(t, f.Text, f.Properties.ShortCode)
+	}
+
+	return a
+}
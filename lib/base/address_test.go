@@ -0,0 +1,89 @@
+/**
+ * go-mapbox Base Module Address Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddress(t *testing.T) {
+	t.Run("Parses address features", func(t *testing.T) {
+		f := Feature{}
+		err := json.Unmarshal([]byte(`{
+			"id": "address.4356035406756260",
+			"type": "Feature",
+			"place_type": ["address"],
+			"text": "Lincoln Memorial Circle Northwest",
+			"address": "2",
+			"place_name": "2 Lincoln Memorial Circle Northwest, Washington, District of Columbia 20037, United States",
+			"properties": {"accuracy": "rooftop"},
+			"context": [
+				{"id": "neighborhood.2918495", "text": "West End"},
+				{"id": "postcode.12511893070335510", "text": "20037"},
+				{"id": "place.12334081418246050", "wikidata": "Q61", "text": "Washington"},
+				{"id": "region.14064402149979320", "short_code": "US-DC", "wikidata": "Q3551781", "text": "District of Columbia"},
+				{"id": "country.9053006287256050", "short_code": "us", "wikidata": "Q30", "text": "United States"}
+			]
+		}`), &f)
+		assert.Nil(t, err)
+
+		assert.EqualValues(t, Address{
+			HouseNumber:  "2",
+			Street:       "Lincoln Memorial Circle Northwest",
+			Neighborhood: "West End",
+			Place:        "Washington",
+			Postcode:     "20037",
+			Region:       "District of Columbia",
+			RegionCode:   "US-DC",
+			Country:      "United States",
+			CountryCode:  "US",
+		}, f.Address())
+	})
+
+	t.Run("Parses points of interest", func(t *testing.T) {
+		f := Feature{
+			ID:         "poi.1",
+			Text:       "Te Papa",
+			Properties: Properties{Address: "55 Cable Street", Maki: "museum"},
+			Context: []Context{
+				{ID: "locality.1", Text: "Te Aro"},
+				{ID: "place.2", Text: "Wellington"},
+				{ID: "country.3", Text: "New Zealand", ShortCode: "nz"},
+			},
+		}
+		assert.EqualValues(t, Address{HouseNumber: "55", Street: "Cable Street", Locality: "Te Aro", Place: "Wellington", Country: "New Zealand", CountryCode: "NZ"}, f.Address())
+
+		for line, want := range map[string][2]string{
+			"1600 Pennsylvania Ave NW": {"1600", "Pennsylvania Ave NW"},
+			"12A High St":              {"12A", "High St"},
+			"12-14 High St":            {"12-14", "High St"},
+			"5th Avenue":               {"", "5th Avenue"},
+			"Cable Street":             {"", "Cable Street"},
+			"42":                       {"", "42"},
+		} {
+			f.Properties.Address = line
+			a := f.Address()
+			assert.EqualValues(t, want, [2]string{a.HouseNumber, a.Street}, line)
+		}
+	})
+
+	t.Run("Parses administrative features", func(t *testing.T) {
+		f := Feature{
+			ID:         "region.1",
+			PlaceType:  []string{"region"},
+			Text:       "Wellington",
+			Properties: Properties{ShortCode: "NZ-WGN"},
+			Context:    []Context{{ID: "country.3", Text: "New Zealand", ShortCode: "nz"}},
+		}
+		assert.EqualValues(t, Address{Region: "Wellington", RegionCode: "NZ-WGN", Country: "New Zealand", CountryCode: "NZ"}, f.Address())
+		assert.EqualValues(t, "country", f.Context[0].Type())
+	})
+}
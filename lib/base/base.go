@@ -10,6 +10,7 @@
 package base
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
 )
 
 const (
@@ -29,8 +31,9 @@ const (
 
 // Base Mapbox API base
 type Base struct {
-	token string
-	debug bool
+	token   string
+	debug   bool
+	baseURL string
 }
 
 // NewBase Create a new API base instance
@@ -39,7 +42,7 @@ func NewBase(token string) (*Base, error) {
 		return nil, errors.New("Mapbox API token not found")
 	}
 
-	b := &Base{}
+	b := &Base{baseURL: BaseURL}
 
 	b.token = token
 
@@ -48,7 +51,12 @@ func NewBase(token string) (*Base, error) {
 
 // SetDebug enables debug output for API calls
 func (b *Base) SetDebug(debug bool) {
-	b.debug = true
+	b.debug = debug
+}
+
+// SetBaseURL overrides the API base URL, for use with proxies or test servers
+func (b *Base) SetBaseURL(baseURL string) {
+	b.baseURL = strings.TrimSuffix(baseURL, "/")
 }
 
 type MapboxApiMessage struct {
@@ -57,6 +65,11 @@ type MapboxApiMessage struct {
 
 // Request make a get with the provided path string and return the response if successful
 func (b *Base) Request(method, path string, query *url.Values, body io.Reader) (*http.Response, error) {
+	return b.RequestWithContext(context.Background(), method, path, query, body)
+}
+
+// RequestWithContext makes a request that is cancelled along with the provided context
+func (b *Base) RequestWithContext(ctx context.Context, method, path string, query *url.Values, body io.Reader) (*http.Response, error) {
 	q := query
 	if q == nil {
 		q = &url.Values{}
@@ -65,14 +78,14 @@ func (b *Base) Request(method, path string, query *url.Values, body io.Reader) (
 	q.Set("access_token", b.token)
 
 	// Generate URL
-	url := fmt.Sprintf("%s/%s", BaseURL, path)
+	url := fmt.Sprintf("%s/%s", b.baseURL, path)
 
 	if b.debug {
 		fmt.Printf("URL: %s\n", url)
 	}
 
 	// Create request object
-	request, err := http.NewRequest(method, url, body)
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -101,28 +114,47 @@ func (b *Base) Request(method, path string, query *url.Values, body io.Reader) (
 		fmt.Printf("Response: %s", string(data))
 	}
 
-	if resp.StatusCode == statusRateLimitExceeded {
+	// Responses are discarded for these errors, so are drained and closed to release the connection
+	switch resp.StatusCode {
+	case statusRateLimitExceeded:
+		discard(resp)
 		return nil, ErrorAPILimitExceeded
-	}
-	if resp.StatusCode == http.StatusUnauthorized {
+	case http.StatusUnauthorized:
+		discard(resp)
 		return nil, ErrorAPIUnauthorized
 	}
 
 	return resp, nil
 }
 
+// discard reads and closes a response body so the connection can be reused
+func discard(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
 // QueryBase Query the mapbox API and fill the provided instance with the returned JSON
 // TODO: Rename this
 func (b *Base) QueryBase(query string, v *url.Values, inst interface{}) error {
+	return b.QueryBody(http.MethodGet, query, v, nil, inst)
+}
+
+// QueryBody makes a request with the provided method and (optional) body, and fills the provided instance with the returned JSON
+func (b *Base) QueryBody(method, query string, v *url.Values, body io.Reader, inst interface{}) error {
+	return b.QueryBodyWithContext(context.Background(), method, query, v, body, inst)
+}
+
+// QueryBodyWithContext makes a request as QueryBody that is cancelled along with the provided context
+func (b *Base) QueryBodyWithContext(ctx context.Context, method, query string, v *url.Values, body io.Reader, inst interface{}) error {
 	// Make request
-	resp, err := b.Request(http.MethodGet, query, v, nil)
+	resp, err := b.RequestWithContext(ctx, method, query, v, body)
 	if err != nil && (resp == nil || resp.StatusCode != http.StatusBadRequest) {
 		return err
 	}
 	defer resp.Body.Close()
 
 	// Read body into buffer
-	body, err := ioutil.ReadAll(resp.Body)
+	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return err
 	}
@@ -130,15 +162,24 @@ func (b *Base) QueryBase(query string, v *url.Values, inst interface{}) error {
 	// Handle bad requests with messages
 	if resp.StatusCode == http.StatusBadRequest {
 		apiMessage := MapboxApiMessage{}
-		messageErr := json.Unmarshal(body, &apiMessage)
+		messageErr := json.Unmarshal(data, &apiMessage)
 		if messageErr == nil {
 			return fmt.Errorf("api error: %s", apiMessage.Message)
 		}
 		return fmt.Errorf("Bad Request (400) - no message")
 	}
 
+	// Handle other client and server errors, such as validation failures (422)
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiMessage := MapboxApiMessage{}
+		if json.Unmarshal(data, &apiMessage) == nil && apiMessage.Message != "" {
+			return fmt.Errorf("api error (%d): %s", resp.StatusCode, apiMessage.Message)
+		}
+		return fmt.Errorf("api error (%d)", resp.StatusCode)
+	}
+
 	// Attempt to decode body into inst type
-	err = json.Unmarshal(body, &inst)
+	err = json.Unmarshal(data, &inst)
 	if err != nil {
 		return err
 	}
@@ -149,9 +190,14 @@ func (b *Base) QueryBase(query string, v *url.Values, inst interface{}) error {
 // Query the mapbox API
 // TODO: Depreciate this
 func (b *Base) Query(api, version, mode, query string, v *url.Values, inst interface{}) error {
+	return b.QueryWithContext(context.Background(), api, version, mode, query, v, inst)
+}
+
+// QueryWithContext queries the mapbox API as Query, cancelling the request along with the provided context
+func (b *Base) QueryWithContext(ctx context.Context, api, version, mode, query string, v *url.Values, inst interface{}) error {
 
 	// Generate URL
 	queryString := fmt.Sprintf("%s/%s/%s/%s", api, version, mode, query)
 
-	return b.QueryBase(queryString, v, inst)
+	return b.QueryBodyWithContext(ctx, http.MethodGet, queryString, v, nil, inst)
 }
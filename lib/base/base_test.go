@@ -0,0 +1,52 @@
+/**
+ * go-mapbox Base Module Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestErrors(t *testing.T) {
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/unauthorized" {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else {
+			w.WriteHeader(statusRateLimitExceeded)
+		}
+		w.Write([]byte(`{"message": "Too Many Requests"}`))
+	}))
+	var connections int32
+	s.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	s.Start()
+	defer s.Close()
+
+	b, err := NewBase("synthetic")
+	require.Nil(t, err)
+	b.SetBaseURL(s.URL)
+
+	for i := 0; i < 3; i++ {
+		_, err := b.Request(http.MethodGet, "limited", nil, nil)
+		assert.Equal(t, ErrorAPILimitExceeded, err)
+	}
+	_, err = b.Request(http.MethodGet, "unauthorized", nil, nil)
+	assert.Equal(t, ErrorAPIUnauthorized, err)
+
+	// Discarded responses are closed, so the connection is reused for retries
+	assert.EqualValues(t, 1, atomic.LoadInt32(&connections))
+}
@@ -0,0 +1,30 @@
+/**
+ * go-mapbox Base Test Utilities
+ * Provides API instances backed by local test servers, so module tests run without an API token
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package basetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// NewBase starts a test server with the provided handler, returning an API base that sends
+// requests to the server and a function to close it
+func NewBase(t testing.TB, handler http.HandlerFunc) (*base.Base, func()) {
+	s := httptest.NewServer(handler)
+	b, err := base.NewBase("synthetic")
+	if err != nil {
+		s.Close()
+		t.Fatal(err)
+	}
+	b.SetBaseURL(s.URL)
+	return b, s.Close
+}
@@ -0,0 +1,63 @@
+/**
+ * go-mapbox Base Module Filters
+ * Options shared by the geocoding and search APIs to bias and filter results
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Filter contains the options shared by geocoding and search requests to bias and filter results
+// Request options embed a Filter so these are encoded alongside their own parameters
+type Filter struct {
+	Proximity   []float64   `url:"proximity,comma,omitempty"`
+	ProximityIP bool        `url:"-"` // Bias results towards the location of the requesting IP address
+	BBox        BoundingBox `url:"bbox,comma,omitempty"`
+	Country     string      `url:"country,omitempty"`        // Comma separated ISO 3166 alpha 2 country codes
+	Language    []string    `url:"language,comma,omitempty"` // IETF language tags, some APIs accept only one
+}
+
+// Validate checks filter options before a request is made
+func (f *Filter) Validate() error {
+	if f.ProximityIP && f.Proximity != nil {
+		return fmt.Errorf("Proximity and ProximityIP can not both be set")
+	}
+	if err := ValidateLocation("Proximity", f.Proximity); err != nil {
+		return err
+	}
+	if err := ValidateBBox(f.BBox); err != nil {
+		return err
+	}
+	if err := ValidateCountry(f.Country); err != nil {
+		return err
+	}
+	return ValidateLanguages(f.Language)
+}
+
+// ValidateSingleLanguage checks the filter for APIs accepting a single language
+func (f *Filter) ValidateSingleLanguage() error {
+	if len(f.Language) > 1 {
+		return fmt.Errorf("Only one language may be requested (received %d)", len(f.Language))
+	}
+	return nil
+}
+
+// Values encodes request options embedding the filter as query parameters
+func (f *Filter) Values(opts interface{}) (url.Values, error) {
+	v, err := query.Values(opts)
+	if err != nil {
+		return nil, err
+	}
+	if f.ProximityIP {
+		v.Set("proximity", "ip")
+	}
+	return v, nil
+}
@@ -0,0 +1,56 @@
+/**
+ * go-mapbox Base Module Filter Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	type opts struct {
+		Filter
+		Limit uint `url:"limit,omitempty"`
+	}
+
+	t.Run("Encodes embedded filters", func(t *testing.T) {
+		o := opts{Filter: Filter{BBox: BoundingBox{174, -42, 175, -41}, Country: "nz", Language: []string{"en", "mi"}}, Limit: 2}
+		assert.Nil(t, o.Filter.Validate())
+
+		v, err := o.Filter.Values(&o)
+		assert.Nil(t, err)
+		assert.EqualValues(t, url.Values{
+			"bbox":     {"174,-42,175,-41"},
+			"country":  {"nz"},
+			"language": {"en,mi"},
+			"limit":    {"2"},
+		}, v)
+
+		o = opts{Filter: Filter{ProximityIP: true}}
+		v, err = o.Filter.Values(&o)
+		assert.Nil(t, err)
+		assert.EqualValues(t, url.Values{"proximity": {"ip"}}, v)
+	})
+
+	t.Run("Validates filters", func(t *testing.T) {
+		for name, f := range map[string]Filter{
+			"proximity and ip": {Proximity: []float64{0, 0}, ProximityIP: true},
+			"proximity":        {Proximity: []float64{181, 0}},
+			"bbox":             {BBox: BoundingBox{1, 1, 0, 0}},
+			"country":          {Country: "nzl"},
+			"language":         {Language: []string{"english"}},
+		} {
+			assert.NotNil(t, f.Validate(), name)
+		}
+
+		assert.Nil(t, (&Filter{Language: []string{"en"}}).ValidateSingleLanguage())
+		assert.NotNil(t, (&Filter{Language: []string{"en", "fr"}}).ValidateSingleLanguage())
+	})
+}
@@ -0,0 +1,52 @@
+/**
+ * go-mapbox Base Module Geodesy
+ * Provides common spherical earth helpers for API modules
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"math"
+)
+
+// EarthRadius is the mean radius of the earth in metres
+const EarthRadius = 6371008.8
+
+// Distance calculates the great circle (haversine) distance between two locations in metres
+func Distance(a, b Location) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Interpolate returns the location at fraction f along the straight line between a and b
+// This is linear in lat/lng space and intended for short segments such as route geometries
+func Interpolate(a, b Location, f float64) Location {
+	return Location{
+		Latitude:  a.Latitude + (b.Latitude-a.Latitude)*f,
+		Longitude: a.Longitude + (b.Longitude-a.Longitude)*f,
+	}
+}
+
+// Destination calculates the location reached by travelling the provided distance (in metres) from a
+// location along a great circle with the initial bearing (in degrees clockwise from north)
+func Destination(loc Location, bearing, distance float64) Location {
+	lat1, lng1 := loc.Latitude*math.Pi/180, loc.Longitude*math.Pi/180
+	brng := bearing * math.Pi / 180
+	d := distance / EarthRadius
+
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(brng))
+	lng2 := lng1 + math.Atan2(math.Sin(brng)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+
+	return Location{
+		Latitude:  lat2 * 180 / math.Pi,
+		Longitude: math.Mod(lng2*180/math.Pi+540, 360) - 180,
+	}
+}
@@ -0,0 +1,106 @@
+/**
+ * go-mapbox Base Module Polylines
+ * Encodes and decodes the polyline geometry format returned by the routing APIs
+ * See https://developers.google.com/maps/documentation/utilities/polylinealgorithm for format information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// PolylinePrecision is the precision used by the "polyline" geometry type
+	PolylinePrecision uint = 5
+	// Polyline6Precision is the precision used by the "polyline6" geometry type
+	Polyline6Precision uint = 6
+)
+
+// DecodePolyline decodes an encoded polyline string with the provided precision into a list of locations
+func DecodePolyline(encoded string, precision uint) ([]Location, error) {
+	factor := math.Pow10(int(precision))
+
+	locations := make([]Location, 0, len(encoded)/4)
+
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		dLat, n, err := decodePolylineValue(encoded[i:])
+		if err != nil {
+			return nil, fmt.Errorf("Polyline decode error at offset %d (%s)", i, err)
+		}
+		i += n
+
+		dLng, n, err := decodePolylineValue(encoded[i:])
+		if err != nil {
+			return nil, fmt.Errorf("Polyline decode error at offset %d (%s)", i, err)
+		}
+		i += n
+
+		lat += dLat
+		lng += dLng
+
+		locations = append(locations, Location{
+			Latitude:  float64(lat) / factor,
+			Longitude: float64(lng) / factor,
+		})
+	}
+
+	return locations, nil
+}
+
+func decodePolylineValue(s string) (int64, int, error) {
+	var result int64
+	var shift uint
+	for i := 0; i < len(s); i++ {
+		b := int64(s[i]) - 63
+		if b < 0 || b > 0x3f {
+			return 0, 0, fmt.Errorf("invalid character '%c'", s[i])
+		}
+		result |= (b & 0x1f) << shift
+		shift += 5
+		if b < 0x20 {
+			if result&1 != 0 {
+				return ^(result >> 1), i + 1, nil
+			}
+			return result >> 1, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("unterminated value")
+}
+
+// EncodePolyline encodes a list of locations into a polyline string with the provided precision
+func EncodePolyline(locations []Location, precision uint) string {
+	factor := math.Pow10(int(precision))
+
+	var sb strings.Builder
+	var lastLat, lastLng int64
+	for _, l := range locations {
+		lat := int64(math.Round(l.Latitude * factor))
+		lng := int64(math.Round(l.Longitude * factor))
+
+		encodePolylineValue(&sb, lat-lastLat)
+		encodePolylineValue(&sb, lng-lastLng)
+
+		lastLat, lastLng = lat, lng
+	}
+
+	return sb.String()
+}
+
+func encodePolylineValue(sb *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
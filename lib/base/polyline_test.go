@@ -0,0 +1,48 @@
+/**
+ * go-mapbox Base Module Polyline Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolyline(t *testing.T) {
+	encoded := "_p~iF~ps|U_ulLnnqC_mqNvxq`@"
+	locations := []Location{
+		{Latitude: 38.5, Longitude: -120.2},
+		{Latitude: 40.7, Longitude: -120.95},
+		{Latitude: 43.252, Longitude: -126.453},
+	}
+
+	t.Run("Decodes polylines", func(t *testing.T) {
+		decoded, err := DecodePolyline(encoded, PolylinePrecision)
+		assert.Nil(t, err)
+		assert.Len(t, decoded, len(locations))
+		for i := range locations {
+			assert.InDelta(t, locations[i].Latitude, decoded[i].Latitude, 1e-9)
+			assert.InDelta(t, locations[i].Longitude, decoded[i].Longitude, 1e-9)
+		}
+	})
+
+	t.Run("Encodes polylines", func(t *testing.T) {
+		assert.EqualValues(t, encoded, EncodePolyline(locations, PolylinePrecision))
+	})
+
+	t.Run("Round trips polyline6", func(t *testing.T) {
+		decoded, err := DecodePolyline(EncodePolyline(locations, Polyline6Precision), Polyline6Precision)
+		assert.Nil(t, err)
+		assert.EqualValues(t, locations, decoded)
+	})
+
+	t.Run("Rejects truncated polylines", func(t *testing.T) {
+		_, err := DecodePolyline(encoded[:len(encoded)-1], PolylinePrecision)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,25 @@
+/**
+ * go-mapbox Base Module Sessions
+ * Generates session tokens used to group related search requests for billing
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// NewSessionToken generates a random (version 4) UUID for use as a session token
+func NewSessionToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Error generating session token (%s)", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,28 @@
+/**
+ * go-mapbox Base Module Session Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSessionToken(t *testing.T) {
+	a, err := NewSessionToken()
+	require.Nil(t, err)
+	b, err := NewSessionToken()
+	require.Nil(t, err)
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	assert.Regexp(t, uuid, a)
+	assert.Regexp(t, uuid, b)
+	assert.NotEqual(t, a, b)
+}
@@ -11,6 +11,16 @@ package base
 
 type Point []float64
 
+// Bool returns a pointer to a bool, for optional (tri-state) request options
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Float64 returns a pointer to a float64, for optional options where zero is a valid value
+func Float64(v float64) *float64 {
+	return &v
+}
+
 type Location struct {
 	Latitude  float64 `json:"lat"`
 	Longitude float64 `json:"lng"`
@@ -31,25 +41,29 @@ type Context struct {
 }
 
 type Properties struct {
-	Category string `json:"category"`
-	Tel      string `json:"tel"`
-	Wikidata string `json:"wikidata"`
-	Landmark bool   `json:"landmark"`
-	Maki     string `json:"short_code"`
+	Category  string `json:"category"`
+	Tel       string `json:"tel"`
+	Wikidata  string `json:"wikidata"`
+	Landmark  bool   `json:"landmark"`
+	Maki      string `json:"maki"`
+	ShortCode string `json:"short_code"`
+	Address   string `json:"address"`
+	Accuracy  string `json:"accuracy"` // Accuracy of address features, eg. rooftop or interpolated
 }
 
 type Feature struct {
-	ID         string      `json:"id"`
-	Type       string      `json:"type"`
-	Text       string      `json:"text"`
-	PlaceName  string      `json:"place_name"`
-	PlaceType  []string    `json:"place_type"`
-	Relevance  float64     `json:"relevance"`
-	Properties Properties  `json:"properties"`
-	BBox       BoundingBox `json:"bbox"`
-	Center     Point       `json:"center"`
-	Geometry   Geometry    `json:"geometry"`
-	Context    []Context   `json:"context"`
+	ID          string      `json:"id"`
+	Type        string      `json:"type"`
+	Text        string      `json:"text"`
+	PlaceName   string      `json:"place_name"`
+	PlaceType   []string    `json:"place_type"`
+	HouseNumber string      `json:"address"` // House number of address features
+	Relevance   float64     `json:"relevance"`
+	Properties  Properties  `json:"properties"`
+	BBox        BoundingBox `json:"bbox"`
+	Center      Point       `json:"center"`
+	Geometry    Geometry    `json:"geometry"`
+	Context     []Context   `json:"context"`
 }
 
 type FeatureCollection struct {
@@ -0,0 +1,94 @@
+/**
+ * go-mapbox Base Module Validation
+ * Validates request options shared between API modules before they are sent to the API
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package base
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	countryPattern   = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+	languagePattern  = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+	worldviewPattern = regexp.MustCompile(`^[a-zA-Z]{2}$`)
+)
+
+// ValidateCountry checks a comma separated list of ISO 3166 alpha 2 country codes
+func ValidateCountry(country string) error {
+	if country == "" {
+		return nil
+	}
+	for _, c := range strings.Split(country, ",") {
+		if !countryPattern.MatchString(c) {
+			return fmt.Errorf("Country must be a comma separated list of ISO 3166 alpha 2 codes (received %s)", country)
+		}
+	}
+	return nil
+}
+
+// ValidateLanguages checks a list of IETF language tags
+func ValidateLanguages(languages []string) error {
+	for _, l := range languages {
+		if !languagePattern.MatchString(l) {
+			return fmt.Errorf("Language must be an IETF language tag (received %s)", l)
+		}
+	}
+	return nil
+}
+
+// ValidateWorldview checks an ISO 3166 alpha 2 worldview code
+func ValidateWorldview(worldview string) error {
+	if worldview != "" && !worldviewPattern.MatchString(worldview) {
+		return fmt.Errorf("Worldview must be an ISO 3166 alpha 2 code (received %s)", worldview)
+	}
+	return nil
+}
+
+// ValidateType checks a requested type is in the set of types supported by an API
+func ValidateType(t string, supported map[string]bool) error {
+	if !supported[t] {
+		return fmt.Errorf("Unsupported type (%s)", t)
+	}
+	return nil
+}
+
+// ValidateLocation checks an (optional) [longitude, latitude] pair, using the name in errors
+func ValidateLocation(name string, loc []float64) error {
+	if loc == nil {
+		return nil
+	}
+	if len(loc) != 2 {
+		return fmt.Errorf("%s must contain a longitude and latitude (received %d values)", name, len(loc))
+	}
+	if loc[0] < -180 || loc[0] > 180 || loc[1] < -90 || loc[1] > 90 {
+		return fmt.Errorf("%s (%f,%f) is not a valid longitude and latitude", name, loc[0], loc[1])
+	}
+	return nil
+}
+
+// ValidateBBox checks an (optional) [minLng, minLat, maxLng, maxLat] bounding box
+func ValidateBBox(bbox BoundingBox) error {
+	if bbox == nil {
+		return nil
+	}
+	if len(bbox) != 4 {
+		return fmt.Errorf("Bounding box must contain 4 values (received %d)", len(bbox))
+	}
+	if err := ValidateLocation("Bounding box minimum", []float64{bbox[0], bbox[1]}); err != nil {
+		return err
+	}
+	if err := ValidateLocation("Bounding box maximum", []float64{bbox[2], bbox[3]}); err != nil {
+		return err
+	}
+	if bbox[0] > bbox[2] || bbox[1] > bbox[3] {
+		return fmt.Errorf("Bounding box minimum must not exceed maximum")
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+/**
+ * go-mapbox Elevation Module
+ * Annotates directions and map matching results with elevation data sampled from Terrain-RGB tiles
+ * See https://www.mapbox.com/help/access-elevation-data/ for terrain information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package elevation
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/directions"
+	"github.com/tumasgiu/go-mapbox/lib/map_matching"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+const (
+	// DefaultSpacing is the default distance between elevation samples in metres
+	DefaultSpacing = 30.0
+	// DefaultLevel is the default terrain tile zoom level
+	DefaultLevel uint64 = 14
+)
+
+// Elevation samples terrain elevation along route geometries
+// Tiles are fetched through the provided Maps instance, so binding a cache with Maps.SetCache
+// avoids refetching tiles for repeated routes
+type Elevation struct {
+	maps *maps.Maps
+}
+
+// NewElevation creates a new elevation sampler using the provided maps instance
+func NewElevation(m *maps.Maps) *Elevation {
+	return &Elevation{m}
+}
+
+// Sample builds an elevation profile along the provided line
+func (e *Elevation) Sample(line []base.Location, opts *Opts) (*Profile, error) {
+	o := withDefaults(opts)
+	s := e.newSampler(o)
+
+	samples, err := s.sampleLine(line, o.Spacing)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newProfile(samples, o.Spacing)
+	return &p, nil
+}
+
+// AnnotateRoute builds an elevation profile for a directions route, its legs and (if available) its steps
+func (e *Elevation) AnnotateRoute(route *directions.Route, opts *Opts) (*RouteProfile, error) {
+	o := withDefaults(opts)
+	s := e.newSampler(o)
+
+	line, err := base.DecodePolyline(route.Geometry, o.Precision)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fall back to step geometries where no overview geometry was requested
+	if len(line) == 0 {
+		for _, l := range route.Legs {
+			for _, step := range l.Steps {
+				stepLine, err := base.DecodePolyline(step.Geometry, o.Precision)
+				if err != nil {
+					return nil, err
+				}
+				line = append(line, stepLine...)
+			}
+		}
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("Route contains no geometry (request overview or steps)")
+	}
+
+	samples, err := s.sampleLine(line, o.Spacing)
+	if err != nil {
+		return nil, err
+	}
+
+	legDistances := make([]float64, len(route.Legs))
+	for i, l := range route.Legs {
+		legDistances[i] = l.Distance
+	}
+
+	rp := RouteProfile{Profile: newProfile(samples, o.Spacing)}
+
+	for i, legSamples := range splitByDistance(samples, legDistances) {
+		leg := LegProfile{Profile: newProfile(legSamples, o.Spacing)}
+
+		steps := route.Legs[i].Steps
+		stepDistances := make([]float64, len(steps))
+		for j, step := range steps {
+			stepDistances[j] = step.Distance
+		}
+		stepSplits := splitByDistance(legSamples, stepDistances)
+
+		for j, step := range steps {
+			stepLine, err := base.DecodePolyline(step.Geometry, o.Precision)
+			if err != nil {
+				return nil, err
+			}
+
+			// Prefer step geometries, these are not affected by overview simplification
+			stepSamples := stepSplits[j]
+			if len(stepLine) > 0 {
+				stepSamples, err = s.sampleLine(stepLine, o.Spacing)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			leg.Steps = append(leg.Steps, newProfile(stepSamples, o.Spacing))
+		}
+
+		rp.Legs = append(rp.Legs, leg)
+	}
+
+	return &rp, nil
+}
+
+// AnnotateMatching builds an elevation profile for a map matching result and its legs
+func (e *Elevation) AnnotateMatching(matching *mapmatching.Matchings, opts *Opts) (*RouteProfile, error) {
+	o := withDefaults(opts)
+	s := e.newSampler(o)
+
+	line, err := matching.GetLocations(o.Precision)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("Matching contains no geometry (request overview)")
+	}
+
+	samples, err := s.sampleLine(line, o.Spacing)
+	if err != nil {
+		return nil, err
+	}
+
+	legDistances := make([]float64, len(matching.Legs))
+	for i, l := range matching.Legs {
+		legDistances[i] = l.Distance
+	}
+
+	rp := RouteProfile{Profile: newProfile(samples, o.Spacing)}
+	for _, legSamples := range splitByDistance(samples, legDistances) {
+		rp.Legs = append(rp.Legs, LegProfile{Profile: newProfile(legSamples, o.Spacing)})
+	}
+
+	return &rp, nil
+}
+
+func withDefaults(opts *Opts) Opts {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Spacing <= 0 {
+		o.Spacing = DefaultSpacing
+	}
+	if o.Level == 0 {
+		o.Level = DefaultLevel
+	}
+	if o.Precision == 0 {
+		o.Precision = base.PolylinePrecision
+	}
+	return o
+}
+
+type tileID struct {
+	x, y uint64
+}
+
+// sampler looks up elevations, holding fetched tiles for the duration of a single annotation
+type sampler struct {
+	maps    *maps.Maps
+	level   uint64
+	size    uint64
+	highDPI bool
+	tiles   map[tileID]*maps.Tile
+}
+
+func (e *Elevation) newSampler(o Opts) *sampler {
+	size := maps.SizeStandard
+	if o.HighDPI {
+		size = maps.SizeHighDPI
+	}
+	return &sampler{
+		maps:    e.maps,
+		level:   o.Level,
+		size:    size,
+		highDPI: o.HighDPI,
+		tiles:   make(map[tileID]*maps.Tile),
+	}
+}
+
+// sampleLine resamples a line at a fixed spacing and looks up the elevation of each sample
+func (s *sampler) sampleLine(line []base.Location, spacing float64) ([]Sample, error) {
+	if len(line) == 0 {
+		return nil, nil
+	}
+
+	samples := []Sample{{Location: line[0]}}
+
+	total, next := 0.0, spacing
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		length := base.Distance(a, b)
+
+		for next <= total+length {
+			f := (next - total) / length
+			samples = append(samples, Sample{Location: base.Interpolate(a, b, f), Distance: next})
+			next += spacing
+		}
+
+		total += length
+	}
+
+	// Always finish at the end of the line
+	if last := samples[len(samples)-1]; total-last.Distance > 1e-6 {
+		samples = append(samples, Sample{Location: line[len(line)-1], Distance: total})
+	}
+
+	for i := range samples {
+		alt, err := s.elevation(samples[i].Location)
+		if err != nil {
+			return nil, err
+		}
+		samples[i].Elevation = alt
+	}
+
+	return samples, nil
+}
+
+// elevation bilinearly interpolates the elevation at a location
+func (s *sampler) elevation(loc base.Location) (float64, error) {
+	x, y := maps.MercatorLocationToPixel(loc.Latitude, loc.Longitude, s.level, s.size)
+
+	// Pixel values apply to pixel centres
+	x, y = x-0.5, y-0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+
+	var h [4]float64
+	for i, p := range [4][2]float64{{x0, y0}, {x0 + 1, y0}, {x0, y0 + 1}, {x0 + 1, y0 + 1}} {
+		v, err := s.height(int64(p[0]), int64(p[1]))
+		if err != nil {
+			return 0, err
+		}
+		h[i] = v
+	}
+
+	top := h[0]*(1-fx) + h[1]*fx
+	bottom := h[2]*(1-fx) + h[3]*fx
+
+	return top*(1-fy) + bottom*fy, nil
+}
+
+// height fetches the height of a global pixel, wrapping in x and clamping in y
+func (s *sampler) height(x, y int64) (float64, error) {
+	worldSize := int64(s.size) << s.level
+
+	x = ((x % worldSize) + worldSize) % worldSize
+	if y < 0 {
+		y = 0
+	} else if y >= worldSize {
+		y = worldSize - 1
+	}
+
+	id := tileID{uint64(x) / s.size, uint64(y) / s.size}
+
+	tile, ok := s.tiles[id]
+	if !ok {
+		var err error
+		tile, err = s.maps.GetTile(maps.MapIDTerrainRGB, id.x, id.y, s.level, maps.MapFormatPngRaw, s.highDPI)
+		if err != nil {
+			return 0, err
+		}
+		s.tiles[id] = tile
+	}
+
+	// Scale in case the returned tile does not match the requested tile size
+	b := tile.Bounds()
+	px := b.Min.X + int((uint64(x)-id.x*s.size)*uint64(b.Dx())/s.size)
+	py := b.Min.Y + int((uint64(y)-id.y*s.size)*uint64(b.Dy())/s.size)
+
+	c := color.NRGBAModel.Convert(tile.At(px, py)).(color.NRGBA)
+
+	return maps.PixelToHeight(c.R, c.G, c.B), nil
+}
+
+// newProfile computes summary statistics for a set of samples
+// Grades are only evaluated over sample pairs at least half the spacing apart to limit noise
+func newProfile(samples []Sample, spacing float64) Profile {
+	p := Profile{Samples: samples}
+	if len(samples) == 0 {
+		return p
+	}
+
+	p.Distance = samples[len(samples)-1].Distance - samples[0].Distance
+	p.MinElevation, p.MaxElevation = samples[0].Elevation, samples[0].Elevation
+
+	for i := 1; i < len(samples); i++ {
+		a, b := samples[i-1], samples[i]
+
+		dh := b.Elevation - a.Elevation
+		if dh > 0 {
+			p.Ascent += dh
+		} else {
+			p.Descent -= dh
+		}
+
+		p.MinElevation = math.Min(p.MinElevation, b.Elevation)
+		p.MaxElevation = math.Max(p.MaxElevation, b.Elevation)
+
+		if dd := b.Distance - a.Distance; dd >= spacing/2 {
+			grade := dh / dd * 100
+			p.MaxGrade = math.Max(p.MaxGrade, grade)
+			p.MinGrade = math.Min(p.MinGrade, grade)
+		}
+	}
+
+	return p
+}
+
+// splitByDistance splits samples into sections of the provided (API reported) lengths
+// Lengths are scaled to the sampled geometry length and sample distances are rebased to each section
+func splitByDistance(samples []Sample, lengths []float64) [][]Sample {
+	sections := make([][]Sample, len(lengths))
+	if len(samples) == 0 || len(lengths) == 0 {
+		return sections
+	}
+
+	total := 0.0
+	for _, l := range lengths {
+		total += l
+	}
+
+	start := samples[0].Distance
+	scale := 1.0
+	if total > 0 {
+		scale = (samples[len(samples)-1].Distance - start) / total
+	}
+
+	d0 := start
+	for i, l := range lengths {
+		d1 := d0 + l*scale
+		if i == len(lengths)-1 {
+			d1 = samples[len(samples)-1].Distance
+		}
+		sections[i] = sliceSamples(samples, d0, d1)
+		d0 = d1
+	}
+
+	return sections
+}
+
+// sliceSamples returns the samples between d0 and d1, interpolating samples at the boundaries
+func sliceSamples(samples []Sample, d0, d1 float64) []Sample {
+	section := make([]Sample, 0)
+
+	for i, s := range samples {
+		if s.Distance < d0 {
+			continue
+		}
+		if len(section) == 0 && s.Distance > d0 && i > 0 {
+			section = append(section, interpolateSample(samples[i-1], s, d0))
+		}
+		if s.Distance > d1 {
+			if i > 0 {
+				section = append(section, interpolateSample(samples[i-1], s, d1))
+			}
+			break
+		}
+		section = append(section, s)
+	}
+
+	for i := range section {
+		section[i].Distance -= d0
+	}
+
+	return section
+}
+
+func interpolateSample(a, b Sample, d float64) Sample {
+	f := 0.0
+	if b.Distance > a.Distance {
+		f = (d - a.Distance) / (b.Distance - a.Distance)
+	}
+	return Sample{
+		Location:  base.Interpolate(a.Location, b.Location, f),
+		Distance:  d,
+		Elevation: a.Elevation + (b.Elevation-a.Elevation)*f,
+	}
+}
@@ -0,0 +1,137 @@
+/**
+ * go-mapbox Elevation Module Tests
+ * Uses synthetic terrain tiles served from a memory cache
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package elevation
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/directions"
+	"github.com/tumasgiu/go-mapbox/lib/map_matching"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+const level = 12
+
+// terrainHeight is a synthetic terrain rising 0.1m per global pixel towards the east
+func terrainHeight(x, y uint64) float64 {
+	return 100 + float64(x)*0.1
+}
+
+func newTerrainMaps(t *testing.T, a, b base.Location) *maps.Maps {
+	bs, err := base.NewBase("synthetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := maps.NewMemoryCache()
+	xStart, yStart, xEnd, yEnd := maps.GetEnclosingTileIDs(a, b, level)
+	for ty := yStart - 1; ty <= yEnd+1; ty++ {
+		for tx := xStart - 1; tx <= xEnd+1; tx++ {
+			img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+			for y := 0; y < 256; y++ {
+				for x := 0; x < 256; x++ {
+					r, g, b := maps.HeightToPixel(terrainHeight(tx*256+uint64(x), ty*256+uint64(y)))
+					img.Set(x, y, color.NRGBA{R: r, G: g, B: b, A: 255})
+				}
+			}
+			cache.Save(maps.MapIDTerrainRGB, tx, ty, level, maps.MapFormatPngRaw, false, img)
+		}
+	}
+
+	m := maps.NewMaps(bs)
+	m.SetCache(cache)
+	return m
+}
+
+func TestElevation(t *testing.T) {
+	a := base.Location{Latitude: -41.30, Longitude: 174.70}
+	mid := base.Location{Latitude: -41.30, Longitude: 174.75}
+	b := base.Location{Latitude: -41.30, Longitude: 174.80}
+
+	e := NewElevation(newTerrainMaps(t, a, b))
+	opts := Opts{Spacing: 20, Level: level}
+
+	xa, _ := maps.MercatorLocationToPixel(a.Latitude, a.Longitude, level, 256)
+	xb, _ := maps.MercatorLocationToPixel(b.Latitude, b.Longitude, level, 256)
+	climb := (xb - xa) * 0.1
+
+	t.Run("Samples lines at the requested spacing", func(t *testing.T) {
+		p, err := e.Sample([]base.Location{a, b}, &opts)
+		assert.Nil(t, err)
+
+		assert.InDelta(t, base.Distance(a, b), p.Distance, 1e-6)
+		assert.InDelta(t, climb, p.Ascent, 0.5)
+		assert.InDelta(t, 0, p.Descent, 0.5)
+		assert.True(t, p.MaxGrade > 0)
+
+		for i := 1; i < len(p.Samples)-1; i++ {
+			assert.InDelta(t, opts.Spacing, p.Samples[i].Distance-p.Samples[i-1].Distance, 1e-6)
+		}
+	})
+
+	t.Run("Annotates directions routes by leg and step", func(t *testing.T) {
+		d1, d2 := base.Distance(a, mid), base.Distance(mid, b)
+		route := directions.Route{
+			Geometry: base.EncodePolyline([]base.Location{a, mid, b}, base.PolylinePrecision),
+			Legs: []directions.RouteLeg{{
+				Distance: d1,
+				Steps: []directions.RouteStep{{
+					Distance: d1,
+					Geometry: base.EncodePolyline([]base.Location{a, mid}, base.PolylinePrecision),
+				}},
+			}, {
+				Distance: d2,
+			}},
+		}
+
+		rp, err := e.AnnotateRoute(&route, &opts)
+		assert.Nil(t, err)
+
+		assert.InDelta(t, climb, rp.Ascent, 0.5)
+		assert.Len(t, rp.Legs, 2)
+		assert.InDelta(t, rp.Ascent, rp.Legs[0].Ascent+rp.Legs[1].Ascent, 0.5)
+		assert.InDelta(t, d1, rp.Legs[0].Distance, 1)
+
+		assert.Len(t, rp.Legs[0].Steps, 1)
+		assert.InDelta(t, rp.Legs[0].Ascent, rp.Legs[0].Steps[0].Ascent, 0.5)
+		assert.Len(t, rp.Legs[1].Steps, 0)
+
+		// Descending the same route reverses the profile
+		reverse := directions.Route{Geometry: base.EncodePolyline([]base.Location{b, a}, base.PolylinePrecision)}
+		rp, err = e.AnnotateRoute(&reverse, &opts)
+		assert.Nil(t, err)
+		assert.InDelta(t, climb, rp.Descent, 0.5)
+		assert.True(t, rp.MinGrade < 0)
+	})
+
+	t.Run("Annotates map matching results", func(t *testing.T) {
+		matching := mapmatching.Matchings{
+			Geometry: map[string]interface{}{
+				"type": "LineString",
+				"coordinates": []interface{}{
+					[]interface{}{a.Longitude, a.Latitude},
+					[]interface{}{b.Longitude, b.Latitude},
+				},
+			},
+			Legs: []mapmatching.MatchingLeg{{Distance: 1}, {Distance: 1}},
+		}
+
+		rp, err := e.AnnotateMatching(&matching, &opts)
+		assert.Nil(t, err)
+
+		assert.InDelta(t, climb, rp.Ascent, 0.5)
+		assert.Len(t, rp.Legs, 2)
+		assert.InDelta(t, rp.Legs[0].Distance, rp.Legs[1].Distance, 1e-6)
+	})
+}
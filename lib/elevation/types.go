@@ -0,0 +1,57 @@
+/**
+ * go-mapbox Elevation Module Types
+ * Elevation profiles for directions and map matching results
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package elevation
+
+import (
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// Opts configures elevation sampling
+type Opts struct {
+	// Spacing between elevation samples in metres (defaults to DefaultSpacing)
+	Spacing float64
+	// Level is the terrain tile zoom level to sample (defaults to DefaultLevel)
+	Level uint64
+	// HighDPI fetches 512px terrain tiles rather than 256px tiles
+	HighDPI bool
+	// Precision of encoded polyline geometries (defaults to base.PolylinePrecision, use base.Polyline6Precision for polyline6)
+	Precision uint
+}
+
+// Sample is a single elevation sample along a geometry
+type Sample struct {
+	Location  base.Location
+	Distance  float64 // Distance along the geometry in metres
+	Elevation float64 // Elevation in metres
+}
+
+// Profile is an elevation profile with summary statistics
+type Profile struct {
+	Distance     float64 // Sampled distance in metres
+	Ascent       float64 // Total climb in metres
+	Descent      float64 // Total descent in metres
+	MaxGrade     float64 // Steepest climb in percent
+	MinGrade     float64 // Steepest descent in percent (negative)
+	MinElevation float64
+	MaxElevation float64
+	Samples      []Sample
+}
+
+// LegProfile is the elevation profile of a route leg and its steps
+// Steps are only populated when the route was requested with steps
+type LegProfile struct {
+	Profile
+	Steps []Profile
+}
+
+// RouteProfile is the elevation profile of a route or matching and its legs
+type RouteProfile struct {
+	Profile
+	Legs []LegProfile
+}
@@ -0,0 +1,226 @@
+/**
+ * go-mapbox Export Module
+ * Exports stitched tiles and elevation grids with georeferencing for GIS tools
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package export
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+	"github.com/tumasgiu/go-mapbox/lib/terrain"
+)
+
+// DefaultNoData is the no data value used for raster cells without a value
+const DefaultNoData = -9999.0
+
+// Raster is a single band georeferenced raster
+type Raster struct {
+	Width, Height int
+	Values        []float64 // Row major values
+	Transform     maps.GeoTransform
+	NoData        float64
+}
+
+// NewRaster creates a raster from a terrain grid (such as a DEM) in EPSG:3857
+func NewRaster(g *terrain.Grid) *Raster {
+	values := make([]float64, len(g.Values))
+	copy(values, g.Values)
+
+	return &Raster{
+		Width:     g.Width,
+		Height:    g.Height,
+		Values:    values,
+		Transform: g.GeoTransform(),
+		NoData:    DefaultNoData,
+	}
+}
+
+// At fetches the value at a pixel, returning NoData outside the raster
+func (r *Raster) At(x, y int) float64 {
+	if x < 0 || y < 0 || x >= r.Width || y >= r.Height {
+		return r.NoData
+	}
+	return r.Values[y*r.Width+x]
+}
+
+// sampleBilinear samples the raster at a fractional pixel location, values apply to pixel centres
+func (r *Raster) sampleBilinear(x, y float64) float64 {
+	x, y = x-0.5, y-0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	xi, yi := int(x0), int(y0)
+
+	// Clamp to the edge pixels inside the raster extent
+	clampX := func(v int) int { return int(math.Max(0, math.Min(float64(r.Width-1), float64(v)))) }
+	clampY := func(v int) int { return int(math.Max(0, math.Min(float64(r.Height-1), float64(v)))) }
+
+	v00, v10 := r.At(clampX(xi), clampY(yi)), r.At(clampX(xi+1), clampY(yi))
+	v01, v11 := r.At(clampX(xi), clampY(yi+1)), r.At(clampX(xi+1), clampY(yi+1))
+	for _, v := range []float64{v00, v10, v01, v11} {
+		if v == r.NoData {
+			return r.NoData
+		}
+	}
+
+	return (v00*(1-fx)+v10*fx)*(1-fy) + (v01*(1-fx)+v11*fx)*fy
+}
+
+// wgs84Extent computes the EPSG:4326 transform and size covering an EPSG:3857 image with square degree pixels
+func wgs84Extent(width, height int, gt maps.GeoTransform) (int, int, maps.GeoTransform, error) {
+	if gt.EPSG != maps.EPSGWebMercator {
+		return 0, 0, maps.GeoTransform{}, fmt.Errorf("Reprojection requires an EPSG:%d source (received EPSG:%d)", maps.EPSGWebMercator, gt.EPSG)
+	}
+
+	minX, maxY := gt.PixelToCoordinate(0, 0)
+	maxX, minY := gt.PixelToCoordinate(float64(width), float64(height))
+
+	north, west := maps.MercatorMetersToLocation(minX, maxY)
+	south, east := maps.MercatorMetersToLocation(maxX, minY)
+
+	cell := (east - west) / float64(width)
+	outHeight := int(math.Ceil((north - south) / cell))
+
+	return width, outHeight, maps.GeoTransform{
+		EPSG:        maps.EPSGWGS84,
+		OriginX:     west,
+		OriginY:     north,
+		PixelWidth:  cell,
+		PixelHeight: -cell,
+	}, nil
+}
+
+// sourcePixel maps the centre of a EPSG:4326 output pixel back to the EPSG:3857 source image
+func sourcePixel(x, y int, src, dst maps.GeoTransform) (float64, float64) {
+	lng, lat := dst.PixelToCoordinate(float64(x)+0.5, float64(y)+0.5)
+	mx, my := maps.MercatorLocationToMeters(lat, lng)
+	return src.CoordinateToPixel(mx, my)
+}
+
+// ReprojectImage resamples an EPSG:3857 image to EPSG:4326 with square degree pixels
+func ReprojectImage(img image.Image, gt maps.GeoTransform) (*image.NRGBA, maps.GeoTransform, error) {
+	b := img.Bounds()
+
+	width, height, out, err := wgs84Extent(b.Dx(), b.Dy(), gt)
+	if err != nil {
+		return nil, out, err
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := sourcePixel(x, y, gt, out)
+			dst.SetNRGBA(x, y, maps.SampleBilinear(img, sx, sy))
+		}
+	}
+
+	return dst, out, nil
+}
+
+// Reproject resamples an EPSG:3857 raster to EPSG:4326 with square degree pixels
+func (r *Raster) Reproject() (*Raster, error) {
+	width, height, out, err := wgs84Extent(r.Width, r.Height, r.Transform)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := &Raster{
+		Width:     width,
+		Height:    height,
+		Values:    make([]float64, width*height),
+		Transform: out,
+		NoData:    r.NoData,
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx, sy := sourcePixel(x, y, r.Transform, out)
+			dst.Values[y*width+x] = r.sampleBilinear(sx, sy)
+		}
+	}
+
+	return dst, nil
+}
+
+// WorldFileExtension returns the world file extension for an image file extension (eg. png -> pgw)
+func WorldFileExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	switch ext {
+	case "jpeg":
+		ext = "jpg"
+	case "tiff":
+		ext = "tif"
+	}
+	if len(ext) < 2 {
+		return ext + "w"
+	}
+	return ext[:1] + ext[len(ext)-1:] + "w"
+}
+
+// WriteWorldFile writes an ESRI world file (.pgw, .jgw etc.) for an image with the provided transform
+// World files reference the centre of the top left pixel
+func WriteWorldFile(w io.Writer, gt maps.GeoTransform) error {
+	cx, cy := gt.PixelToCoordinate(0.5, 0.5)
+	_, err := fmt.Fprintf(w, "%.10f\n0.0\n0.0\n%.10f\n%.10f\n%.10f\n", gt.PixelWidth, gt.PixelHeight, cx, cy)
+	return err
+}
+
+// Well known text definitions for .prj sidecar files
+const (
+	wktWebMercator = `PROJCS["WGS 84 / Pseudo-Mercator",GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563]],PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433]],PROJECTION["Mercator_1SP"],PARAMETER["central_meridian",0],PARAMETER["scale_factor",1],PARAMETER["false_easting",0],PARAMETER["false_northing",0],UNIT["metre",1],AUTHORITY["EPSG","3857"]]`
+	wktWGS84       = `GEOGCS["WGS 84",DATUM["WGS_1984",SPHEROID["WGS 84",6378137,298.257223563]],PRIMEM["Greenwich",0],UNIT["degree",0.0174532925199433],AUTHORITY["EPSG","4326"]]`
+)
+
+// WriteProjection writes a .prj sidecar file describing the transform coordinate reference system
+func WriteProjection(w io.Writer, gt maps.GeoTransform) error {
+	var wkt string
+	switch gt.EPSG {
+	case maps.EPSGWebMercator:
+		wkt = wktWebMercator
+	case maps.EPSGWGS84:
+		wkt = wktWGS84
+	default:
+		return fmt.Errorf("Unsupported EPSG code (%d)", gt.EPSG)
+	}
+	_, err := io.WriteString(w, wkt)
+	return err
+}
+
+// WriteASCIIGrid writes a raster as an ESRI ASCII grid (.asc)
+// Non square cells are written using the dx/dy extension supported by GDAL
+func WriteASCIIGrid(w io.Writer, r *Raster) error {
+	gt := r.Transform
+	_, yll := gt.PixelToCoordinate(0, float64(r.Height))
+
+	header := fmt.Sprintf("ncols %d\nnrows %d\nxllcorner %.10f\nyllcorner %.10f\n", r.Width, r.Height, gt.OriginX, yll)
+	if math.Abs(gt.PixelWidth) == math.Abs(gt.PixelHeight) {
+		header += fmt.Sprintf("cellsize %.10f\n", gt.PixelWidth)
+	} else {
+		header += fmt.Sprintf("dx %.10f\ndy %.10f\n", gt.PixelWidth, -gt.PixelHeight)
+	}
+	header += fmt.Sprintf("NODATA_value %g\n", r.NoData)
+
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	line := make([]string, r.Width)
+	for y := 0; y < r.Height; y++ {
+		for x := 0; x < r.Width; x++ {
+			line[x] = fmt.Sprintf("%.2f", r.Values[y*r.Width+x])
+		}
+		if _, err := io.WriteString(w, strings.Join(line, " ")+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+/**
+ * go-mapbox Export Module Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/tiff"
+
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+	"github.com/tumasgiu/go-mapbox/lib/terrain"
+)
+
+// readTags parses the first IFD of a little endian TIFF into raw tag values
+func readTags(t *testing.T, data []byte) map[uint16][]byte {
+	sizes := map[uint16]uint32{tiffASCII: 1, tiffShort: 2, tiffLong: 4, tiffDouble: 8}
+
+	tags := make(map[uint16][]byte)
+	offset := binary.LittleEndian.Uint32(data[4:])
+	count := binary.LittleEndian.Uint16(data[offset:])
+	for i := uint32(0); i < uint32(count); i++ {
+		e := data[offset+2+i*12:]
+		tag, typ, n := binary.LittleEndian.Uint16(e), binary.LittleEndian.Uint16(e[2:]), binary.LittleEndian.Uint32(e[4:])
+		size := sizes[typ] * n
+		if size <= 4 {
+			tags[tag] = e[8 : 8+size]
+		} else {
+			at := binary.LittleEndian.Uint32(e[8:])
+			tags[tag] = data[at : at+size]
+		}
+	}
+	return tags
+}
+
+func doubles(b []byte) []float64 {
+	values := make([]float64, len(b)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*8:]))
+	}
+	return values
+}
+
+func TestExport(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+	for y := 0; y < 512; y++ {
+		for x := 0; x < 512; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	tile := maps.NewTile(1, 1, 2, 256, img)
+
+	t.Run("Computes tile transforms", func(t *testing.T) {
+		gt := tile.GeoTransform()
+		assert.EqualValues(t, maps.EPSGWebMercator, gt.EPSG)
+
+		// Tile (1, 1) at level 2 starts half way to the west and north edges
+		assert.InDelta(t, -math.Pi*maps.MercatorRadius/2, gt.OriginX, 1e-6)
+		assert.InDelta(t, math.Pi*maps.MercatorRadius/2, gt.OriginY, 1e-6)
+
+		x, y := gt.PixelToCoordinate(512, 512)
+		assert.InDelta(t, math.Pi*maps.MercatorRadius/2, x, 1e-6)
+		assert.InDelta(t, -math.Pi*maps.MercatorRadius/2, y, 1e-6)
+	})
+
+	t.Run("Writes RGBA GeoTIFFs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := WriteGeoTIFF(buf, tile, tile.GeoTransform())
+		assert.Nil(t, err)
+
+		decoded, err := tiff.Decode(bytes.NewReader(buf.Bytes()))
+		assert.Nil(t, err)
+		assert.EqualValues(t, img.Bounds(), decoded.Bounds())
+		r, g, _, _ := decoded.At(10, 20).RGBA()
+		assert.EqualValues(t, 10, r>>8)
+		assert.EqualValues(t, 20, g>>8)
+
+		tags := readTags(t, buf.Bytes())
+		tiepoint := doubles(tags[tagModelTiepoint])
+		assert.InDelta(t, tile.GeoTransform().OriginX, tiepoint[3], 1e-6)
+		scale := doubles(tags[tagModelPixelScale])
+		assert.InDelta(t, tile.GeoTransform().PixelWidth, scale[0], 1e-9)
+		assert.InDelta(t, tile.GeoTransform().PixelWidth, scale[1], 1e-9)
+	})
+
+	dem := &terrain.Grid{Width: 4, Height: 3, Values: make([]float64, 12), Level: 10, Size: 256, X: 1000, Y: 600}
+	for i := range dem.Values {
+		dem.Values[i] = float64(i) * 10
+	}
+	raster := NewRaster(dem)
+
+	t.Run("Writes float GeoTIFFs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := WriteRasterGeoTIFF(buf, raster)
+		assert.Nil(t, err)
+
+		tags := readTags(t, buf.Bytes())
+		assert.EqualValues(t, "-9999\x00", string(tags[tagGDALNoData]))
+
+		offset := binary.LittleEndian.Uint32(tags[tagStripOffsets])
+		v := math.Float32frombits(binary.LittleEndian.Uint32(buf.Bytes()[offset+4*5:]))
+		assert.EqualValues(t, 50, v)
+	})
+
+	t.Run("Reprojects to EPSG:4326", func(t *testing.T) {
+		out, gt, err := ReprojectImage(tile, tile.GeoTransform())
+		assert.Nil(t, err)
+		assert.EqualValues(t, maps.EPSGWGS84, gt.EPSG)
+		assert.InDelta(t, -90, gt.OriginX, 1e-9)
+		assert.InDelta(t, 66.5133, gt.OriginY, 1e-4)
+		assert.InDelta(t, gt.PixelWidth, -gt.PixelHeight, 1e-12)
+		assert.EqualValues(t, 512, out.Bounds().Dx())
+
+		reprojected, err := raster.Reproject()
+		assert.Nil(t, err)
+		assert.EqualValues(t, maps.EPSGWGS84, reprojected.Transform.EPSG)
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, v := range reprojected.Values {
+			if v != reprojected.NoData {
+				min, max = math.Min(min, v), math.Max(max, v)
+			}
+		}
+		assert.True(t, min >= 0 && max <= 110)
+
+		_, err = reprojected.Reproject()
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Writes world files", func(t *testing.T) {
+		assert.EqualValues(t, "pgw", WorldFileExtension(".png"))
+		assert.EqualValues(t, "jgw", WorldFileExtension("jpeg"))
+		assert.EqualValues(t, "tfw", WorldFileExtension("tiff"))
+
+		buf := &bytes.Buffer{}
+		err := WriteWorldFile(buf, tile.GeoTransform())
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 6)
+	})
+
+	t.Run("Writes ASCII grids", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := WriteASCIIGrid(buf, raster)
+		assert.Nil(t, err)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 6+3)
+		assert.EqualValues(t, "ncols 4", lines[0])
+		assert.True(t, strings.HasPrefix(lines[4], "cellsize"))
+		assert.EqualValues(t, "0.00 10.00 20.00 30.00", lines[6])
+	})
+}
@@ -0,0 +1,238 @@
+/**
+ * go-mapbox Export Module GeoTIFF
+ * Minimal uncompressed GeoTIFF encoder for RGBA images and float32 rasters
+ * See http://docs.opengeospatial.org/is/19-008r4/19-008r4.html for format information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// TIFF field types
+const (
+	tiffASCII  = 2
+	tiffShort  = 3
+	tiffLong   = 4
+	tiffDouble = 12
+)
+
+// TIFF and GeoTIFF tags
+const (
+	tagImageWidth                = 256
+	tagImageLength               = 257
+	tagBitsPerSample             = 258
+	tagCompression               = 259
+	tagPhotometricInterpretation = 262
+	tagStripOffsets              = 273
+	tagSamplesPerPixel           = 277
+	tagRowsPerStrip              = 278
+	tagStripByteCounts           = 279
+	tagPlanarConfiguration       = 284
+	tagExtraSamples              = 338
+	tagSampleFormat              = 339
+	tagModelPixelScale           = 33550
+	tagModelTiepoint             = 33922
+	tagGeoKeyDirectory           = 34735
+	tagGDALNoData                = 42113
+)
+
+// GeoTIFF keys
+const (
+	keyGTModelType        = 1024
+	keyGTRasterType       = 1025
+	keyGeographicType     = 2048
+	keyProjectedCSType    = 3072
+	modelTypeProjected    = 1
+	modelTypeGeographic   = 2
+	rasterPixelIsArea     = 1
+	sampleFormatUint      = 1
+	sampleFormatFloat     = 3
+	extraSampleUnassocAlp = 2
+)
+
+type tiffEntry struct {
+	tag   uint16
+	typ   uint16
+	count uint32
+	data  []byte
+}
+
+func shortEntry(tag uint16, values ...uint16) tiffEntry {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, values)
+	return tiffEntry{tag, tiffShort, uint32(len(values)), buf.Bytes()}
+}
+
+func longEntry(tag uint16, values ...uint32) tiffEntry {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, values)
+	return tiffEntry{tag, tiffLong, uint32(len(values)), buf.Bytes()}
+}
+
+func doubleEntry(tag uint16, values ...float64) tiffEntry {
+	buf := &bytes.Buffer{}
+	binary.Write(buf, binary.LittleEndian, values)
+	return tiffEntry{tag, tiffDouble, uint32(len(values)), buf.Bytes()}
+}
+
+func asciiEntry(tag uint16, value string) tiffEntry {
+	data := append([]byte(value), 0)
+	return tiffEntry{tag, tiffASCII, uint32(len(data)), data}
+}
+
+// geoEntries builds the georeferencing tags for a transform
+func geoEntries(gt maps.GeoTransform) ([]tiffEntry, error) {
+	var keys []uint16
+	switch gt.EPSG {
+	case maps.EPSGWebMercator:
+		keys = []uint16{
+			1, 1, 0, 3,
+			keyGTModelType, 0, 1, modelTypeProjected,
+			keyGTRasterType, 0, 1, rasterPixelIsArea,
+			keyProjectedCSType, 0, 1, maps.EPSGWebMercator,
+		}
+	case maps.EPSGWGS84:
+		keys = []uint16{
+			1, 1, 0, 3,
+			keyGTModelType, 0, 1, modelTypeGeographic,
+			keyGTRasterType, 0, 1, rasterPixelIsArea,
+			keyGeographicType, 0, 1, maps.EPSGWGS84,
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported EPSG code (%d)", gt.EPSG)
+	}
+
+	return []tiffEntry{
+		doubleEntry(tagModelPixelScale, gt.PixelWidth, -gt.PixelHeight, 0),
+		doubleEntry(tagModelTiepoint, 0, 0, 0, gt.OriginX, gt.OriginY, 0),
+		shortEntry(tagGeoKeyDirectory, keys...),
+	}, nil
+}
+
+// writeTIFF writes a single strip little endian TIFF with the provided pixel data and entries
+func writeTIFF(w io.Writer, width, height int, pixels []byte, entries []tiffEntry) error {
+	const headerSize = 8
+
+	// Pixel data follows the header, the IFD follows the (word aligned) pixel data
+	ifdOffset := uint32(headerSize + len(pixels))
+	if ifdOffset%2 != 0 {
+		ifdOffset++
+	}
+
+	entries = append(entries,
+		longEntry(tagImageWidth, uint32(width)),
+		longEntry(tagImageLength, uint32(height)),
+		shortEntry(tagCompression, 1),
+		longEntry(tagStripOffsets, headerSize),
+		longEntry(tagRowsPerStrip, uint32(height)),
+		longEntry(tagStripByteCounts, uint32(len(pixels))),
+		shortEntry(tagPlanarConfiguration, 1),
+	)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].tag < entries[j].tag })
+
+	ifdSize := uint32(2 + 12*len(entries) + 4)
+	extOffset := ifdOffset + ifdSize
+
+	ifd := &bytes.Buffer{}
+	ext := &bytes.Buffer{}
+
+	binary.Write(ifd, binary.LittleEndian, uint16(len(entries)))
+	for _, e := range entries {
+		binary.Write(ifd, binary.LittleEndian, e.tag)
+		binary.Write(ifd, binary.LittleEndian, e.typ)
+		binary.Write(ifd, binary.LittleEndian, e.count)
+
+		// Values of up to four bytes are stored inline, larger values are offset into the trailing data
+		if len(e.data) <= 4 {
+			value := make([]byte, 4)
+			copy(value, e.data)
+			ifd.Write(value)
+		} else {
+			binary.Write(ifd, binary.LittleEndian, extOffset+uint32(ext.Len()))
+			ext.Write(e.data)
+			if ext.Len()%2 != 0 {
+				ext.WriteByte(0)
+			}
+		}
+	}
+	binary.Write(ifd, binary.LittleEndian, uint32(0))
+
+	out := &bytes.Buffer{}
+	out.Write([]byte{'I', 'I', 42, 0})
+	binary.Write(out, binary.LittleEndian, ifdOffset)
+	out.Write(pixels)
+	if len(pixels)%2 != 0 {
+		out.WriteByte(0)
+	}
+	out.Write(ifd.Bytes())
+	out.Write(ext.Bytes())
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// WriteGeoTIFF writes an image as an 8 bit RGBA GeoTIFF with the provided transform
+// Use Tile.GeoTransform for tiles and stitched tiles, or ReprojectImage for EPSG:4326 output
+func WriteGeoTIFF(w io.Writer, img image.Image, gt maps.GeoTransform) error {
+	entries, err := geoEntries(gt)
+	if err != nil {
+		return err
+	}
+
+	b := img.Bounds()
+	pixels := make([]byte, 0, b.Dx()*b.Dy()*4)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pixels = append(pixels, c.R, c.G, c.B, c.A)
+		}
+	}
+
+	entries = append(entries,
+		shortEntry(tagBitsPerSample, 8, 8, 8, 8),
+		shortEntry(tagPhotometricInterpretation, 2),
+		shortEntry(tagSamplesPerPixel, 4),
+		shortEntry(tagExtraSamples, extraSampleUnassocAlp),
+		shortEntry(tagSampleFormat, sampleFormatUint, sampleFormatUint, sampleFormatUint, sampleFormatUint),
+	)
+
+	return writeTIFF(w, b.Dx(), b.Dy(), pixels, entries)
+}
+
+// WriteRasterGeoTIFF writes a raster as a single band float32 GeoTIFF with a GDAL no data tag
+func WriteRasterGeoTIFF(w io.Writer, r *Raster) error {
+	entries, err := geoEntries(r.Transform)
+	if err != nil {
+		return err
+	}
+
+	pixels := make([]byte, len(r.Values)*4)
+	for i, v := range r.Values {
+		binary.LittleEndian.PutUint32(pixels[i*4:], math.Float32bits(float32(v)))
+	}
+
+	entries = append(entries,
+		shortEntry(tagBitsPerSample, 32),
+		shortEntry(tagPhotometricInterpretation, 1),
+		shortEntry(tagSamplesPerPixel, 1),
+		shortEntry(tagSampleFormat, sampleFormatFloat),
+		asciiEntry(tagGDALNoData, strconv.FormatFloat(r.NoData, 'g', -1, 64)),
+	)
+
+	return writeTIFF(w, r.Width, r.Height, pixels, entries)
+}
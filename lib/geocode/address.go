@@ -0,0 +1,256 @@
+/**
+ * go-mapbox Geocoding Module Address Validation
+ * Geocodes free text or structured addresses and compares the result against the input,
+ * reporting per-component matches and suggested corrections
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// AddressInput is an address to validate, either as free text or as components
+// Text is used where set, otherwise the components are joined to form the query
+type AddressInput struct {
+	Text        string
+	HouseNumber string
+	Street      string
+	Postcode    string
+	City        string
+	Region      string
+	Country     string
+}
+
+// query returns the search string for the input
+func (a *AddressInput) query() string {
+	if strings.TrimSpace(a.Text) != "" {
+		return a.Text
+	}
+	parts := []string{}
+	for _, p := range []string{
+		strings.TrimSpace(a.HouseNumber + " " + a.Street),
+		a.City,
+		strings.TrimSpace(a.Region + " " + a.Postcode),
+		a.Country,
+	} {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ComponentMatch compares an address component of the input with the geocoded result
+type ComponentMatch struct {
+	Status MatchStatus
+	Input  string
+	Result string
+}
+
+// Correction is a suggested change to an address component
+type Correction struct {
+	Component string
+	From      string
+	To        string
+}
+
+// AddressValidation is the result of validating an address
+// Free text inputs can not distinguish corrected from missing components, so both are reported as inferred
+type AddressValidation struct {
+	Address     base.Address  // Normalised address of the best match
+	Formatted   string        // Formatted address of the best match
+	Location    base.Location // Location of the best match
+	HouseNumber ComponentMatch
+	Street      ComponentMatch
+	Postcode    ComponentMatch
+	City        ComponentMatch
+	Corrections []Correction
+	Confidence  MatchConfidence
+	Quality     *QualityReport
+}
+
+// streetAbbreviations expands common street abbreviations for comparison
+var streetAbbreviations = map[string]string{
+	"st": "street", "ave": "avenue", "av": "avenue", "rd": "road", "blvd": "boulevard",
+	"dr": "drive", "ln": "lane", "ct": "court", "pl": "place", "sq": "square",
+	"hwy": "highway", "pkwy": "parkway", "ter": "terrace", "cres": "crescent",
+	"n": "north", "s": "south", "e": "east", "w": "west",
+	"ne": "northeast", "nw": "northwest", "se": "southeast", "sw": "southwest",
+}
+
+// normaliseComponent normalises an address component for comparison, expanding street abbreviations
+// Full stops are removed rather than separating words so dotted abbreviations (eg. N.W.) are expanded
+func normaliseComponent(s string) string {
+	words := strings.Fields(NormaliseQuery(strings.Replace(s, ".", "", -1)))
+	for i, w := range words {
+		if full, ok := streetAbbreviations[w]; ok {
+			words[i] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// normalisePostcode removes whitespace and punctuation and upper cases a postcode
+func normalisePostcode(s string) string {
+	return strings.ToUpper(strings.Replace(NormaliseQuery(s), " ", "", -1))
+}
+
+// componentsEqual compares components, treating postcodes as equal where the input extends the result (eg. ZIP+4)
+func componentsEqual(name, input, result string) bool {
+	if name == "postcode" {
+		in, out := normalisePostcode(input), normalisePostcode(result)
+		return in == out || (out != "" && strings.HasPrefix(in, out))
+	}
+	return normaliseComponent(input) == normaliseComponent(result)
+}
+
+// containsComponent checks whether a free text input contains a component as whole words
+// Postcodes may be written with or without internal spaces, so runs of adjacent words are joined for comparison
+func containsComponent(name, text, component string) bool {
+	if name == "postcode" {
+		postcode := normalisePostcode(component)
+		words := strings.Fields(NormaliseQuery(text))
+		for i := range words {
+			joined := ""
+			for _, w := range words[i:] {
+				joined += strings.ToUpper(w)
+				if len(joined) >= len(postcode) {
+					break
+				}
+			}
+			if joined == postcode {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(" "+normaliseComponent(text)+" ", " "+normaliseComponent(component)+" ")
+}
+
+// compare builds the match for a component, recording a correction where the result differs from the input
+func (v *AddressValidation) compare(name, text, input string, results ...string) ComponentMatch {
+	result := ""
+	for _, r := range results {
+		if r != "" {
+			result = r
+			break
+		}
+	}
+	m := ComponentMatch{Input: input, Result: result}
+
+	switch {
+	case text != "":
+		// Free text input, components are matched where they are found in the text
+		m.Input = ""
+		switch {
+		case result == "":
+			m.Status = MatchNotApplicable
+		case containsComponent(name, text, result):
+			m.Status = MatchMatched
+		default:
+			m.Status = MatchInferred
+			v.Corrections = append(v.Corrections, Correction{Component: name, To: result})
+		}
+
+	case input == "" && result == "":
+		m.Status = MatchNotApplicable
+	case input == "":
+		m.Status = MatchInferred
+		v.Corrections = append(v.Corrections, Correction{Component: name, To: result})
+	case result == "":
+		m.Status = MatchUnmatched
+	default:
+		m.Status = MatchUnmatched
+		for _, r := range results {
+			if r != "" && componentsEqual(name, input, r) {
+				m.Status, m.Result = MatchMatched, r
+				break
+			}
+		}
+		if m.Status == MatchUnmatched {
+			v.Corrections = append(v.Corrections, Correction{Component: name, From: input, To: result})
+		}
+	}
+
+	return m
+}
+
+// confidence summarises the component matches and result quality
+func (v *AddressValidation) confidence() MatchConfidence {
+	unmatched := 0
+	for _, m := range []ComponentMatch{v.HouseNumber, v.Street, v.Postcode, v.City} {
+		if m.Status == MatchUnmatched {
+			unmatched++
+		}
+	}
+
+	switch {
+	case v.Quality.Quality == QualityNone || v.Quality.Quality == QualityAmbiguous || unmatched >= 2:
+		return ConfidenceLow
+	case v.HouseNumber.Status == MatchUnmatched:
+		return ConfidenceLow
+	case unmatched == 1 || v.Quality.Quality == QualityPostcode || v.Quality.Quality == QualityCity:
+		return ConfidenceMedium
+	case v.Quality.Quality == QualityStreet || v.Quality.OutsideCountry || v.Quality.OutsideBBox:
+		return ConfidenceHigh
+	default:
+		return ConfidenceExact
+	}
+}
+
+// ValidateAddress geocodes an address and compares the house number, street, postcode and city of the
+// best match against the input, with (optional) forward geocoding options such as a country filter
+// Autocomplete is disabled unless set in the options
+func (g *Geocode) ValidateAddress(input *AddressInput, req *ForwardRequestOpts) (*AddressValidation, error) {
+	if input == nil {
+		return nil, fmt.Errorf("Address validation requires an address")
+	}
+	q := input.query()
+	if strings.TrimSpace(q) == "" {
+		return nil, fmt.Errorf("Address validation requires an address")
+	}
+
+	// Validation compares complete addresses rather than partial input
+	opts := ForwardRequestOpts{}
+	if req != nil {
+		opts = *req
+	}
+	if opts.Autocomplete == nil {
+		opts.Autocomplete = base.Bool(false)
+	}
+
+	resp, err := g.Forward(q, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	qualityOpts := &QualityOpts{Country: opts.Country, BBox: opts.BBox}
+
+	v := &AddressValidation{Quality: resp.Quality(qualityOpts)}
+
+	a := base.Address{}
+	if f := v.Quality.Feature; f != nil {
+		a = f.Address()
+		v.Address = a
+		v.Formatted = f.PlaceName
+		if len(f.Center) == 2 {
+			v.Location = base.Location{Latitude: f.Center[1], Longitude: f.Center[0]}
+		}
+	}
+
+	text := strings.TrimSpace(input.Text)
+	v.HouseNumber = v.compare("house_number", text, input.HouseNumber, a.HouseNumber)
+	v.Street = v.compare("street", text, input.Street, a.Street)
+	v.Postcode = v.compare("postcode", text, input.Postcode, a.Postcode)
+	v.City = v.compare("city", text, input.City, a.Place, a.Locality, a.District)
+	v.Confidence = v.confidence()
+
+	return v, nil
+}
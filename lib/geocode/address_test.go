@@ -0,0 +1,160 @@
+/**
+ * go-mapbox Geocoding Module Address Validation Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+)
+
+const testAddressFeature = `{
+	"id": "address.1",
+	"type": "Feature",
+	"place_type": ["address"],
+	"relevance": 1,
+	"text": "Pennsylvania Avenue Northwest",
+	"address": "1600",
+	"place_name": "1600 Pennsylvania Avenue Northwest, Washington, District of Columbia 20500, United States",
+	"center": [-77.0365, 38.8977],
+	"properties": {"accuracy": "rooftop"},
+	"context": [
+		{"id": "postcode.1", "text": "20500"},
+		{"id": "place.1", "text": "Washington"},
+		{"id": "region.1", "text": "District of Columbia", "short_code": "US-DC"},
+		{"id": "country.1", "text": "United States", "short_code": "us"}
+	]
+}`
+
+func TestValidateAddress(t *testing.T) {
+	var query, autocomplete string
+	b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+		query = strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/geocoding/v5/mapbox.places/"), ".json")
+		autocomplete = r.URL.Query().Get("autocomplete")
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(query, "nowhere") {
+			w.Write([]byte(`{"type": "FeatureCollection", "features": []}`))
+			return
+		}
+		w.Write([]byte(`{"type": "FeatureCollection", "features": [` + testAddressFeature + `]}`))
+	})
+	g := NewGeocode(b)
+	defer done()
+
+	t.Run("Matches structured addresses", func(t *testing.T) {
+		v, err := g.ValidateAddress(&AddressInput{
+			HouseNumber: "1600",
+			Street:      "Pennsylvania Ave NW",
+			Postcode:    "20500-0003",
+			City:        "washington",
+			Country:     "US",
+		}, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "1600 Pennsylvania Ave NW, washington, 20500-0003, US", query)
+
+		assert.Equal(t, ConfidenceExact, v.Confidence)
+		assert.Equal(t, ComponentMatch{Status: MatchMatched, Input: "1600", Result: "1600"}, v.HouseNumber)
+		assert.Equal(t, ComponentMatch{Status: MatchMatched, Input: "Pennsylvania Ave NW", Result: "Pennsylvania Avenue Northwest"}, v.Street)
+		assert.Equal(t, MatchMatched, v.Postcode.Status)
+		assert.Equal(t, MatchMatched, v.City.Status)
+		assert.Empty(t, v.Corrections)
+
+		assert.Equal(t, "US-DC", v.Address.RegionCode)
+		assert.Equal(t, "1600 Pennsylvania Avenue Northwest, Washington, District of Columbia 20500, United States", v.Formatted)
+		assert.Equal(t, base.Location{Latitude: 38.8977, Longitude: -77.0365}, v.Location)
+	})
+
+	t.Run("Suggests corrections", func(t *testing.T) {
+		v, err := g.ValidateAddress(&AddressInput{HouseNumber: "1600", Street: "Pennsylvania Avenue", Postcode: "20501"}, nil)
+		require.Nil(t, err)
+
+		assert.Equal(t, MatchMatched, v.HouseNumber.Status)
+		assert.Equal(t, MatchUnmatched, v.Street.Status)
+		assert.Equal(t, MatchUnmatched, v.Postcode.Status)
+		assert.Equal(t, MatchInferred, v.City.Status)
+		assert.EqualValues(t, []Correction{
+			{Component: "street", From: "Pennsylvania Avenue", To: "Pennsylvania Avenue Northwest"},
+			{Component: "postcode", From: "20501", To: "20500"},
+			{Component: "city", To: "Washington"},
+		}, v.Corrections)
+		assert.Equal(t, ConfidenceLow, v.Confidence)
+
+		v, err = g.ValidateAddress(&AddressInput{HouseNumber: "1600", Street: "Pennsylvania Ave NW", City: "Washington", Postcode: "20501"}, nil)
+		require.Nil(t, err)
+		assert.Equal(t, ConfidenceMedium, v.Confidence)
+
+		v, err = g.ValidateAddress(&AddressInput{HouseNumber: "1601", Street: "Pennsylvania Ave NW", City: "Washington"}, nil)
+		require.Nil(t, err)
+		assert.Equal(t, MatchUnmatched, v.HouseNumber.Status)
+		assert.Equal(t, ConfidenceLow, v.Confidence)
+	})
+
+	t.Run("Matches free text addresses", func(t *testing.T) {
+		v, err := g.ValidateAddress(&AddressInput{Text: "1600 Pennsylvania Ave. N.W., Washington DC"}, nil)
+		require.Nil(t, err)
+
+		assert.Equal(t, MatchMatched, v.HouseNumber.Status)
+		assert.Equal(t, MatchMatched, v.Street.Status)
+		assert.Equal(t, MatchMatched, v.City.Status)
+		assert.Equal(t, ComponentMatch{Status: MatchInferred, Result: "20500"}, v.Postcode)
+		assert.EqualValues(t, []Correction{{Component: "postcode", To: "20500"}}, v.Corrections)
+		assert.Equal(t, ConfidenceExact, v.Confidence)
+	})
+
+	t.Run("Matches postcodes as whole words", func(t *testing.T) {
+		assert.False(t, containsComponent("postcode", "16011 Main St", "6011"))
+		assert.False(t, containsComponent("postcode", "1 Main St, 60110", "6011"))
+		assert.True(t, containsComponent("postcode", "1 Main St, 6011", "6011"))
+		assert.True(t, containsComponent("postcode", "10 Downing St, London SW1A 2AA", "SW1A 2AA"))
+		assert.True(t, containsComponent("postcode", "10 Downing St, London sw1a2aa", "SW1A 2AA"))
+		assert.True(t, containsComponent("postcode", "1600 Pennsylvania Ave NW, 20500-0003", "20500"))
+	})
+
+	t.Run("Disables autocomplete unless requested", func(t *testing.T) {
+		_, err := g.ValidateAddress(&AddressInput{Text: "1600 Pennsylvania Ave NW"}, nil)
+		require.Nil(t, err)
+		assert.Equal(t, "false", autocomplete)
+
+		req := &ForwardRequestOpts{Filter: base.Filter{Country: "us"}}
+		_, err = g.ValidateAddress(&AddressInput{Text: "1600 Pennsylvania Ave NW"}, req)
+		require.Nil(t, err)
+		assert.Equal(t, "false", autocomplete)
+		assert.Nil(t, req.Autocomplete)
+
+		_, err = g.ValidateAddress(&AddressInput{Text: "1600 Pennsylvania Ave NW"}, &ForwardRequestOpts{Autocomplete: base.Bool(true)})
+		require.Nil(t, err)
+		assert.Equal(t, "true", autocomplete)
+	})
+
+	t.Run("Flags results outside the requested country", func(t *testing.T) {
+		v, err := g.ValidateAddress(&AddressInput{Text: "1600 Pennsylvania Ave NW, Washington, 20500"}, &ForwardRequestOpts{Filter: base.Filter{Country: "ca"}})
+		require.Nil(t, err)
+		assert.True(t, v.Quality.OutsideCountry)
+		assert.Equal(t, ConfidenceHigh, v.Confidence)
+	})
+
+	t.Run("Handles missing results", func(t *testing.T) {
+		v, err := g.ValidateAddress(&AddressInput{Street: "nowhere", City: "Springfield"}, nil)
+		require.Nil(t, err)
+		assert.Equal(t, QualityNone, v.Quality.Quality)
+		assert.Equal(t, MatchUnmatched, v.Street.Status)
+		assert.Equal(t, MatchNotApplicable, v.HouseNumber.Status)
+		assert.Equal(t, ConfidenceLow, v.Confidence)
+
+		_, err = g.ValidateAddress(nil, nil)
+		assert.NotNil(t, err)
+		_, err = g.ValidateAddress(&AddressInput{Text: " "}, nil)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,255 @@
+/**
+ * go-mapbox Geocoding Module Autocomplete Sessions
+ * Debounces search-as-you-type input, cancelling superseded requests so only results
+ * for the latest query are delivered
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// DefaultDebounce is the default delay after the last input before a query is made
+const DefaultDebounce = 200 * time.Millisecond
+
+// AutocompleteOpts configures an autocomplete session
+type AutocompleteOpts struct {
+	Debounce  time.Duration            // Delay after the last input before a query is made, defaults to DefaultDebounce
+	MinLength int                      // Queries shorter than this clear results without a request
+	Options   ForwardRequestOpts       // Forward geocoding options such as proximity, country and types
+	Callback  func(AutocompleteResult) // Called with results in place of the Results channel, must not call Update
+}
+
+// AutocompleteResult is the response to the latest query of an autocomplete session
+// Response is nil where the query was too short to search
+type AutocompleteResult struct {
+	Query    string
+	Response *ForwardResponse
+	Err      error
+}
+
+// AutocompleteSession debounces queries and delivers results for the latest query only
+type AutocompleteSession struct {
+	g        *Geocode
+	debounce time.Duration
+	minLen   int
+	callback func(AutocompleteResult)
+
+	mu    sync.Mutex
+	opts  ForwardRequestOpts
+	token string
+
+	input     chan string
+	responses chan autocompleteResponse
+	results   chan AutocompleteResult
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closed    chan struct{}
+}
+
+type autocompleteResponse struct {
+	seq    int
+	result AutocompleteResult
+}
+
+// NewAutocompleteSession creates an autocomplete session, which runs until Close is called or the context is done
+func (g *Geocode) NewAutocompleteSession(ctx context.Context, opts *AutocompleteOpts) (*AutocompleteSession, error) {
+	o := AutocompleteOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Debounce == 0 {
+		o.Debounce = DefaultDebounce
+	}
+	if o.Options.Autocomplete == nil {
+		o.Options.Autocomplete = base.Bool(true)
+	}
+	if err := o.Options.Validate(); err != nil {
+		return nil, err
+	}
+
+	token, err := base.NewSessionToken()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &AutocompleteSession{
+		g:         g,
+		debounce:  o.Debounce,
+		minLen:    o.MinLength,
+		callback:  o.Callback,
+		opts:      o.Options,
+		token:     token,
+		input:     make(chan string),
+		responses: make(chan autocompleteResponse),
+		results:   make(chan AutocompleteResult, 1),
+		closed:    make(chan struct{}),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	go s.run()
+
+	return s, nil
+}
+
+// Update sets the current query, superseding any pending or in flight query
+func (s *AutocompleteSession) Update(query string) {
+	select {
+	case s.input <- query:
+	case <-s.closed:
+	}
+}
+
+// Results returns the channel results are delivered on, which is closed when the session ends
+// Results not yet received are replaced by those for newer queries
+func (s *AutocompleteSession) Results() <-chan AutocompleteResult {
+	return s.results
+}
+
+// Token returns the session token sent with each request
+func (s *AutocompleteSession) Token() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.token
+}
+
+// Reset starts a new billing session with a new token, call this once a result has been selected
+func (s *AutocompleteSession) Reset() error {
+	token, err := base.NewSessionToken()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+	return nil
+}
+
+// SetProximity biases subsequent queries towards a location, or removes the bias if nil
+func (s *AutocompleteSession) SetProximity(loc *base.Location) error {
+	proximity := []float64(nil)
+	if loc != nil {
+		proximity = []float64{loc.Longitude, loc.Latitude}
+		if err := base.ValidateLocation("Proximity", proximity); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.opts.Proximity = proximity
+	s.opts.ProximityIP = false
+	s.mu.Unlock()
+	return nil
+}
+
+// Close ends the session, cancelling any in flight query and closing the Results channel
+func (s *AutocompleteSession) Close() {
+	s.cancel()
+	<-s.closed
+}
+
+// start begins a forward request for a query, returning a function cancelling the request
+func (s *AutocompleteSession) start(seq int, query string) context.CancelFunc {
+	ctx, cancel := context.WithCancel(s.ctx)
+	go s.request(ctx, seq, query)
+	return cancel
+}
+
+// request makes a forward request for a query, with options and the token captured when it starts
+func (s *AutocompleteSession) request(ctx context.Context, seq int, query string) {
+	s.mu.Lock()
+	opts := s.opts
+	opts.SessionToken = s.token
+	s.mu.Unlock()
+
+	resp, err := s.g.ForwardWithContext(ctx, query, &opts)
+	if err != nil {
+		resp = nil
+	}
+
+	select {
+	case s.responses <- autocompleteResponse{seq: seq, result: AutocompleteResult{Query: query, Response: resp, Err: err}}:
+	case <-s.closed:
+	}
+}
+
+// deliver passes a result to the callback, or the results channel replacing any undelivered result
+func (s *AutocompleteSession) deliver(r AutocompleteResult) {
+	if s.callback != nil {
+		s.callback(r)
+		return
+	}
+	for {
+		select {
+		case s.results <- r:
+			return
+		default:
+		}
+		select {
+		case <-s.results:
+		default:
+		}
+	}
+}
+
+func (s *AutocompleteSession) run() {
+	timer := time.NewTimer(s.debounce)
+	timer.Stop()
+
+	var latest string
+	seq := 0
+	cancel := func() {}
+
+	defer func() {
+		timer.Stop()
+		cancel()
+		close(s.closed)
+		close(s.results)
+	}()
+
+	for {
+		select {
+		case q := <-s.input:
+			if q == latest {
+				continue
+			}
+			// Any in flight query is superseded by new input
+			latest = q
+			seq++
+			cancel()
+			cancel = func() {}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(s.debounce)
+
+		case <-timer.C:
+			query := latest
+			trimmed := strings.TrimSpace(query)
+			if trimmed == "" || len([]rune(trimmed)) < s.minLen {
+				s.deliver(AutocompleteResult{Query: query})
+				continue
+			}
+			cancel = s.start(seq, query)
+
+		case r := <-s.responses:
+			if r.seq == seq {
+				s.deliver(r.result)
+			}
+
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,173 @@
+/**
+ * go-mapbox Geocoding Module Autocomplete Session Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+)
+
+func TestAutocompleteSession(t *testing.T) {
+	var mu sync.Mutex
+	requests := []*http.Request{}
+	started := make(chan string, 10)
+	cancelled := make(chan string, 10)
+
+	b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+		q := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/geocoding/v5/mapbox.places/"), ".json")
+		mu.Lock()
+		requests = append(requests, r)
+		mu.Unlock()
+		started <- q
+
+		if strings.HasPrefix(q, "slow") {
+			select {
+			case <-r.Context().Done():
+				cancelled <- q
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type": "FeatureCollection", "features": [{"id": "place.1", "place_name": "` + q + `"}]}`))
+	})
+	g := NewGeocode(b)
+	defer done()
+
+	receive := func(t *testing.T, results <-chan AutocompleteResult) AutocompleteResult {
+		select {
+		case r := <-results:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout waiting for result")
+		}
+		return AutocompleteResult{}
+	}
+
+	t.Run("Debounces input and tags requests", func(t *testing.T) {
+		requests = nil
+		s, err := g.NewAutocompleteSession(context.Background(), &AutocompleteOpts{
+			Debounce: 50 * time.Millisecond,
+			Options:  ForwardRequestOpts{Filter: base.Filter{Proximity: []float64{-77.03, 38.90}}},
+		})
+		require.Nil(t, err)
+		defer s.Close()
+
+		for _, q := range []string{"s", "sp", "spr", "springfield"} {
+			s.Update(q)
+		}
+
+		r := receive(t, s.Results())
+		require.Nil(t, r.Err)
+		assert.Equal(t, "springfield", r.Query)
+		require.NotNil(t, r.Response)
+		assert.Equal(t, "springfield", r.Response.Features[0].PlaceName)
+
+		mu.Lock()
+		require.Len(t, requests, 1)
+		params := requests[0].URL.Query()
+		mu.Unlock()
+		assert.Equal(t, s.Token(), params.Get("session_token"))
+		assert.Equal(t, "true", params.Get("autocomplete"))
+		assert.Equal(t, "-77.03,38.9", params.Get("proximity"))
+		<-started
+
+		// Proximity and token updates apply to subsequent requests
+		token := s.Token()
+		require.Nil(t, s.Reset())
+		assert.NotEqual(t, token, s.Token())
+		require.Nil(t, s.SetProximity(&base.Location{Latitude: 1, Longitude: 2}))
+
+		s.Update("springfield il")
+		r = receive(t, s.Results())
+		assert.Equal(t, "springfield il", r.Query)
+
+		mu.Lock()
+		require.Len(t, requests, 2)
+		params = requests[1].URL.Query()
+		mu.Unlock()
+		assert.Equal(t, s.Token(), params.Get("session_token"))
+		assert.Equal(t, "2,1", params.Get("proximity"))
+		<-started
+	})
+
+	t.Run("Cancels superseded queries", func(t *testing.T) {
+		s, err := g.NewAutocompleteSession(context.Background(), &AutocompleteOpts{Debounce: time.Millisecond})
+		require.Nil(t, err)
+		defer s.Close()
+
+		s.Update("slow")
+		assert.Equal(t, "slow", <-started)
+
+		s.Update("fast")
+		select {
+		case q := <-cancelled:
+			assert.Equal(t, "slow", q)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Superseded query was not cancelled")
+		}
+
+		r := receive(t, s.Results())
+		assert.Nil(t, r.Err)
+		assert.Equal(t, "fast", r.Query)
+		<-started
+	})
+
+	t.Run("Clears results for short queries via callback", func(t *testing.T) {
+		results := make(chan AutocompleteResult, 1)
+		s, err := g.NewAutocompleteSession(context.Background(), &AutocompleteOpts{
+			Debounce:  time.Millisecond,
+			MinLength: 3,
+			Callback:  func(r AutocompleteResult) { results <- r },
+		})
+		require.Nil(t, err)
+
+		s.Update("ab")
+		r := receive(t, results)
+		assert.Equal(t, "ab", r.Query)
+		assert.Nil(t, r.Response)
+		assert.Nil(t, r.Err)
+
+		s.Close()
+		_, ok := <-s.Results()
+		assert.False(t, ok)
+
+		// Updates after closing are ignored
+		s.Update("abc")
+	})
+
+	t.Run("Ends with the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		s, err := g.NewAutocompleteSession(ctx, nil)
+		require.Nil(t, err)
+
+		cancel()
+		select {
+		case _, ok := <-s.Results():
+			assert.False(t, ok)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Session did not end with the context")
+		}
+	})
+
+	t.Run("Validates options", func(t *testing.T) {
+		_, err := g.NewAutocompleteSession(context.Background(), &AutocompleteOpts{Options: ForwardRequestOpts{Limit: 100}})
+		assert.NotNil(t, err)
+	})
+}
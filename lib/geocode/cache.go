@@ -0,0 +1,289 @@
+/**
+ * go-mapbox Geocoding Module Cache
+ * Caches forward and reverse geocoding responses to avoid repeating lookups of the same queries
+ * Note that the terms of service only permit storing results from the permanent endpoint,
+ * so temporary responses are only cached where explicitly allowed
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// DefaultCachePrecision is the default number of decimal places reverse geocoding coordinates
+// are rounded to when building cache keys, approximately 1m
+const DefaultCachePrecision = 5
+
+// Cache interface defines an abstract geocoding response cache
+// Fetch returns nil data for missing or expired entries, a zero TTL never expires
+type Cache interface {
+	Save(key string, data []byte, ttl time.Duration) error
+	Fetch(key string) ([]byte, error)
+}
+
+// CacheOpts configures response caching
+type CacheOpts struct {
+	TTL            time.Duration // Time responses are cached for, zero to never expire
+	Precision      uint          // Decimal places reverse coordinates are rounded to, defaults to DefaultCachePrecision
+	AllowTemporary bool          // Cache responses from the temporary endpoint, only where permitted by your terms of service
+}
+
+func withCacheDefaults(opts *CacheOpts) CacheOpts {
+	o := CacheOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Precision == 0 {
+		o.Precision = DefaultCachePrecision
+	}
+	return o
+}
+
+// SetCache binds a response cache into the geocode instance, a nil cache disables caching
+func (g *Geocode) SetCache(c Cache, opts *CacheOpts) {
+	g.cache = c
+	g.cacheOpts = withCacheDefaults(opts)
+}
+
+// NormaliseQuery normalises a search string for use in cache keys, lower casing,
+// replacing punctuation with spaces and collapsing whitespace
+func NormaliseQuery(q string) string {
+	q = strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return ' '
+		}
+		return unicode.ToLower(r)
+	}, q)
+	return strings.Join(strings.Fields(q), " ")
+}
+
+// roundCoordinate rounds a coordinate to the provided number of decimal places
+func roundCoordinate(v float64, precision int) string {
+	scale := math.Pow(10, float64(precision))
+	return strconv.FormatFloat(math.Round(v*scale)/scale, 'f', precision, 64)
+}
+
+// cacheKey builds a cache key from the request kind, endpoint, query and encoded options,
+// returning an empty key where the response must not be cached
+func (g *Geocode) cacheKey(kind, mode, query string, v url.Values) string {
+	if g.cache == nil {
+		return ""
+	}
+	if mode != apiModePermanent && !g.cacheOpts.AllowTemporary {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s?%s", kind, mode, query, v.Encode())
+}
+
+func (g *Geocode) forwardCacheKey(place string, req *ForwardRequestOpts, v url.Values) string {
+	// Session tokens do not affect results
+	keyed := url.Values{}
+	for k, values := range v {
+		if k != "session_token" {
+			keyed[k] = values
+		}
+	}
+	return g.cacheKey("forward", req.mode(), NormaliseQuery(place), keyed)
+}
+
+func (g *Geocode) reverseCacheKey(loc *base.Location, req *ReverseRequestOpts, v url.Values) string {
+	p := int(g.cacheOpts.Precision)
+	query := roundCoordinate(loc.Longitude, p) + "," + roundCoordinate(loc.Latitude, p)
+	return g.cacheKey("reverse", req.mode(), query, v)
+}
+
+// fetchCached loads a cached response into v, returning true on a cache hit
+func (g *Geocode) fetchCached(key string, v interface{}) bool {
+	if key == "" {
+		return false
+	}
+	data, err := g.cache.Fetch(key)
+	if err != nil {
+		log.Printf("Cache fetch error (%s)", err)
+		return false
+	}
+	if data == nil {
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Printf("Cache decode error (%s)", err)
+		return false
+	}
+	return true
+}
+
+// saveCached saves a response to the cache
+func (g *Geocode) saveCached(key string, v interface{}) {
+	if key == "" {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err == nil {
+		err = g.cache.Save(key, data, g.cacheOpts.TTL)
+	}
+	if err != nil {
+		log.Printf("Cache save error (%s)", err)
+	}
+}
+
+// expiry returns the expiry time for a TTL, or the zero time for entries that never expire
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func expired(now, expires time.Time) bool {
+	return !expires.IsZero() && !now.Before(expires)
+}
+
+type memoryCacheEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// MemoryCache is a simple in-memory caching implementation for geocoding responses
+// Expired entries are removed when fetched, so this is best suited to short lived or bounded workloads
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+	now     func() time.Time
+}
+
+// NewMemoryCache creates a new memory cache instance
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry), now: time.Now}
+}
+
+// Save saves a response to the memory cache
+func (mc *MemoryCache) Save(key string, data []byte, ttl time.Duration) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.entries[key] = memoryCacheEntry{data: data, expires: expiry(mc.now(), ttl)}
+
+	return nil
+}
+
+// Fetch fetches a response from the memory cache if present and not expired
+func (mc *MemoryCache) Fetch(key string) ([]byte, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	e, ok := mc.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if expired(mc.now(), e.expires) {
+		delete(mc.entries, key)
+		return nil, nil
+	}
+
+	return e.data, nil
+}
+
+// FileCache is a simple file-based caching implementation for geocoding responses
+// Entries are stored in files named by the hash of their key, expired entries are removed when fetched
+type FileCache struct {
+	basePath string
+	now      func() time.Time
+}
+
+type fileCacheEntry struct {
+	Key     string          `json:"key"`
+	Expires time.Time       `json:"expires"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// NewFileCache creates a new file cache instance
+func NewFileCache(basePath string) (*FileCache, error) {
+	fc := &FileCache{basePath: basePath, now: time.Now}
+
+	err := os.Mkdir(basePath, 0777)
+	if err != nil && !os.IsExist(err) {
+		return nil, err
+	}
+
+	return fc, nil
+}
+
+func (fc *FileCache) getPath(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%s/%s.json", fc.basePath, hex.EncodeToString(hash[:]))
+}
+
+// Save saves a response to the file cache
+func (fc *FileCache) Save(key string, data []byte, ttl time.Duration) error {
+	entry, err := json.Marshal(&fileCacheEntry{Key: key, Expires: expiry(fc.now(), ttl), Data: data})
+	if err != nil {
+		return err
+	}
+
+	// Write via a temporary file so concurrent readers never see a partial entry
+	path := fc.getPath(key)
+	tmp, err := ioutil.TempFile(fc.basePath, "tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(entry)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Fetch fetches a response from the file cache if present and not expired
+func (fc *FileCache) Fetch(key string) ([]byte, error) {
+	path := fc.getPath(key)
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := fileCacheEntry{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("Error parsing cache entry %s (%s)", path, err)
+	}
+	// Hash collisions are treated as misses
+	if entry.Key != key {
+		return nil, nil
+	}
+	if expired(fc.now(), entry.Expires) {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return entry.Data, nil
+}
@@ -0,0 +1,168 @@
+/**
+ * go-mapbox Geocoding Module Cache Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+)
+
+func TestNormaliseQuery(t *testing.T) {
+	assert.Equal(t, "1 main st springfield", NormaliseQuery("  1 Main St., Springfield "))
+	assert.Equal(t, "1 main st springfield", NormaliseQuery("1 MAIN ST SPRINGFIELD"))
+	assert.Equal(t, "münchen", NormaliseQuery("München!"))
+	assert.Equal(t, "", NormaliseQuery(" , "))
+}
+
+func TestCacheExpiry(t *testing.T) {
+	now := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	dir, err := ioutil.TempDir("", "geocode-cache")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	fc, err := NewFileCache(dir)
+	require.Nil(t, err)
+	fc.now = clock
+
+	mc := NewMemoryCache()
+	mc.now = clock
+
+	caches := map[string]Cache{"memory": mc, "file": fc}
+	for name, c := range caches {
+		t.Run(name, func(t *testing.T) {
+			now = time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			data, err := c.Fetch("missing")
+			assert.Nil(t, err)
+			assert.Nil(t, data)
+
+			require.Nil(t, c.Save("expiring", []byte(`{"a":1}`), time.Hour))
+			require.Nil(t, c.Save("forever", []byte(`{"b":2}`), 0))
+
+			data, err = c.Fetch("expiring")
+			assert.Nil(t, err)
+			assert.Equal(t, `{"a":1}`, string(data))
+
+			now = now.Add(time.Hour)
+			data, err = c.Fetch("expiring")
+			assert.Nil(t, err)
+			assert.Nil(t, data)
+
+			now = now.Add(24 * 365 * time.Hour)
+			data, err = c.Fetch("forever")
+			assert.Nil(t, err)
+			assert.Equal(t, `{"b":2}`, string(data))
+		})
+	}
+
+	// File cache entries persist across instances
+	fc2, err := NewFileCache(dir)
+	require.Nil(t, err)
+	data, err := fc2.Fetch("forever")
+	assert.Nil(t, err)
+	assert.Equal(t, `{"b":2}`, string(data))
+}
+
+func TestGeocodeCache(t *testing.T) {
+	requests := 0
+	b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := `["springfield"]`
+		if strings.Contains(r.URL.Path, ",") {
+			query = `[1, 2]`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"type": "FeatureCollection", "query": ` + query + `, "features": [{"id": "place.1", "place_name": "Springfield", "relevance": 1, "center": [1, 2]}]}`))
+	})
+	g := NewGeocode(b)
+	defer done()
+
+	g.SetCache(NewMemoryCache(), nil)
+	permanent := &ForwardRequestOpts{Permanent: true}
+
+	t.Run("Caches permanent forward responses by normalised query", func(t *testing.T) {
+		requests = 0
+
+		resp, err := g.Forward("Springfield", permanent)
+		require.Nil(t, err)
+		resp, err = g.Forward("  SPRINGFIELD. ", permanent)
+		require.Nil(t, err)
+		assert.Equal(t, 1, requests)
+		require.Len(t, resp.Features, 1)
+		assert.Equal(t, "Springfield", resp.Features[0].PlaceName)
+		assert.EqualValues(t, base.Point{1, 2}, resp.Features[0].Center)
+		assert.EqualValues(t, []string{"springfield"}, resp.Query)
+
+		// Options are part of the key
+		_, err = g.Forward("Springfield", &ForwardRequestOpts{Permanent: true, Filter: base.Filter{Country: "us"}})
+		require.Nil(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("Does not cache temporary responses by default", func(t *testing.T) {
+		requests = 0
+
+		for i := 0; i < 2; i++ {
+			_, err := g.Forward("Springfield", nil)
+			require.Nil(t, err)
+		}
+		assert.Equal(t, 2, requests)
+
+		g.SetCache(NewMemoryCache(), &CacheOpts{AllowTemporary: true})
+		for i := 0; i < 2; i++ {
+			_, err := g.Forward("Springfield", nil)
+			require.Nil(t, err)
+		}
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("Rounds reverse coordinates", func(t *testing.T) {
+		requests = 0
+		g.SetCache(NewMemoryCache(), &CacheOpts{Precision: 3})
+
+		opts := &ReverseRequestOpts{Permanent: true}
+		_, err := g.Reverse(&base.Location{Latitude: 51.50001, Longitude: -0.12001}, opts)
+		require.Nil(t, err)
+		_, err = g.Reverse(&base.Location{Latitude: 51.50029, Longitude: -0.11979}, opts)
+		require.Nil(t, err)
+		assert.Equal(t, 1, requests)
+
+		_, err = g.Reverse(&base.Location{Latitude: 51.501, Longitude: -0.12}, opts)
+		require.Nil(t, err)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("Errors are not cached", func(t *testing.T) {
+		requests = 0
+		b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+		failing := NewGeocode(b)
+		defer done()
+		failing.SetCache(NewMemoryCache(), nil)
+
+		for i := 0; i < 2; i++ {
+			_, err := failing.Forward("Springfield", permanent)
+			assert.EqualValues(t, base.ErrorAPIUnauthorized, err)
+		}
+		assert.Equal(t, 2, requests)
+	})
+}
@@ -10,7 +10,9 @@
 package geocode
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/google/go-querystring/query"
@@ -21,6 +23,8 @@ const (
 	apiName    = "geocoding"
 	apiVersion = "v5"
 	apiMode    = "mapbox.places"
+
+	apiModePermanent = "mapbox.places-permanent"
 )
 
 // Type defines geocode location response types
@@ -49,22 +53,63 @@ const (
 
 // Geocode api wrapper instance
 type Geocode struct {
-	base *base.Base
+	base      *base.Base
+	cache     Cache
+	cacheOpts CacheOpts
 }
 
 // NewGeocode Create a new Geocode API wrapper
 func NewGeocode(base *base.Base) *Geocode {
-	return &Geocode{base}
+	return &Geocode{base: base}
 }
 
+// MaxForwardLimit is the maximum number of forward geocoding results
+const MaxForwardLimit = 10
+
 // ForwardRequestOpts request options fo forward geocoding
 type ForwardRequestOpts struct {
-	Country      string           `url:"country,omitempty"`
-	Proximity    []float64        `url:"proximity,omitempty"`
-	Types        []Type           `url:"types,omitempty"`
-	Autocomplete bool             `url:"autocomplete,omitempty"`
-	BBox         base.BoundingBox `url:"bbox,omitempty"`
-	Limit        uint             `url:"limit,omitempty"`
+	base.Filter
+	Types        []Type `url:"types,comma,omitempty"`
+	Autocomplete *bool  `url:"autocomplete,omitempty"` // Defaults to true when not set
+	Limit        uint   `url:"limit,omitempty"`
+	Worldview    string `url:"worldview,omitempty"`
+	FuzzyMatch   *bool  `url:"fuzzyMatch,omitempty"` // Defaults to true when not set
+	Routing      bool   `url:"routing,omitempty"`
+	Permanent    bool   `url:"-"`                       // Use the mapbox.places-permanent endpoint for results that will be stored
+	SessionToken string `url:"session_token,omitempty"` // Groups autocomplete requests into a session for billing
+}
+
+// Validate checks forward geocoding options before a request is made
+func (o *ForwardRequestOpts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := o.Filter.Validate(); err != nil {
+		return err
+	}
+	if err := validateTypes(o.Types, types); err != nil {
+		return err
+	}
+	if o.Limit > MaxForwardLimit {
+		return fmt.Errorf("Limit (%d) exceeds maximum of %d", o.Limit, MaxForwardLimit)
+	}
+	return base.ValidateWorldview(o.Worldview)
+}
+
+// values encodes forward geocoding options as query parameters
+func (o *ForwardRequestOpts) values() (url.Values, error) {
+	if o == nil {
+		return url.Values{}, nil
+	}
+	return o.Filter.Values(o)
+}
+
+// mode returns the geocoding endpoint for the options
+func (o *ForwardRequestOpts) mode() string {
+	if o != nil && o.Permanent {
+		return apiModePermanent
+	}
+	return apiMode
 }
 
 // ForwardResponse is the response from a forward geocode lookup
@@ -76,25 +121,112 @@ type ForwardResponse struct {
 // Forward geocode lookup
 // Finds locations from a place name
 func (g *Geocode) Forward(place string, req *ForwardRequestOpts) (*ForwardResponse, error) {
+	return g.ForwardWithContext(context.Background(), place, req)
+}
 
-	v, err := query.Values(req)
+// ForwardWithContext performs a forward geocode lookup that is cancelled along with the provided context
+func (g *Geocode) ForwardWithContext(ctx context.Context, place string, req *ForwardRequestOpts) (*ForwardResponse, error) {
+	if strings.TrimSpace(place) == "" {
+		return nil, fmt.Errorf("Forward geocoding requires a search query")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	v, err := req.values()
 	if err != nil {
 		return nil, err
 	}
 
 	resp := ForwardResponse{}
 
-	queryString := strings.Replace(place, " ", "+", -1)
+	key := g.forwardCacheKey(place, req, v)
+	if g.fetchCached(key, &resp) {
+		return &resp, nil
+	}
 
-	err = g.base.Query(apiName, apiVersion, apiMode, fmt.Sprintf("%s.json", queryString), &v, &resp)
+	// The query is a path segment, so reserved characters such as #, ? and / must be escaped
+	queryString := url.PathEscape(place)
+
+	err = g.base.QueryWithContext(ctx, apiName, apiVersion, req.mode(), fmt.Sprintf("%s.json", queryString), &v, &resp)
+	if err == nil {
+		g.saveCached(key, &resp)
+	}
 
 	return &resp, err
 }
 
+// MaxReverseLimit is the maximum number of reverse geocoding results
+const MaxReverseLimit = 5
+
+// ReverseMode defines how reverse geocoding results are sorted
+type ReverseMode string
+
+const (
+	// ReverseModeDistance sorts results by distance from the location
+	ReverseModeDistance ReverseMode = "distance"
+	// ReverseModeScore sorts results by score, favouring prominent features
+	ReverseModeScore ReverseMode = "score"
+)
+
 // ReverseRequestOpts request options fo reverse geocoding
+// Limit may only be used with a single type, as the API otherwise returns one result per type
 type ReverseRequestOpts struct {
-	Types []Type
-	Limit uint
+	Types       []Type      `url:"types,comma,omitempty"`
+	Limit       uint        `url:"limit,omitempty"`
+	Country     string      `url:"country,omitempty"`
+	Language    []string    `url:"language,comma,omitempty"`
+	ReverseMode ReverseMode `url:"reverseMode,omitempty"`
+	Routing     bool        `url:"routing,omitempty"`
+	Worldview   string      `url:"worldview,omitempty"`
+	Permanent   bool        `url:"-"` // Use the mapbox.places-permanent endpoint for results that will be stored
+}
+
+// Validate checks reverse geocoding options before a request is made
+func (o *ReverseRequestOpts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := validateTypes(o.Types, types); err != nil {
+		return err
+	}
+	if o.Limit > MaxReverseLimit {
+		return fmt.Errorf("Limit (%d) exceeds maximum of %d", o.Limit, MaxReverseLimit)
+	}
+	if o.Limit > 0 && len(o.Types) > 1 {
+		return fmt.Errorf("Limit can only be used with a single type (received %d types)", len(o.Types))
+	}
+	if o.Limit > 1 && len(o.Types) == 0 {
+		return fmt.Errorf("Limit greater than 1 requires a single type")
+	}
+	switch o.ReverseMode {
+	case "", ReverseModeDistance, ReverseModeScore:
+	default:
+		return fmt.Errorf("Unsupported reverse mode (%s)", o.ReverseMode)
+	}
+	if err := base.ValidateCountry(o.Country); err != nil {
+		return err
+	}
+	if err := base.ValidateLanguages(o.Language); err != nil {
+		return err
+	}
+	return base.ValidateWorldview(o.Worldview)
+}
+
+// values encodes reverse geocoding options as query parameters
+func (o *ReverseRequestOpts) values() (url.Values, error) {
+	if o == nil {
+		return url.Values{}, nil
+	}
+	return query.Values(o)
+}
+
+// mode returns the geocoding endpoint for the options
+func (o *ReverseRequestOpts) mode() string {
+	if o != nil && o.Permanent {
+		return apiModePermanent
+	}
+	return apiMode
 }
 
 // ReverseResponse is the response to a reverse geocode request
@@ -106,17 +238,34 @@ type ReverseResponse struct {
 // Reverse geocode lookup
 // Finds place names from a location
 func (g *Geocode) Reverse(loc *base.Location, req *ReverseRequestOpts) (*ReverseResponse, error) {
+	if loc == nil {
+		return nil, fmt.Errorf("Reverse geocoding requires a location")
+	}
+	if err := base.ValidateLocation("Location", []float64{loc.Longitude, loc.Latitude}); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
 
-	v, err := query.Values(req)
+	v, err := req.values()
 	if err != nil {
 		return nil, err
 	}
 
 	resp := ReverseResponse{}
 
+	key := g.reverseCacheKey(loc, req, v)
+	if g.fetchCached(key, &resp) {
+		return &resp, nil
+	}
+
 	queryString := fmt.Sprintf("%f,%f.json", loc.Longitude, loc.Latitude)
 
-	err = g.base.Query(apiName, apiVersion, apiMode, queryString, &v, &resp)
+	err = g.base.Query(apiName, apiVersion, req.mode(), queryString, &v, &resp)
+	if err == nil {
+		g.saveCached(key, &resp)
+	}
 
 	return &resp, err
 }
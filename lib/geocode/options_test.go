@@ -0,0 +1,166 @@
+/**
+ * go-mapbox Geocoding Module Option Tests
+ * Checks option validation and encoding, no API token is required
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+)
+
+func TestForwardOpts(t *testing.T) {
+	t.Run("Encodes options", func(t *testing.T) {
+		opts := ForwardRequestOpts{
+			Filter: base.Filter{
+				Country:   "nz,au",
+				Proximity: []float64{174.7762, -41.2865},
+				BBox:      base.BoundingBox{174.6, -41.4, 174.9, -41.2},
+				Language:  []string{"en", "mi"},
+			},
+			Types:        []Type{Address, POI},
+			Autocomplete: base.Bool(false),
+			Limit:        5,
+			Worldview:    "us",
+			FuzzyMatch:   base.Bool(false),
+			Routing:      true,
+			Permanent:    true,
+		}
+		assert.Nil(t, opts.Validate())
+
+		v, err := opts.values()
+		assert.Nil(t, err)
+		assert.EqualValues(t, "nz,au", v.Get("country"))
+		assert.EqualValues(t, "174.7762,-41.2865", v.Get("proximity"))
+		assert.EqualValues(t, "address,poi", v.Get("types"))
+		assert.EqualValues(t, "false", v.Get("autocomplete"))
+		assert.EqualValues(t, "174.6,-41.4,174.9,-41.2", v.Get("bbox"))
+		assert.EqualValues(t, "5", v.Get("limit"))
+		assert.EqualValues(t, "en,mi", v.Get("language"))
+		assert.EqualValues(t, "us", v.Get("worldview"))
+		assert.EqualValues(t, "false", v.Get("fuzzyMatch"))
+		assert.EqualValues(t, "true", v.Get("routing"))
+		assert.EqualValues(t, "mapbox.places-permanent", opts.mode())
+		assert.Len(t, v, 10)
+	})
+
+	t.Run("Omits unset options", func(t *testing.T) {
+		opts := ForwardRequestOpts{Filter: base.Filter{ProximityIP: true}}
+		v, err := opts.values()
+		assert.Nil(t, err)
+		assert.EqualValues(t, "ip", v.Get("proximity"))
+		assert.Len(t, v, 1)
+		assert.EqualValues(t, "mapbox.places", opts.mode())
+
+		var nilOpts *ForwardRequestOpts
+		assert.Nil(t, nilOpts.Validate())
+		v, err = nilOpts.values()
+		assert.Nil(t, err)
+		assert.Len(t, v, 0)
+	})
+
+	t.Run("Validates options", func(t *testing.T) {
+		for name, opts := range map[string]ForwardRequestOpts{
+			"proximity and ip": {Filter: base.Filter{Proximity: []float64{0, 0}, ProximityIP: true}},
+			"proximity length": {Filter: base.Filter{Proximity: []float64{0}}},
+			"proximity range":  {Filter: base.Filter{Proximity: []float64{0, 91}}},
+			"type":             {Types: []Type{"street"}},
+			"bbox length":      {Filter: base.Filter{BBox: base.BoundingBox{0, 0, 1}}},
+			"bbox order":       {Filter: base.Filter{BBox: base.BoundingBox{1, 1, 0, 0}}},
+			"limit":            {Limit: MaxForwardLimit + 1},
+			"country":          {Filter: base.Filter{Country: "nzl"}},
+			"language":         {Filter: base.Filter{Language: []string{"en", "english"}}},
+			"worldview":        {Worldview: "usa"},
+		} {
+			assert.NotNil(t, opts.Validate(), name)
+		}
+
+		b, _ := base.NewBase("synthetic")
+		_, err := NewGeocode(b).Forward("wellington", &ForwardRequestOpts{Limit: 20})
+		assert.NotNil(t, err)
+		_, err = NewGeocode(b).Forward(" ", nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Escapes queries", func(t *testing.T) {
+		var path, query string
+		b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+			path, query = r.URL.EscapedPath(), r.URL.RawQuery
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"type": "FeatureCollection", "features": []}`))
+		})
+		g := NewGeocode(b)
+		defer done()
+
+		for q, escaped := range map[string]string{
+			"12 Main St #4, Springfield": "12%20Main%20St%20%234%2C%20Springfield",
+			"What? Cafe":                 "What%3F%20Cafe",
+			"Unit 3/12 Smith St":         "Unit%203%2F12%20Smith%20St",
+		} {
+			_, err := g.Forward(q, &ForwardRequestOpts{Limit: 1})
+			assert.Nil(t, err, q)
+			assert.Equal(t, "/geocoding/v5/mapbox.places/"+escaped+".json", path, q)
+			assert.Equal(t, "access_token=synthetic&limit=1", query, q)
+		}
+	})
+}
+
+func TestReverseOpts(t *testing.T) {
+	t.Run("Encodes options", func(t *testing.T) {
+		opts := ReverseRequestOpts{
+			Types:       []Type{Address},
+			Limit:       3,
+			Country:     "nz",
+			Language:    []string{"en", "fr"},
+			ReverseMode: ReverseModeScore,
+			Routing:     true,
+			Worldview:   "cn",
+		}
+		assert.Nil(t, opts.Validate())
+
+		v, err := opts.values()
+		assert.Nil(t, err)
+		assert.EqualValues(t, "address", v.Get("types"))
+		assert.EqualValues(t, "3", v.Get("limit"))
+		assert.EqualValues(t, "nz", v.Get("country"))
+		assert.EqualValues(t, "en,fr", v.Get("language"))
+		assert.EqualValues(t, "score", v.Get("reverseMode"))
+		assert.EqualValues(t, "true", v.Get("routing"))
+		assert.EqualValues(t, "cn", v.Get("worldview"))
+		assert.Len(t, v, 7)
+
+		v, err = (&ReverseRequestOpts{Types: []Type{Place, Region}}).values()
+		assert.Nil(t, err)
+		assert.EqualValues(t, []string{"place,region"}, v["types"])
+	})
+
+	t.Run("Validates options", func(t *testing.T) {
+		assert.Nil(t, (&ReverseRequestOpts{Limit: 1}).Validate())
+
+		for name, opts := range map[string]ReverseRequestOpts{
+			"limit with types":    {Types: []Type{Address, POI}, Limit: 1},
+			"limit without types": {Limit: 2},
+			"limit maximum":       {Types: []Type{Address}, Limit: MaxReverseLimit + 1},
+			"reverse mode":        {ReverseMode: "nearest"},
+			"type":                {Types: []Type{"street"}},
+			"country":             {Country: "new zealand"},
+		} {
+			assert.NotNil(t, opts.Validate(), name)
+		}
+
+		b, _ := base.NewBase("synthetic")
+		_, err := NewGeocode(b).Reverse(&base.Location{Latitude: 95, Longitude: 0}, nil)
+		assert.NotNil(t, err)
+		_, err = NewGeocode(b).Reverse(nil, nil)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,180 @@
+/**
+ * go-mapbox Geocoding Module Match Quality
+ * Classifies the best match of a forward geocoding response so low quality results can be reviewed
+ * See https://www.mapbox.com/api-documentation/#geocoding for information on relevance and accuracy
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"strings"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// MatchQuality is the precision of the best match of a geocoding response
+type MatchQuality string
+
+const (
+	// QualityExact is an address at a rooftop, parcel or point, or a point of interest
+	QualityExact MatchQuality = "exact"
+	// QualityStreet is an interpolated or approximate address, or a street without a house number
+	QualityStreet MatchQuality = "street"
+	// QualityPostcode is a postcode
+	QualityPostcode MatchQuality = "postcode"
+	// QualityCity is a place, locality, neighborhood or district
+	QualityCity MatchQuality = "city"
+	// QualityAmbiguous is a low relevance match, one too close to the runner-up, or a region or country
+	QualityAmbiguous MatchQuality = "ambiguous"
+	// QualityNone indicates there were no results
+	QualityNone MatchQuality = "none"
+)
+
+const (
+	// DefaultMinRelevance is the default relevance below which a match is ambiguous
+	DefaultMinRelevance = 0.8
+	// DefaultMinRelevanceGap is the default relevance gap to the runner-up below which a match is ambiguous
+	DefaultMinRelevanceGap = 0.1
+)
+
+// QualityOpts configures match quality classification
+type QualityOpts struct {
+	MinRelevance    float64          // Relevance below which a match is ambiguous, defaults to DefaultMinRelevance
+	MinRelevanceGap float64          // Gap to the runner-up below which a match is ambiguous, defaults to DefaultMinRelevanceGap
+	Country         string           // Comma separated ISO 3166 alpha 2 country codes results are expected within
+	BBox            base.BoundingBox // Bounding box results are expected within
+}
+
+func withQualityDefaults(opts *QualityOpts) QualityOpts {
+	o := QualityOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MinRelevance == 0 {
+		o.MinRelevance = DefaultMinRelevance
+	}
+	if o.MinRelevanceGap == 0 {
+		o.MinRelevanceGap = DefaultMinRelevanceGap
+	}
+	return o
+}
+
+// QualityReport describes the best match of a geocoding response
+type QualityReport struct {
+	Quality        MatchQuality
+	Feature        *base.Feature // Best match, nil where there were no results
+	Relevance      float64
+	Gap            float64 // Relevance gap to the runner-up, equal to the relevance where there is only one result
+	OutsideCountry bool    // The match is not within the expected countries
+	OutsideBBox    bool    // The match is not within the expected bounding box
+}
+
+// NeedsReview returns true for ambiguous matches, missing matches or matches outside the expected area
+func (q *QualityReport) NeedsReview() bool {
+	return q.Quality == QualityAmbiguous || q.Quality == QualityNone || q.OutsideCountry || q.OutsideBBox
+}
+
+// Quality classifies the best match of a forward geocoding response
+func (r *ForwardResponse) Quality(opts *QualityOpts) *QualityReport {
+	if r == nil || r.FeatureCollection == nil {
+		return &QualityReport{Quality: QualityNone}
+	}
+	return AssessQuality(r.Features, opts)
+}
+
+// AssessQuality classifies the best (highest relevance) match of a set of geocoding results
+func AssessQuality(features []base.Feature, opts *QualityOpts) *QualityReport {
+	o := withQualityDefaults(opts)
+
+	if len(features) == 0 {
+		return &QualityReport{Quality: QualityNone}
+	}
+
+	best, runnerUp := 0, -1
+	for i := 1; i < len(features); i++ {
+		switch {
+		case features[i].Relevance > features[best].Relevance:
+			best, runnerUp = i, best
+		case runnerUp < 0 || features[i].Relevance > features[runnerUp].Relevance:
+			runnerUp = i
+		}
+	}
+
+	f := &features[best]
+	q := &QualityReport{Feature: f, Relevance: f.Relevance, Gap: f.Relevance}
+	if runnerUp >= 0 {
+		q.Gap = f.Relevance - features[runnerUp].Relevance
+	}
+
+	q.Quality = featureQuality(f)
+	if q.Relevance < o.MinRelevance || q.Gap < o.MinRelevanceGap {
+		q.Quality = QualityAmbiguous
+	}
+
+	q.OutsideCountry = !inCountries(f, o.Country)
+	q.OutsideBBox = !inBBox(f, o.BBox)
+
+	return q
+}
+
+// featureQuality classifies a feature by type and accuracy
+func featureQuality(f *base.Feature) MatchQuality {
+	switch Type(f.FeatureType()) {
+	case Address:
+		if f.HouseNumber == "" {
+			return QualityStreet
+		}
+		// Features without an accuracy may be interpolated, so are not trusted as exact
+		switch f.Properties.Accuracy {
+		case "rooftop", "parcel", "point":
+			return QualityExact
+		default:
+			return QualityStreet
+		}
+	case POI:
+		return QualityExact
+	case Postcode:
+		return QualityPostcode
+	case Place, Locality, Neighborhood, District:
+		return QualityCity
+	default:
+		return QualityAmbiguous
+	}
+}
+
+// inCountries checks a feature is within one of a comma separated list of countries
+// Features without a known country are not flagged
+func inCountries(f *base.Feature, countries string) bool {
+	if countries == "" {
+		return true
+	}
+	code := f.Address().CountryCode
+	if code == "" {
+		return true
+	}
+	for _, c := range strings.Split(countries, ",") {
+		if strings.EqualFold(strings.TrimSpace(c), code) {
+			return true
+		}
+	}
+	return false
+}
+
+// inBBox checks the centre of a feature is within a [minLng, minLat, maxLng, maxLat] bounding box,
+// which may cross the antimeridian (minLng > maxLng)
+func inBBox(f *base.Feature, bbox base.BoundingBox) bool {
+	if len(bbox) != 4 || len(f.Center) != 2 {
+		return true
+	}
+	lng, lat := f.Center[0], f.Center[1]
+	if lat < bbox[1] || lat > bbox[3] {
+		return false
+	}
+	if bbox[0] <= bbox[2] {
+		return lng >= bbox[0] && lng <= bbox[2]
+	}
+	return lng >= bbox[0] || lng <= bbox[2]
+}
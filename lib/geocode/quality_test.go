@@ -0,0 +1,105 @@
+/**
+ * go-mapbox Geocoding Module Match Quality Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+func TestQuality(t *testing.T) {
+	feature := func(placeType string, relevance float64) base.Feature {
+		return base.Feature{
+			ID:        placeType + ".1",
+			PlaceType: []string{placeType},
+			Relevance: relevance,
+			Center:    base.Point{-77.03, 38.90},
+			Context:   []base.Context{{ID: "country.1", Text: "United States", ShortCode: "us"}},
+		}
+	}
+	address := func(accuracy string) base.Feature {
+		f := feature("address", 1)
+		f.HouseNumber = "1600"
+		f.Properties.Accuracy = accuracy
+		return f
+	}
+
+	t.Run("Classifies by type and accuracy", func(t *testing.T) {
+		street := address("")
+		street.HouseNumber = ""
+
+		cases := []struct {
+			feature base.Feature
+			quality MatchQuality
+		}{
+			{address("rooftop"), QualityExact},
+			{address(""), QualityStreet},
+			{address("interpolated"), QualityStreet},
+			{street, QualityStreet},
+			{feature("poi", 1), QualityExact},
+			{feature("postcode", 1), QualityPostcode},
+			{feature("place", 1), QualityCity},
+			{feature("neighborhood", 1), QualityCity},
+			{feature("region", 1), QualityAmbiguous},
+		}
+		for _, c := range cases {
+			q := AssessQuality([]base.Feature{c.feature}, nil)
+			assert.Equal(t, c.quality, q.Quality, "%s (%s)", c.feature.ID, c.feature.Properties.Accuracy)
+			assert.Equal(t, 1.0, q.Gap)
+		}
+	})
+
+	t.Run("Flags low relevance and close runners-up", func(t *testing.T) {
+		q := AssessQuality([]base.Feature{feature("place", 0.5)}, nil)
+		assert.Equal(t, QualityAmbiguous, q.Quality)
+		assert.True(t, q.NeedsReview())
+
+		q = AssessQuality([]base.Feature{feature("place", 0.95), feature("poi", 0.99)}, nil)
+		assert.Equal(t, QualityAmbiguous, q.Quality)
+		assert.Equal(t, "poi.1", q.Feature.ID)
+		assert.InDelta(t, 0.04, q.Gap, 1e-9)
+
+		q = AssessQuality([]base.Feature{feature("poi", 0.99), feature("place", 0.95)}, &QualityOpts{MinRelevanceGap: 0.01})
+		assert.Equal(t, QualityExact, q.Quality)
+		assert.False(t, q.NeedsReview())
+
+		q = AssessQuality(nil, nil)
+		assert.Equal(t, QualityNone, q.Quality)
+		assert.Nil(t, q.Feature)
+		assert.True(t, q.NeedsReview())
+	})
+
+	t.Run("Flags results outside the expected area", func(t *testing.T) {
+		features := []base.Feature{address("rooftop")}
+
+		q := AssessQuality(features, &QualityOpts{Country: "ca, US", BBox: base.BoundingBox{-78, 38, -77, 39}})
+		assert.False(t, q.OutsideCountry)
+		assert.False(t, q.OutsideBBox)
+
+		q = AssessQuality(features, &QualityOpts{Country: "ca", BBox: base.BoundingBox{170, 38, -170, 39}})
+		assert.True(t, q.OutsideCountry)
+		assert.True(t, q.OutsideBBox)
+		assert.True(t, q.NeedsReview())
+		assert.Equal(t, QualityExact, q.Quality)
+
+		features[0].Center = base.Point{179, 38.5}
+		q = AssessQuality(features, &QualityOpts{BBox: base.BoundingBox{170, 38, -170, 39}})
+		assert.False(t, q.OutsideBBox)
+	})
+
+	t.Run("Classifies forward responses", func(t *testing.T) {
+		var resp *ForwardResponse
+		assert.Equal(t, QualityNone, resp.Quality(nil).Quality)
+
+		resp = &ForwardResponse{FeatureCollection: &base.FeatureCollection{Features: []base.Feature{feature("postcode", 0.9)}}}
+		assert.Equal(t, QualityPostcode, resp.Quality(nil).Quality)
+	})
+}
@@ -0,0 +1,346 @@
+/**
+ * go-mapbox Geocoding Module v6
+ * Wraps the v6 geocoding API, including structured input and batch geocoding
+ * See https://docs.mapbox.com/api/search/geocoding/ for API information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+const (
+	apiPathV6 = "search/geocode/v6"
+
+	// MaxForwardLimitV6 is the maximum number of v6 forward geocoding results
+	MaxForwardLimitV6 = 10
+	// MaxReverseLimitV6 is the maximum number of v6 reverse geocoding results
+	MaxReverseLimitV6 = 5
+	// MaxBatchQueries is the maximum number of queries in a v6 batch request
+	MaxBatchQueries = 1000
+)
+
+// Additional location types supported by the v6 API
+const (
+	// Street level
+	Street Type = "street"
+	// Block level (Japan only)
+	Block Type = "block"
+	// SecondaryAddress level, eg. units and apartments
+	SecondaryAddress Type = "secondary_address"
+)
+
+// StructuredInput is a forward geocoding query split into address components
+type StructuredInput struct {
+	AddressLine1  string `url:"address_line1,omitempty" json:"address_line1,omitempty"`
+	AddressNumber string `url:"address_number,omitempty" json:"address_number,omitempty"`
+	Street        string `url:"street,omitempty" json:"street,omitempty"`
+	Block         string `url:"block,omitempty" json:"block,omitempty"`
+	Place         string `url:"place,omitempty" json:"place,omitempty"`
+	Region        string `url:"region,omitempty" json:"region,omitempty"`
+	Postcode      string `url:"postcode,omitempty" json:"postcode,omitempty"`
+	Locality      string `url:"locality,omitempty" json:"locality,omitempty"`
+	Neighborhood  string `url:"neighborhood,omitempty" json:"neighborhood,omitempty"`
+	Country       string `url:"country,omitempty" json:"country,omitempty"`
+}
+
+// empty checks whether no components are set
+func (s *StructuredInput) empty() bool {
+	return *s == StructuredInput{}
+}
+
+// ForwardV6Opts request options for v6 forward geocoding
+// The v6 API accepts a single language
+type ForwardV6Opts struct {
+	base.Filter
+	Permanent    bool   `url:"permanent,omitempty"` // Results will be stored, billed as permanent geocoding
+	Autocomplete *bool  `url:"autocomplete,omitempty"`
+	Limit        uint   `url:"limit,omitempty"`
+	Types        []Type `url:"types,comma,omitempty"`
+	Worldview    string `url:"worldview,omitempty"`
+}
+
+// Validate checks v6 forward geocoding options before a request is made
+func (o *ForwardV6Opts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := o.Filter.Validate(); err != nil {
+		return err
+	}
+	if err := o.Filter.ValidateSingleLanguage(); err != nil {
+		return err
+	}
+	if err := validateTypes(o.Types, typesV6); err != nil {
+		return err
+	}
+	if o.Limit > MaxForwardLimitV6 {
+		return fmt.Errorf("Limit (%d) exceeds maximum of %d", o.Limit, MaxForwardLimitV6)
+	}
+	return base.ValidateWorldview(o.Worldview)
+}
+
+// values encodes v6 forward geocoding options as query parameters
+func (o *ForwardV6Opts) values() (url.Values, error) {
+	if o == nil {
+		return url.Values{}, nil
+	}
+	return o.Filter.Values(o)
+}
+
+// ReverseV6Opts request options for v6 reverse geocoding
+type ReverseV6Opts struct {
+	Permanent bool   `url:"permanent,omitempty"` // Results will be stored, billed as permanent geocoding
+	Country   string `url:"country,omitempty"`
+	Language  string `url:"language,omitempty"`
+	Limit     uint   `url:"limit,omitempty"`
+	Types     []Type `url:"types,comma,omitempty"`
+	Worldview string `url:"worldview,omitempty"`
+}
+
+// Validate checks v6 reverse geocoding options before a request is made
+func (o *ReverseV6Opts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := validateTypes(o.Types, typesV6); err != nil {
+		return err
+	}
+	if o.Limit > MaxReverseLimitV6 {
+		return fmt.Errorf("Limit (%d) exceeds maximum of %d", o.Limit, MaxReverseLimitV6)
+	}
+	if o.Limit > 1 && len(o.Types) != 1 {
+		return fmt.Errorf("Limit greater than 1 requires a single type (received %d types)", len(o.Types))
+	}
+	if err := base.ValidateCountry(o.Country); err != nil {
+		return err
+	}
+	if o.Language != "" {
+		if err := base.ValidateLanguages([]string{o.Language}); err != nil {
+			return err
+		}
+	}
+	return base.ValidateWorldview(o.Worldview)
+}
+
+// ForwardV6 geocode lookup
+// Finds locations from a search string
+func (g *Geocode) ForwardV6(search string, req *ForwardV6Opts) (*FeatureCollectionV6, error) {
+	if strings.TrimSpace(search) == "" {
+		return nil, fmt.Errorf("Forward geocoding requires a search query")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	v, err := req.values()
+	if err != nil {
+		return nil, err
+	}
+	v.Set("q", search)
+
+	resp := FeatureCollectionV6{}
+	err = g.base.QueryBase(fmt.Sprintf("%s/forward", apiPathV6), &v, &resp)
+
+	return &resp, err
+}
+
+// ForwardStructured geocode lookup
+// Finds locations from address components, results include match codes describing which components matched
+func (g *Geocode) ForwardStructured(input *StructuredInput, req *ForwardV6Opts) (*FeatureCollectionV6, error) {
+	if input == nil || input.empty() {
+		return nil, fmt.Errorf("Structured geocoding requires at least one address component")
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req != nil && req.Country != "" && input.Country != "" && !strings.EqualFold(req.Country, input.Country) {
+		return nil, fmt.Errorf("Structured country (%s) conflicts with country filter (%s)", input.Country, req.Country)
+	}
+
+	v, err := req.values()
+	if err != nil {
+		return nil, err
+	}
+	components, err := query.Values(input)
+	if err != nil {
+		return nil, err
+	}
+	for k := range components {
+		v.Set(k, components.Get(k))
+	}
+
+	resp := FeatureCollectionV6{}
+	err = g.base.QueryBase(fmt.Sprintf("%s/forward", apiPathV6), &v, &resp)
+
+	return &resp, err
+}
+
+// ReverseV6 geocode lookup
+// Finds places from a location
+func (g *Geocode) ReverseV6(loc *base.Location, req *ReverseV6Opts) (*FeatureCollectionV6, error) {
+	if loc == nil {
+		return nil, fmt.Errorf("Reverse geocoding requires a location")
+	}
+	if err := base.ValidateLocation("Location", []float64{loc.Longitude, loc.Latitude}); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if req != nil {
+		var err error
+		if v, err = query.Values(req); err != nil {
+			return nil, err
+		}
+	}
+	v.Set("longitude", fmt.Sprint(loc.Longitude))
+	v.Set("latitude", fmt.Sprint(loc.Latitude))
+
+	resp := FeatureCollectionV6{}
+	err := g.base.QueryBase(fmt.Sprintf("%s/reverse", apiPathV6), &v, &resp)
+
+	return &resp, err
+}
+
+// BatchQuery is a single forward, structured or reverse query in a batch request
+// Exactly one of Q, the structured input components or the location must be set
+// Country filters results for search string and location queries, and is an address component otherwise
+type BatchQuery struct {
+	Q string `json:"q,omitempty"`
+	StructuredInput
+	Longitude *float64 `json:"longitude,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+
+	Types        []Type           `json:"types,omitempty"`
+	Limit        uint             `json:"limit,omitempty"`
+	Autocomplete *bool            `json:"autocomplete,omitempty"`
+	BBox         base.BoundingBox `json:"bbox,omitempty"`
+	Proximity    []float64        `json:"proximity,omitempty"`
+	Language     string           `json:"language,omitempty"`
+	Worldview    string           `json:"worldview,omitempty"`
+}
+
+// NewForwardBatchQuery creates a forward batch query from a search string
+func NewForwardBatchQuery(search string) BatchQuery {
+	return BatchQuery{Q: search}
+}
+
+// NewStructuredBatchQuery creates a structured batch query from address components
+func NewStructuredBatchQuery(input StructuredInput) BatchQuery {
+	return BatchQuery{StructuredInput: input}
+}
+
+// NewReverseBatchQuery creates a reverse batch query from a location
+func NewReverseBatchQuery(loc base.Location) BatchQuery {
+	return BatchQuery{Longitude: &loc.Longitude, Latitude: &loc.Latitude}
+}
+
+// Validate checks a batch query before a request is made
+func (q *BatchQuery) Validate() error {
+	structured := q.StructuredInput
+	structured.Country = ""
+
+	modes, reverse := 0, false
+	if q.Q != "" {
+		modes++
+	}
+	if !structured.empty() {
+		modes++
+	}
+	if q.Longitude != nil || q.Latitude != nil {
+		if q.Longitude == nil || q.Latitude == nil {
+			return fmt.Errorf("Reverse queries require both a longitude and latitude")
+		}
+		if err := base.ValidateLocation("Location", []float64{*q.Longitude, *q.Latitude}); err != nil {
+			return err
+		}
+		modes++
+		reverse = true
+	}
+	if modes == 0 && q.Country != "" {
+		// A country alone is a structured query
+		modes++
+	}
+	if modes != 1 {
+		return fmt.Errorf("Batch queries require exactly one of a search string, address components or a location")
+	}
+
+	if err := validateTypes(q.Types, typesV6); err != nil {
+		return err
+	}
+	if err := base.ValidateBBox(q.BBox); err != nil {
+		return err
+	}
+	if err := base.ValidateLocation("Proximity", q.Proximity); err != nil {
+		return err
+	}
+	// Reverse queries follow the reverse endpoint limits, see ReverseV6Opts
+	if reverse {
+		if q.Limit > MaxReverseLimitV6 {
+			return fmt.Errorf("Limit (%d) exceeds maximum of %d", q.Limit, MaxReverseLimitV6)
+		}
+		if q.Limit > 1 && len(q.Types) != 1 {
+			return fmt.Errorf("Limit greater than 1 requires a single type (received %d types)", len(q.Types))
+		}
+	} else if q.Limit > MaxForwardLimitV6 {
+		return fmt.Errorf("Limit (%d) exceeds maximum of %d", q.Limit, MaxForwardLimitV6)
+	}
+	if q.Language != "" {
+		if err := base.ValidateLanguages([]string{q.Language}); err != nil {
+			return err
+		}
+	}
+	return base.ValidateWorldview(q.Worldview)
+}
+
+// BatchV6 geocodes up to MaxBatchQueries forward, structured and reverse queries in a single request
+// Results are returned in the same order as the queries
+func (g *Geocode) BatchV6(queries []BatchQuery, permanent bool) (*BatchResponseV6, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("Batch geocoding requires at least one query")
+	}
+	if len(queries) > MaxBatchQueries {
+		return nil, fmt.Errorf("Batch geocoding supports at most %d queries (received %d)", MaxBatchQueries, len(queries))
+	}
+	for i := range queries {
+		if err := queries[i].Validate(); err != nil {
+			return nil, fmt.Errorf("Batch query %d: %s", i, err)
+		}
+	}
+
+	data, err := json.Marshal(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if permanent {
+		v.Set("permanent", "true")
+	}
+
+	resp := BatchResponseV6{}
+	err = g.base.QueryBody(http.MethodPost, fmt.Sprintf("%s/batch", apiPathV6), &v, bytes.NewReader(data), &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Batch) != len(queries) {
+		return nil, fmt.Errorf("Batch response length mismatch (expected %d received %d)", len(queries), len(resp.Batch))
+	}
+
+	return &resp, nil
+}
@@ -0,0 +1,217 @@
+/**
+ * go-mapbox Geocoding Module v6 Tests
+ * Uses a local test server so no API token is required
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+)
+
+const featureV6JSON = `{
+  "type": "Feature",
+  "id": "dXJuOm1ieGFkcjo",
+  "geometry": {"type": "Point", "coordinates": [174.776217, -41.286461]},
+  "properties": {
+    "mapbox_id": "dXJuOm1ieGFkcjo",
+    "feature_type": "address",
+    "full_address": "1 Lambton Quay, Pipitea, Wellington 6011, New Zealand",
+    "name": "1 Lambton Quay",
+    "place_formatted": "Pipitea, Wellington 6011, New Zealand",
+    "coordinates": {
+      "longitude": 174.776217,
+      "latitude": -41.286461,
+      "accuracy": "rooftop",
+      "routable_points": [{"name": "default", "longitude": 174.7762, "latitude": -41.2864}]
+    },
+    "context": {
+      "address": {"mapbox_id": "a", "address_number": "1", "street_name": "Lambton Quay", "name": "1 Lambton Quay"},
+      "street": {"mapbox_id": "s", "name": "Lambton Quay"},
+      "neighborhood": {"mapbox_id": "n", "name": "Pipitea"},
+      "postcode": {"mapbox_id": "p", "name": "6011"},
+      "place": {"mapbox_id": "pl", "name": "Wellington", "wikidata_id": "Q23661"},
+      "region": {"mapbox_id": "r", "name": "Wellington", "region_code": "WGN", "region_code_full": "NZ-WGN"},
+      "country": {"mapbox_id": "c", "name": "New Zealand", "country_code": "NZ", "country_code_alpha_3": "NZL"}
+    },
+    "match_code": {
+      "address_number": "matched", "street": "matched", "postcode": "unmatched", "place": "matched",
+      "region": "inferred", "locality": "not_applicable", "country": "inferred", "confidence": "medium"
+    }
+  }
+}`
+
+func TestGeocodeV6(t *testing.T) {
+	var last *http.Request
+	var lastBody []byte
+	b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+		last = r
+		lastBody, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/search/geocode/v6/batch":
+			queries := []BatchQuery{}
+			if err := json.Unmarshal(lastBody, &queries); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"message": "Invalid body"}`))
+				return
+			}
+			w.Write([]byte(`{"batch": [`))
+			for i := range queries {
+				if i > 0 {
+					w.Write([]byte(","))
+				}
+				w.Write([]byte(`{"type": "FeatureCollection", "features": [` + featureV6JSON + `]}`))
+			}
+			w.Write([]byte(`]}`))
+		case "/search/geocode/v6/forward", "/search/geocode/v6/reverse":
+			if r.URL.Query().Get("worldview") == "xx" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				w.Write([]byte(`{"message": "Worldview not supported"}`))
+				return
+			}
+			w.Write([]byte(`{"type": "FeatureCollection", "features": [` + featureV6JSON + `], "attribution": "Mapbox"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	g := NewGeocode(b)
+	defer done()
+
+	t.Run("Forward geocodes", func(t *testing.T) {
+		res, err := g.ForwardV6("1 lambton quay", &ForwardV6Opts{Filter: base.Filter{Country: "nz", ProximityIP: true}, Permanent: true, Types: []Type{Address, Street}, Autocomplete: base.Bool(false)})
+		assert.Nil(t, err)
+
+		q := last.URL.Query()
+		assert.EqualValues(t, "1 lambton quay", q.Get("q"))
+		assert.EqualValues(t, "true", q.Get("permanent"))
+		assert.EqualValues(t, "address,street", q.Get("types"))
+		assert.EqualValues(t, "ip", q.Get("proximity"))
+		assert.EqualValues(t, "false", q.Get("autocomplete"))
+		assert.EqualValues(t, "synthetic", q.Get("access_token"))
+
+		assert.Len(t, res.Features, 1)
+		f := res.Features[0]
+		assert.EqualValues(t, Address, f.Properties.FeatureType)
+		assert.EqualValues(t, base.Location{Latitude: -41.286461, Longitude: 174.776217}, f.Location())
+		assert.EqualValues(t, "1", f.Properties.Context.Address.AddressNumber)
+		assert.EqualValues(t, "NZ-WGN", f.Properties.Context.Region.RegionCodeFull)
+		assert.EqualValues(t, "NZL", f.Properties.Context.Country.CountryCodeAlpha3)
+		assert.EqualValues(t, "Q23661", f.Properties.Context.Place.WikidataID)
+		assert.Nil(t, f.Properties.Context.Locality)
+		assert.EqualValues(t, MatchUnmatched, f.Properties.MatchCode.Postcode)
+		assert.EqualValues(t, ConfidenceMedium, f.Properties.MatchCode.Confidence)
+		assert.EqualValues(t, "rooftop", f.Properties.Coordinates.Accuracy)
+	})
+
+	t.Run("Forward geocodes structured input", func(t *testing.T) {
+		_, err := g.ForwardStructured(&StructuredInput{AddressNumber: "1", Street: "Lambton Quay", Place: "Wellington", Country: "nz"}, &ForwardV6Opts{Limit: 1})
+		assert.Nil(t, err)
+
+		q := last.URL.Query()
+		assert.EqualValues(t, "1", q.Get("address_number"))
+		assert.EqualValues(t, "Lambton Quay", q.Get("street"))
+		assert.EqualValues(t, "Wellington", q.Get("place"))
+		assert.EqualValues(t, []string{"nz"}, q["country"])
+		assert.EqualValues(t, "", q.Get("q"))
+
+		_, err = g.ForwardStructured(&StructuredInput{}, nil)
+		assert.NotNil(t, err)
+		_, err = g.ForwardStructured(&StructuredInput{Place: "Wellington", Country: "nz"}, &ForwardV6Opts{Filter: base.Filter{Country: "au"}})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Reverse geocodes", func(t *testing.T) {
+		res, err := g.ReverseV6(&base.Location{Latitude: -41.286461, Longitude: 174.776217}, &ReverseV6Opts{Types: []Type{Address}, Limit: 2})
+		assert.Nil(t, err)
+		assert.Len(t, res.Features, 1)
+
+		q := last.URL.Query()
+		assert.EqualValues(t, "/search/geocode/v6/reverse", last.URL.Path)
+		assert.EqualValues(t, "174.776217", q.Get("longitude"))
+		assert.EqualValues(t, "-41.286461", q.Get("latitude"))
+		assert.EqualValues(t, "2", q.Get("limit"))
+
+		_, err = g.ReverseV6(&base.Location{Latitude: -41, Longitude: 174}, &ReverseV6Opts{Limit: 2})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Batch geocodes", func(t *testing.T) {
+		queries := []BatchQuery{
+			NewForwardBatchQuery("1 lambton quay"),
+			NewStructuredBatchQuery(StructuredInput{AddressNumber: "1", Street: "Lambton Quay", Country: "nz"}),
+			NewReverseBatchQuery(base.Location{Latitude: -41.286461, Longitude: 174.776217}),
+		}
+		queries[0].Types = []Type{Address}
+
+		res, err := g.BatchV6(queries, true)
+		assert.Nil(t, err)
+		assert.Len(t, res.Batch, 3)
+		assert.EqualValues(t, http.MethodPost, last.Method)
+		assert.EqualValues(t, "true", last.URL.Query().Get("permanent"))
+
+		sent := []map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(lastBody, &sent))
+		assert.EqualValues(t, map[string]interface{}{"q": "1 lambton quay", "types": []interface{}{"address"}}, sent[0])
+		assert.EqualValues(t, map[string]interface{}{"address_number": "1", "street": "Lambton Quay", "country": "nz"}, sent[1])
+		assert.EqualValues(t, map[string]interface{}{"longitude": 174.776217, "latitude": -41.286461}, sent[2])
+	})
+
+	t.Run("Validates batch queries", func(t *testing.T) {
+		_, err := g.BatchV6(nil, false)
+		assert.NotNil(t, err)
+		_, err = g.BatchV6(make([]BatchQuery, MaxBatchQueries+1), false)
+		assert.NotNil(t, err)
+
+		lat := 10.0
+		reverse := func(limit uint, types ...Type) BatchQuery {
+			q := NewReverseBatchQuery(base.Location{Latitude: -41.29, Longitude: 174.78})
+			q.Limit, q.Types = limit, types
+			return q
+		}
+		for name, q := range map[string]BatchQuery{
+			"empty":               {},
+			"multiple modes":      {Q: "wellington", StructuredInput: StructuredInput{Place: "Wellington"}},
+			"partial location":    {Latitude: &lat},
+			"type":                {Q: "wellington", Types: []Type{POI}},
+			"forward limit":       {Q: "wellington", Limit: MaxForwardLimitV6 + 1},
+			"reverse limit":       reverse(MaxReverseLimitV6+1, Address),
+			"reverse limit types": reverse(2),
+		} {
+			assert.NotNil(t, q.Validate(), name)
+		}
+		assert.Nil(t, (&BatchQuery{StructuredInput: StructuredInput{Country: "nz"}}).Validate())
+		assert.Nil(t, (&BatchQuery{Q: "wellington", Limit: MaxForwardLimitV6}).Validate())
+		for _, q := range []BatchQuery{reverse(1), reverse(MaxReverseLimitV6, Address)} {
+			assert.Nil(t, q.Validate())
+		}
+	})
+
+	t.Run("Reports API errors", func(t *testing.T) {
+		_, err := g.ForwardV6("wellington", &ForwardV6Opts{Worldview: "xx"})
+		assert.EqualError(t, err, "api error (422): Worldview not supported")
+	})
+}
+
+func TestForwardV6Opts(t *testing.T) {
+	v, err := (&ForwardV6Opts{Filter: base.Filter{BBox: base.BoundingBox{174, -42, 175, -41}, Proximity: []float64{174.7, -41.3}, Language: []string{"en"}}}).values()
+	assert.Nil(t, err)
+	assert.EqualValues(t, url.Values{"bbox": {"174,-42,175,-41"}, "proximity": {"174.7,-41.3"}, "language": {"en"}}, v)
+
+	assert.NotNil(t, (&ForwardV6Opts{Limit: MaxForwardLimitV6 + 1}).Validate())
+	assert.NotNil(t, (&ForwardV6Opts{Filter: base.Filter{Language: []string{"en", "fr"}}}).Validate())
+	assert.NotNil(t, (&ForwardV6Opts{Types: []Type{POI}}).Validate())
+}
@@ -0,0 +1,151 @@
+/**
+ * go-mapbox Geocoding Module v6 Types
+ * Response types for the v6 geocoding API
+ * See https://docs.mapbox.com/api/search/geocoding/ for API information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// MatchStatus describes how a component of a structured query matched a result
+type MatchStatus string
+
+const (
+	// MatchMatched indicates the component matched the result
+	MatchMatched MatchStatus = "matched"
+	// MatchUnmatched indicates the component did not match the result
+	MatchUnmatched MatchStatus = "unmatched"
+	// MatchNotApplicable indicates the component was not provided or does not apply
+	MatchNotApplicable MatchStatus = "not_applicable"
+	// MatchInferred indicates the component was not provided and was inferred
+	MatchInferred MatchStatus = "inferred"
+	// MatchPlausible indicates the component could not be verified but is plausible
+	MatchPlausible MatchStatus = "plausible"
+)
+
+// MatchConfidence is the overall confidence of a result match
+type MatchConfidence string
+
+const (
+	// ConfidenceExact indicates all provided components matched
+	ConfidenceExact MatchConfidence = "exact"
+	// ConfidenceHigh indicates a match with minor differences
+	ConfidenceHigh MatchConfidence = "high"
+	// ConfidenceMedium indicates a partial match
+	ConfidenceMedium MatchConfidence = "medium"
+	// ConfidenceLow indicates a poor match
+	ConfidenceLow MatchConfidence = "low"
+)
+
+// MatchCode describes how an address result matched the query
+type MatchCode struct {
+	AddressNumber MatchStatus     `json:"address_number"`
+	Street        MatchStatus     `json:"street"`
+	Postcode      MatchStatus     `json:"postcode"`
+	Place         MatchStatus     `json:"place"`
+	Region        MatchStatus     `json:"region"`
+	Locality      MatchStatus     `json:"locality"`
+	Country       MatchStatus     `json:"country"`
+	Confidence    MatchConfidence `json:"confidence"`
+}
+
+// ContextEntry is a feature in the hierarchy containing a result
+type ContextEntry struct {
+	MapboxID   string `json:"mapbox_id"`
+	Name       string `json:"name"`
+	WikidataID string `json:"wikidata_id,omitempty"`
+}
+
+// AddressContext is the address containing a result
+type AddressContext struct {
+	ContextEntry
+	AddressNumber string `json:"address_number"`
+	StreetName    string `json:"street_name"`
+}
+
+// RegionContext is the region containing a result
+type RegionContext struct {
+	ContextEntry
+	RegionCode     string `json:"region_code"`
+	RegionCodeFull string `json:"region_code_full"`
+}
+
+// CountryContext is the country containing a result
+type CountryContext struct {
+	ContextEntry
+	CountryCode       string `json:"country_code"`
+	CountryCodeAlpha3 string `json:"country_code_alpha_3"`
+}
+
+// ContextV6 is the hierarchy of features containing a result
+type ContextV6 struct {
+	Address      *AddressContext `json:"address,omitempty"`
+	Street       *ContextEntry   `json:"street,omitempty"`
+	Neighborhood *ContextEntry   `json:"neighborhood,omitempty"`
+	Postcode     *ContextEntry   `json:"postcode,omitempty"`
+	Locality     *ContextEntry   `json:"locality,omitempty"`
+	Place        *ContextEntry   `json:"place,omitempty"`
+	District     *ContextEntry   `json:"district,omitempty"`
+	Region       *RegionContext  `json:"region,omitempty"`
+	Country      *CountryContext `json:"country,omitempty"`
+}
+
+// RoutablePoint is a location suitable for navigating to a result
+type RoutablePoint struct {
+	Name      string  `json:"name"`
+	Longitude float64 `json:"longitude"`
+	Latitude  float64 `json:"latitude"`
+}
+
+// CoordinatesV6 is the location of a result
+type CoordinatesV6 struct {
+	Longitude      float64         `json:"longitude"`
+	Latitude       float64         `json:"latitude"`
+	Accuracy       string          `json:"accuracy,omitempty"`
+	RoutablePoints []RoutablePoint `json:"routable_points,omitempty"`
+}
+
+// PropertiesV6 are the properties of a v6 feature
+type PropertiesV6 struct {
+	MapboxID       string           `json:"mapbox_id"`
+	FeatureType    Type             `json:"feature_type"`
+	Name           string           `json:"name"`
+	NamePreferred  string           `json:"name_preferred"`
+	PlaceFormatted string           `json:"place_formatted"`
+	FullAddress    string           `json:"full_address"`
+	Coordinates    CoordinatesV6    `json:"coordinates"`
+	Context        ContextV6        `json:"context"`
+	BBox           base.BoundingBox `json:"bbox,omitempty"`
+	MatchCode      *MatchCode       `json:"match_code,omitempty"`
+}
+
+// FeatureV6 is a v6 geocoding result
+type FeatureV6 struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	Geometry   base.Geometry `json:"geometry"`
+	Properties PropertiesV6  `json:"properties"`
+}
+
+// Location returns the location of the feature
+func (f *FeatureV6) Location() base.Location {
+	return base.Location{Latitude: f.Properties.Coordinates.Latitude, Longitude: f.Properties.Coordinates.Longitude}
+}
+
+// FeatureCollectionV6 is the response to a v6 forward or reverse geocoding request
+type FeatureCollectionV6 struct {
+	Type        string      `json:"type"`
+	Features    []FeatureV6 `json:"features"`
+	Attribution string      `json:"attribution"`
+}
+
+// BatchResponseV6 is the response to a v6 batch geocoding request, with one collection per query
+type BatchResponseV6 struct {
+	Batch []FeatureCollectionV6 `json:"batch"`
+}
@@ -0,0 +1,36 @@
+/**
+ * go-mapbox Geocoding Module Validation
+ * Validates geocoding specific request options, shared options are validated by the base module
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geocode
+
+import (
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// types is the set of location types supported by the v5 API
+var types = map[string]bool{
+	string(Country): true, string(Region): true, string(Postcode): true, string(District): true, string(Place): true,
+	string(Locality): true, string(Neighborhood): true, string(Address): true, string(POI): true,
+}
+
+// typesV6 is the set of location types supported by the v6 API
+var typesV6 = map[string]bool{
+	string(Country): true, string(Region): true, string(Postcode): true, string(District): true, string(Place): true,
+	string(Locality): true, string(Neighborhood): true, string(Street): true, string(Block): true, string(Address): true,
+	string(SecondaryAddress): true,
+}
+
+// validateTypes checks each type is in the supported set
+func validateTypes(t []Type, supported map[string]bool) error {
+	for _, v := range t {
+		if err := base.ValidateType(string(v), supported); err != nil {
+			return err
+		}
+	}
+	return nil
+}
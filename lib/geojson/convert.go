@@ -0,0 +1,105 @@
+/**
+ * go-mapbox GeoJSON Module Conversions
+ * Converts geocoding, directions and map matching results to GeoJSON features
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geojson
+
+import (
+	"fmt"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/directions"
+	"github.com/tumasgiu/go-mapbox/lib/map_matching"
+)
+
+// FromBaseFeature converts a geocoding feature to a point feature at its geometry
+// The place name is stored in the simplestyle "title" property
+func FromBaseFeature(f *base.Feature) *Feature {
+	loc := base.Location{}
+	if len(f.Geometry.Coordinates) >= 2 {
+		loc = Location(f.Geometry.Coordinates)
+	} else if len(f.Center) >= 2 {
+		loc = Location(f.Center)
+	}
+
+	feature := NewFeature(NewPointGeometry(loc))
+	feature.ID = f.ID
+	feature.BBox = f.BBox
+	feature.Properties["title"] = f.PlaceName
+	feature.Properties["text"] = f.Text
+	feature.Properties["place_type"] = f.PlaceType
+	feature.Properties["relevance"] = f.Relevance
+
+	return feature
+}
+
+// FromBaseFeatureCollection converts a geocoding response to a feature collection of points
+func FromBaseFeatureCollection(fc *base.FeatureCollection) *FeatureCollection {
+	out := NewFeatureCollection()
+	for i := range fc.Features {
+		out.AddFeature(FromBaseFeature(&fc.Features[i]))
+	}
+	return out
+}
+
+// FromRoute converts a directions route to a line string feature
+// The precision must match the requested geometry type (base.PolylinePrecision or base.Polyline6Precision)
+func FromRoute(route *directions.Route, precision uint) (*Feature, error) {
+	line, err := base.DecodePolyline(route.Geometry, precision)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("Route contains no geometry (request overview)")
+	}
+
+	feature := NewFeature(NewLineStringGeometry(line))
+	feature.Properties["distance"] = route.Distance
+	feature.Properties["duration"] = route.Duration
+
+	return feature, nil
+}
+
+// FromDirections converts a directions response to a feature collection of route lines and waypoint points
+func FromDirections(res *directions.DirectionResponse, precision uint) (*FeatureCollection, error) {
+	out := NewFeatureCollection()
+
+	for i := range res.Routes {
+		f, err := FromRoute(&res.Routes[i], precision)
+		if err != nil {
+			return nil, err
+		}
+		out.AddFeature(f)
+	}
+
+	for _, w := range res.Waypoints {
+		f := NewFeature(NewPointGeometry(Location(w.Location)))
+		f.Properties["title"] = w.Name
+		out.AddFeature(f)
+	}
+
+	return out, nil
+}
+
+// FromMatching converts a map matching result to a line string feature
+// The precision is used for polyline geometries (base.PolylinePrecision or base.Polyline6Precision)
+func FromMatching(matching *mapmatching.Matchings, precision uint) (*Feature, error) {
+	line, err := matching.GetLocations(precision)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("Matching contains no geometry (request overview)")
+	}
+
+	feature := NewFeature(NewLineStringGeometry(line))
+	feature.Properties["distance"] = matching.Distance
+	feature.Properties["duration"] = matching.Duration
+	feature.Properties["confidence"] = matching.Confidence
+
+	return feature, nil
+}
@@ -0,0 +1,199 @@
+/**
+ * go-mapbox GeoJSON Module
+ * Generic GeoJSON geometries, features and feature collections
+ * See https://tools.ietf.org/html/rfc7946 for format information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// GeometryType is the type of a GeoJSON geometry
+type GeometryType string
+
+// Geometry types
+const (
+	GeometryPoint              GeometryType = "Point"
+	GeometryMultiPoint         GeometryType = "MultiPoint"
+	GeometryLineString         GeometryType = "LineString"
+	GeometryMultiLineString    GeometryType = "MultiLineString"
+	GeometryPolygon            GeometryType = "Polygon"
+	GeometryMultiPolygon       GeometryType = "MultiPolygon"
+	GeometryGeometryCollection GeometryType = "GeometryCollection"
+)
+
+// Geometry is a GeoJSON geometry, only the coordinate field matching the geometry type is populated
+// Positions are [longitude, latitude] pairs as per the GeoJSON specification
+type Geometry struct {
+	Type            GeometryType
+	Point           []float64
+	MultiPoint      [][]float64
+	LineString      [][]float64
+	MultiLineString [][][]float64
+	Polygon         [][][]float64
+	MultiPolygon    [][][][]float64
+	Geometries      []*Geometry
+}
+
+// NewPointGeometry creates a point geometry from a location
+func NewPointGeometry(loc base.Location) *Geometry {
+	return &Geometry{Type: GeometryPoint, Point: position(loc)}
+}
+
+// NewLineStringGeometry creates a line string geometry from a list of locations
+func NewLineStringGeometry(locs []base.Location) *Geometry {
+	return &Geometry{Type: GeometryLineString, LineString: positions(locs)}
+}
+
+// NewPolygonGeometry creates a polygon geometry from an outer ring and optional holes
+func NewPolygonGeometry(rings [][]base.Location) *Geometry {
+	polygon := make([][][]float64, len(rings))
+	for i, r := range rings {
+		polygon[i] = positions(r)
+	}
+	return &Geometry{Type: GeometryPolygon, Polygon: polygon}
+}
+
+// Location converts a GeoJSON position to a location
+func Location(p []float64) base.Location {
+	if len(p) < 2 {
+		return base.Location{}
+	}
+	return base.Location{Latitude: p[1], Longitude: p[0]}
+}
+
+// Locations converts a list of GeoJSON positions to locations
+func Locations(ps [][]float64) []base.Location {
+	locs := make([]base.Location, len(ps))
+	for i, p := range ps {
+		locs[i] = Location(p)
+	}
+	return locs
+}
+
+func position(loc base.Location) []float64 {
+	return []float64{loc.Longitude, loc.Latitude}
+}
+
+func positions(locs []base.Location) [][]float64 {
+	ps := make([][]float64, len(locs))
+	for i, l := range locs {
+		ps[i] = position(l)
+	}
+	return ps
+}
+
+type jsonGeometry struct {
+	Type        GeometryType `json:"type"`
+	Coordinates interface{}  `json:"coordinates,omitempty"`
+	Geometries  []*Geometry  `json:"geometries,omitempty"`
+}
+
+// MarshalJSON encodes the geometry as GeoJSON
+func (g Geometry) MarshalJSON() ([]byte, error) {
+	j := jsonGeometry{Type: g.Type}
+
+	switch g.Type {
+	case GeometryPoint:
+		j.Coordinates = g.Point
+	case GeometryMultiPoint:
+		j.Coordinates = g.MultiPoint
+	case GeometryLineString:
+		j.Coordinates = g.LineString
+	case GeometryMultiLineString:
+		j.Coordinates = g.MultiLineString
+	case GeometryPolygon:
+		j.Coordinates = g.Polygon
+	case GeometryMultiPolygon:
+		j.Coordinates = g.MultiPolygon
+	case GeometryGeometryCollection:
+		j.Geometries = g.Geometries
+	default:
+		return nil, fmt.Errorf("Unsupported geometry type (%s)", g.Type)
+	}
+
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a GeoJSON geometry
+func (g *Geometry) UnmarshalJSON(data []byte) error {
+	j := struct {
+		Type        GeometryType    `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+		Geometries  []*Geometry     `json:"geometries"`
+	}{}
+
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+
+	*g = Geometry{Type: j.Type}
+
+	var err error
+	switch j.Type {
+	case GeometryPoint:
+		err = json.Unmarshal(j.Coordinates, &g.Point)
+	case GeometryMultiPoint:
+		err = json.Unmarshal(j.Coordinates, &g.MultiPoint)
+	case GeometryLineString:
+		err = json.Unmarshal(j.Coordinates, &g.LineString)
+	case GeometryMultiLineString:
+		err = json.Unmarshal(j.Coordinates, &g.MultiLineString)
+	case GeometryPolygon:
+		err = json.Unmarshal(j.Coordinates, &g.Polygon)
+	case GeometryMultiPolygon:
+		err = json.Unmarshal(j.Coordinates, &g.MultiPolygon)
+	case GeometryGeometryCollection:
+		g.Geometries = j.Geometries
+	default:
+		return fmt.Errorf("Unsupported geometry type (%s)", j.Type)
+	}
+
+	return err
+}
+
+// Feature is a GeoJSON feature
+type Feature struct {
+	ID         interface{}            `json:"id,omitempty"`
+	Type       string                 `json:"type"`
+	BBox       base.BoundingBox       `json:"bbox,omitempty"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// NewFeature creates a feature with the provided geometry
+func NewFeature(g *Geometry) *Feature {
+	return &Feature{
+		Type:       "Feature",
+		Geometry:   g,
+		Properties: make(map[string]interface{}),
+	}
+}
+
+// FeatureCollection is a GeoJSON feature collection
+type FeatureCollection struct {
+	Type     string           `json:"type"`
+	BBox     base.BoundingBox `json:"bbox,omitempty"`
+	Features []*Feature       `json:"features"`
+}
+
+// NewFeatureCollection creates an empty feature collection
+func NewFeatureCollection() *FeatureCollection {
+	return &FeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]*Feature, 0),
+	}
+}
+
+// AddFeature appends a feature to the collection
+func (fc *FeatureCollection) AddFeature(f *Feature) {
+	fc.Features = append(fc.Features, f)
+}
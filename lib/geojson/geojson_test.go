@@ -0,0 +1,82 @@
+/**
+ * go-mapbox GeoJSON Module Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package geojson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/directions"
+	"github.com/tumasgiu/go-mapbox/lib/map_matching"
+)
+
+func TestGeoJSON(t *testing.T) {
+
+	t.Run("Round trips feature collections", func(t *testing.T) {
+		fc := NewFeatureCollection()
+
+		line := NewFeature(NewLineStringGeometry([]base.Location{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}))
+		line.Properties["name"] = "line"
+		fc.AddFeature(line)
+		fc.AddFeature(NewFeature(NewPointGeometry(base.Location{Latitude: -41, Longitude: 174})))
+
+		data, err := json.Marshal(fc)
+		assert.Nil(t, err)
+
+		decoded := FeatureCollection{}
+		err = json.Unmarshal(data, &decoded)
+		assert.Nil(t, err)
+
+		assert.Len(t, decoded.Features, 2)
+		assert.EqualValues(t, GeometryLineString, decoded.Features[0].Geometry.Type)
+		assert.EqualValues(t, [][]float64{{2, 1}, {4, 3}}, decoded.Features[0].Geometry.LineString)
+		assert.EqualValues(t, "line", decoded.Features[0].Properties["name"])
+		assert.EqualValues(t, base.Location{Latitude: -41, Longitude: 174}, Location(decoded.Features[1].Geometry.Point))
+	})
+
+	t.Run("Rejects unknown geometry types", func(t *testing.T) {
+		g := Geometry{}
+		err := json.Unmarshal([]byte(`{"type":"Circle","coordinates":[0,0]}`), &g)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Converts API results", func(t *testing.T) {
+		line := []base.Location{{Latitude: 38.5, Longitude: -120.2}, {Latitude: 40.7, Longitude: -120.95}}
+
+		route := directions.Route{Distance: 10, Geometry: base.EncodePolyline(line, base.PolylinePrecision)}
+		f, err := FromRoute(&route, base.PolylinePrecision)
+		assert.Nil(t, err)
+		assert.EqualValues(t, [][]float64{{-120.2, 38.5}, {-120.95, 40.7}}, f.Geometry.LineString)
+		assert.EqualValues(t, 10, f.Properties["distance"])
+
+		_, err = FromRoute(&directions.Route{}, base.PolylinePrecision)
+		assert.NotNil(t, err)
+
+		matching := mapmatching.Matchings{Confidence: 0.9, Geometry: map[string]interface{}{
+			"type":        "LineString",
+			"coordinates": []interface{}{[]interface{}{-120.2, 38.5}, []interface{}{-120.95, 40.7}},
+		}}
+		f, err = FromMatching(&matching, base.PolylinePrecision)
+		assert.Nil(t, err)
+		assert.EqualValues(t, [][]float64{{-120.2, 38.5}, {-120.95, 40.7}}, f.Geometry.LineString)
+
+		geocode := base.FeatureCollection{Features: []base.Feature{{
+			ID:        "place.1",
+			PlaceName: "Wellington, New Zealand",
+			Geometry:  base.Geometry{Type: "Point", Coordinates: base.Point{174.77, -41.28}},
+		}}}
+		fc := FromBaseFeatureCollection(&geocode)
+		assert.Len(t, fc.Features, 1)
+		assert.EqualValues(t, "place.1", fc.Features[0].ID)
+		assert.EqualValues(t, "Wellington, New Zealand", fc.Features[0].Properties["title"])
+		assert.EqualValues(t, base.Location{Latitude: -41.28, Longitude: 174.77}, Location(fc.Features[0].Geometry.Point))
+	})
+}
@@ -0,0 +1,225 @@
+/**
+ * go-mapbox Heatmap Module
+ * Kernel density rasters from weighted point sets in web mercator pixel space
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package heatmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+const (
+	// DefaultRadius is the default kernel bandwidth (gaussian standard deviation) in pixels
+	DefaultRadius = 10.0
+	// DefaultOpacity is the default heatmap opacity when compositing
+	DefaultOpacity = 0.6
+)
+
+// kernelExtent is the kernel cut off as a multiple of the bandwidth
+const kernelExtent = 3
+
+// Point is a weighted location
+type Point struct {
+	Location base.Location
+	Weight   float64
+}
+
+// NewPoints creates unit weight points from a list of locations
+func NewPoints(locs []base.Location) []Point {
+	points := make([]Point, len(locs))
+	for i, l := range locs {
+		points[i] = Point{Location: l, Weight: 1}
+	}
+	return points
+}
+
+// Opts configures heatmap generation
+// Set Max when compositing tiles individually so colours are consistent between tiles
+type Opts struct {
+	Radius  float64  // Kernel bandwidth in pixels, defaults to DefaultRadius
+	Ramp    Ramp     // Colour ramp, defaults to RampHeat
+	Opacity *float64 // Opacity from 0 to 1 when compositing, defaults to DefaultOpacity (see base.Float64)
+	Max     float64  // Density mapped to the top of the ramp, defaults to the maximum density
+}
+
+func withDefaults(opts *Opts) Opts {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Radius <= 0 {
+		o.Radius = DefaultRadius
+	}
+	if len(o.Ramp) == 0 {
+		o.Ramp = RampHeat
+	}
+	if o.Opacity == nil {
+		o.Opacity = base.Float64(DefaultOpacity)
+	}
+	return o
+}
+
+// Density is a kernel density raster covering a region of global pixel space at a zoom level
+// Values are weights per square pixel
+type Density struct {
+	Level  uint64
+	Size   uint64
+	Bounds image.Rectangle // Global pixel bounds
+	Values []float64       // Row major values covering the bounds
+	Max    float64         // Maximum value
+}
+
+// NewDensity computes the kernel density of points over global pixel bounds at a zoom level
+// Points are splatted onto a grid then blurred with a gaussian kernel of the provided bandwidth
+func NewDensity(points []Point, level, size uint64, bounds image.Rectangle, radius float64) *Density {
+	if radius <= 0 {
+		radius = DefaultRadius
+	}
+
+	// Include points within the kernel extent of the bounds
+	margin := int(math.Ceil(radius * kernelExtent))
+	region := bounds.Inset(-margin)
+	w, h := region.Dx(), region.Dy()
+	grid := make([]float64, w*h)
+
+	for _, p := range points {
+		x, y := maps.MercatorLocationToPixel(p.Location.Latitude, p.Location.Longitude, level, size)
+
+		// Bilinear splatting about pixel centres keeps sub-pixel positions
+		fx, fy := x-float64(region.Min.X)-0.5, y-float64(region.Min.Y)-0.5
+		x0, y0 := int(math.Floor(fx)), int(math.Floor(fy))
+		dx, dy := fx-float64(x0), fy-float64(y0)
+
+		for _, s := range []struct {
+			x, y int
+			w    float64
+		}{
+			{x0, y0, (1 - dx) * (1 - dy)},
+			{x0 + 1, y0, dx * (1 - dy)},
+			{x0, y0 + 1, (1 - dx) * dy},
+			{x0 + 1, y0 + 1, dx * dy},
+		} {
+			if s.x >= 0 && s.x < w && s.y >= 0 && s.y < h {
+				grid[s.y*w+s.x] += p.Weight * s.w
+			}
+		}
+	}
+
+	blurred := blur(grid, w, h, radius)
+
+	d := Density{
+		Level:  level,
+		Size:   size,
+		Bounds: bounds,
+		Values: make([]float64, bounds.Dx()*bounds.Dy()),
+	}
+	for y := 0; y < bounds.Dy(); y++ {
+		copy(d.Values[y*bounds.Dx():(y+1)*bounds.Dx()], blurred[(y+margin)*w+margin:(y+margin)*w+margin+bounds.Dx()])
+	}
+	for _, v := range d.Values {
+		d.Max = math.Max(d.Max, v)
+	}
+
+	return &d
+}
+
+// blur applies a separable normalised gaussian blur
+func blur(grid []float64, w, h int, sigma float64) []float64 {
+	r := int(math.Ceil(sigma * kernelExtent))
+	kernel := make([]float64, 2*r+1)
+	sum := 0.0
+	for i := range kernel {
+		d := float64(i - r)
+		kernel[i] = math.Exp(-d * d / (2 * sigma * sigma))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+
+	tmp := make([]float64, len(grid))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := grid[y*w+x]
+			if v == 0 {
+				continue
+			}
+			for k, kv := range kernel {
+				if xi := x + k - r; xi >= 0 && xi < w {
+					tmp[y*w+xi] += v * kv
+				}
+			}
+		}
+	}
+
+	out := make([]float64, len(grid))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := tmp[y*w+x]
+			if v == 0 {
+				continue
+			}
+			for k, kv := range kernel {
+				if yi := y + k - r; yi >= 0 && yi < h {
+					out[yi*w+x] += v * kv
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// At returns the density at a global pixel, or zero outside the bounds
+func (d *Density) At(x, y int) float64 {
+	if !(image.Point{x, y}).In(d.Bounds) {
+		return 0
+	}
+	return d.Values[(y-d.Bounds.Min.Y)*d.Bounds.Dx()+(x-d.Bounds.Min.X)]
+}
+
+// Image colour maps the density, values at or above max map to the top of the ramp
+// A max of zero uses the maximum density
+func (d *Density) Image(ramp Ramp, max float64) *image.NRGBA {
+	if max <= 0 {
+		max = d.Max
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, d.Bounds.Dx(), d.Bounds.Dy()))
+	if max <= 0 {
+		return img
+	}
+
+	for y := 0; y < d.Bounds.Dy(); y++ {
+		for x := 0; x < d.Bounds.Dx(); x++ {
+			img.SetNRGBA(x, y, ramp.At(d.Values[y*d.Bounds.Dx()+x]/max))
+		}
+	}
+
+	return img
+}
+
+// Composite draws a heatmap of the points over a (possibly stitched) tile, such as the result of
+// maps.StitchTiles on tiles from Maps.GetEnclosingTiles
+func Composite(t *maps.Tile, points []Point, opts *Opts) *Density {
+	o := withDefaults(opts)
+
+	bounds := t.Bounds().Add(image.Point{int(t.X * t.Size), int(t.Y * t.Size)})
+	d := NewDensity(points, t.Level, t.Size, bounds, o.Radius)
+
+	overlay := d.Image(o.Ramp, o.Max)
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Max(0, math.Min(1, *o.Opacity))*255 + 0.5)})
+	draw.DrawMask(t.Image, t.Bounds(), overlay, image.Point{}, mask, image.Point{}, draw.Over)
+
+	return d
+}
@@ -0,0 +1,81 @@
+/**
+ * go-mapbox Heatmap Module Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package heatmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+func TestRamp(t *testing.T) {
+	assert.EqualValues(t, RampHeat[0].Color, RampHeat.At(-1))
+	assert.EqualValues(t, RampHeat[len(RampHeat)-1].Color, RampHeat.At(2))
+	assert.EqualValues(t, color.NRGBA{0, 255, 255, 255}, RampHeat.At(0.4))
+	assert.InDelta(t, 128, RampHeat.At(0.1).A, 1)
+	assert.EqualValues(t, RampGreys, Ramps["greys"])
+}
+
+func TestDensity(t *testing.T) {
+	level := uint64(10)
+	loc := base.Location{Latitude: -36.8485, Longitude: 174.7633}
+	x, y := maps.MercatorLocationToPixel(loc.Latitude, loc.Longitude, level, maps.SizeStandard)
+	cx, cy := int(x), int(y)
+	bounds := image.Rect(cx-64, cy-64, cx+64, cy+64)
+
+	t.Run("Spreads weights with a gaussian kernel", func(t *testing.T) {
+		d := NewDensity([]Point{{Location: loc, Weight: 3}}, level, maps.SizeStandard, bounds, 5)
+
+		// The kernel integrates to the point weight and peaks at the point
+		sum := 0.0
+		for _, v := range d.Values {
+			sum += v
+		}
+		assert.InDelta(t, 3, sum, 1e-3)
+		assert.InDelta(t, d.Max, d.At(cx, cy), d.Max*0.05)
+		assert.True(t, d.At(cx+10, cy) < d.At(cx+5, cy))
+		assert.InDelta(t, d.At(cx+8, cy), d.At(cx, cy+8), d.Max*0.05)
+		assert.EqualValues(t, 0, d.At(bounds.Max.X, cy))
+	})
+
+	t.Run("Includes points just outside the bounds", func(t *testing.T) {
+		lat, lng := maps.MercatorPixelToLocation(float64(bounds.Max.X+5), float64(cy), level, maps.SizeStandard)
+		d := NewDensity([]Point{{Location: base.Location{Latitude: lat, Longitude: lng}, Weight: 1}}, level, maps.SizeStandard, bounds, 5)
+		assert.True(t, d.At(bounds.Max.X-1, cy) > 0)
+	})
+
+	t.Run("Composites onto tiles", func(t *testing.T) {
+		tx, ty := maps.LocationToTileID(loc, level)
+		img := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		tile := maps.NewTile(tx-1, ty-1, level, maps.SizeStandard, img)
+
+		points := NewPoints([]base.Location{loc, loc, loc})
+		p := tile.LocationToLocal(loc)
+
+		hidden := tile.Clone()
+		Composite(&hidden, points, &Opts{Radius: 4, Opacity: base.Float64(0)})
+		assert.EqualValues(t, color.RGBA{255, 255, 255, 255}, hidden.At(int(p.X), int(p.Y)))
+
+		d := Composite(&tile, points, &Opts{Radius: 4, Opacity: base.Float64(1)})
+		assert.True(t, d.Max > 0)
+
+		hot := color.NRGBAModel.Convert(tile.At(int(p.X), int(p.Y))).(color.NRGBA)
+		assert.InDelta(t, 255, hot.R, 8)
+		assert.InDelta(t, 0, hot.B, 8)
+
+		cold := color.NRGBAModel.Convert(tile.At(int(p.X)+100, int(p.Y))).(color.NRGBA)
+		assert.EqualValues(t, color.NRGBA{255, 255, 255, 255}, cold)
+	})
+}
@@ -0,0 +1,101 @@
+/**
+ * go-mapbox Heatmap Module Colour Ramps
+ * Colour ramps for mapping normalised densities to colours
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package heatmap
+
+import (
+	"image/color"
+	"math"
+	"sort"
+)
+
+// Stop is a colour at a position (0 to 1) along a ramp
+type Stop struct {
+	Offset float64
+	Color  color.NRGBA
+}
+
+// Ramp is a list of colour stops ordered by offset
+type Ramp []Stop
+
+// Colour ramps, all ramps fade in from transparent so empty areas leave the base map visible
+var (
+	RampHeat = Ramp{
+		{0, color.NRGBA{0, 0, 255, 0}},
+		{0.2, color.NRGBA{0, 0, 255, 255}},
+		{0.4, color.NRGBA{0, 255, 255, 255}},
+		{0.6, color.NRGBA{0, 255, 0, 255}},
+		{0.8, color.NRGBA{255, 255, 0, 255}},
+		{1, color.NRGBA{255, 0, 0, 255}},
+	}
+	RampViridis = Ramp{
+		{0, color.NRGBA{68, 1, 84, 0}},
+		{0.1, color.NRGBA{68, 1, 84, 255}},
+		{0.3, color.NRGBA{59, 82, 139, 255}},
+		{0.5, color.NRGBA{33, 145, 140, 255}},
+		{0.75, color.NRGBA{94, 201, 98, 255}},
+		{1, color.NRGBA{253, 231, 37, 255}},
+	}
+	RampMagma = Ramp{
+		{0, color.NRGBA{0, 0, 4, 0}},
+		{0.1, color.NRGBA{28, 16, 68, 255}},
+		{0.3, color.NRGBA{114, 31, 129, 255}},
+		{0.5, color.NRGBA{183, 55, 121, 255}},
+		{0.75, color.NRGBA{252, 137, 97, 255}},
+		{1, color.NRGBA{252, 253, 191, 255}},
+	}
+	RampBlues = Ramp{
+		{0, color.NRGBA{222, 235, 247, 0}},
+		{0.2, color.NRGBA{198, 219, 239, 255}},
+		{0.6, color.NRGBA{66, 146, 198, 255}},
+		{1, color.NRGBA{8, 48, 107, 255}},
+	}
+	RampGreys = Ramp{
+		{0, color.NRGBA{255, 255, 255, 0}},
+		{0.2, color.NRGBA{217, 217, 217, 255}},
+		{1, color.NRGBA{0, 0, 0, 255}},
+	}
+)
+
+// Ramps maps ramp names to colour ramps
+var Ramps = map[string]Ramp{
+	"heat":    RampHeat,
+	"viridis": RampViridis,
+	"magma":   RampMagma,
+	"blues":   RampBlues,
+	"greys":   RampGreys,
+}
+
+// At returns the colour at position t (clamped to 0 to 1) along the ramp, interpolating between stops
+func (r Ramp) At(t float64) color.NRGBA {
+	if len(r) == 0 {
+		return color.NRGBA{}
+	}
+	t = math.Max(0, math.Min(1, t))
+
+	i := sort.Search(len(r), func(i int) bool { return r[i].Offset >= t })
+	if i == 0 {
+		return r[0].Color
+	}
+	if i == len(r) {
+		return r[len(r)-1].Color
+	}
+
+	a, b := r[i-1], r[i]
+	f := (t - a.Offset) / (b.Offset - a.Offset)
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*f + 0.5)
+	}
+
+	return color.NRGBA{
+		R: lerp(a.Color.R, b.Color.R),
+		G: lerp(a.Color.G, b.Color.G),
+		B: lerp(a.Color.B, b.Color.B),
+		A: lerp(a.Color.A, b.Color.A),
+	}
+}
@@ -11,6 +11,8 @@ package mapmatching
 
 import (
 	"fmt"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
 )
 
 // MatchingResponse is the response from GetMatching
@@ -92,6 +94,24 @@ func (m *Matchings) GetGeometryPolyline() (string, error) {
 	return g, nil
 }
 
+// GetLocations decodes the matching geometry (polyline or geojson) into a list of locations
+// The precision is used for polyline geometries (base.PolylinePrecision or base.Polyline6Precision)
+func (m *Matchings) GetLocations(precision uint) ([]base.Location, error) {
+	if polyline, err := m.GetGeometryPolyline(); err == nil {
+		return base.DecodePolyline(polyline, precision)
+	}
+
+	geojson, err := m.GetGeometryGeojson()
+	if err != nil {
+		return nil, err
+	}
+	locs := make([]base.Location, len(geojson.Coordinates))
+	for i, c := range geojson.Coordinates {
+		locs[i] = base.Location{Latitude: c[1], Longitude: c[0]}
+	}
+	return locs, nil
+}
+
 //MatchingLeg legs inside the matching object
 type MatchingLeg struct {
 	Step     []float64
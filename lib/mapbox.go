@@ -16,6 +16,7 @@ import (
 	"github.com/tumasgiu/go-mapbox/lib/geocode"
 	"github.com/tumasgiu/go-mapbox/lib/map_matching"
 	"github.com/tumasgiu/go-mapbox/lib/maps"
+	"github.com/tumasgiu/go-mapbox/lib/search"
 	"github.com/tumasgiu/go-mapbox/lib/styles"
 )
 
@@ -26,6 +27,8 @@ type Mapbox struct {
 	Maps *maps.Maps
 	// Geocode allows forward (by address) and reverse (by lat/lng) geocoding
 	Geocode *geocode.Geocode
+	// Search provides interactive POI, category and address search
+	Search *search.Search
 	// Directions generates directions between arbitrary points
 	Directions *directions.Directions
 	// Direction Matrix returns all travel times and ways points between multiple points
@@ -49,6 +52,7 @@ func New(token string) (*Mapbox, error) {
 	// Bind modules
 	m.Maps = maps.NewMaps(m.base)
 	m.Geocode = geocode.NewGeocode(m.base)
+	m.Search = search.NewSearch(m.base)
 	m.Directions = directions.NewDirections(m.base)
 	m.DirectionsMatrix = directionsmatrix.NewDirectionsMatrix(m.base)
 	m.MapMatching = mapmatching.NewMapMaptching(m.base)
@@ -0,0 +1,125 @@
+/**
+ * go-mapbox Maps Module Bounding Box Rendering
+ * Renders a map image covering an exact bounding box at a target pixel size
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+const (
+	// MaxLatitude is the latitude limit of the spherical mercator projection
+	MaxLatitude = 85.0511287798066
+	// MaxLevel is the highest zoom level that will be requested by helpers that select a zoom
+	MaxLevel uint64 = 22
+)
+
+// unwrapEast returns the east edge of a bounding box unwrapped so that it is not less than the west edge
+// Boxes cross the antimeridian only where west > east, equal edges are a zero width box rather than
+// one spanning the whole world
+func unwrapEast(west, east float64) float64 {
+	if east < west {
+		return east + 360
+	}
+	return east
+}
+
+// bboxBounds validates a [minLng, minLat, maxLng, maxLat] bounding box and returns its west, south, east
+// and north edges, where east is unwrapped (east > west) for boxes crossing the antimeridian
+func bboxBounds(bbox base.BoundingBox) (float64, float64, float64, float64, error) {
+	if len(bbox) != 4 {
+		return 0, 0, 0, 0, fmt.Errorf("Bounding box must contain 4 values (received %d)", len(bbox))
+	}
+	west, south, east, north := bbox[0], bbox[1], bbox[2], bbox[3]
+
+	if south >= north {
+		return 0, 0, 0, 0, fmt.Errorf("Bounding box south (%f) must be less than north (%f)", south, north)
+	}
+	if south < -MaxLatitude || north > MaxLatitude {
+		return 0, 0, 0, 0, fmt.Errorf("Bounding box latitudes must be within +/- %f", MaxLatitude)
+	}
+	if west < -180 || west > 180 || east < -180 || east > 180 {
+		return 0, 0, 0, 0, fmt.Errorf("Bounding box longitudes must be within +/- 180")
+	}
+
+	if west == east {
+		return 0, 0, 0, 0, fmt.Errorf("Bounding box west (%f) and east (%f) must differ", west, east)
+	}
+	east = unwrapEast(west, east)
+
+	return west, south, east, north, nil
+}
+
+// bboxLevel selects the lowest zoom level at which the bounding box covers at least the requested pixel size
+func bboxLevel(west, south, east, north float64, width, height int, size uint64) uint64 {
+	zx, zy := boundsZoom(west, south, east, north, float64(width), float64(height), size)
+
+	z := math.Ceil(math.Max(zx, zy))
+	if z < 0 {
+		return 0
+	}
+	if z > float64(MaxLevel) {
+		return MaxLevel
+	}
+	return uint64(z)
+}
+
+// RenderBBox renders a map image of exactly the provided [minLng, minLat, maxLng, maxLat] bounding box
+// at the requested pixel size. Tiles are fetched at the best zoom level for the output size, stitched,
+// then cropped and resampled. Boxes crossing the antimeridian (minLng > maxLng) are supported.
+// The returned EPSG:3857 transform georeferences the output image
+func (m *Maps) RenderBBox(mapID MapID, bbox base.BoundingBox, width, height int, format MapFormat) (image.Image, GeoTransform, error) {
+	if width <= 0 || height <= 0 {
+		return nil, GeoTransform{}, fmt.Errorf("Output size must be positive (received %dx%d)", width, height)
+	}
+
+	west, south, east, north, err := bboxBounds(bbox)
+	if err != nil {
+		return nil, GeoTransform{}, err
+	}
+
+	size := SizeStandard
+	level := bboxLevel(west, south, east, north, width, height, size)
+
+	// Global pixel extents at the selected level, x1 may extend past the edge of the world
+	x0, y0 := MercatorLocationToPixel(north, west, level, size)
+	x1, y1 := MercatorLocationToPixel(south, east, level, size)
+
+	fsize := float64(size)
+	xStart, yStart := uint64(x0/fsize), uint64(y0/fsize)
+	xEnd, yEnd := uint64(math.Ceil(x1/fsize))-1, uint64(math.Ceil(y1/fsize))-1
+
+	tiles, err := m.getTileRange(mapID, xStart, yStart, xEnd, yEnd, level, format, false)
+	if err != nil {
+		return nil, GeoTransform{}, err
+	}
+	stitched := StitchTiles(tiles)
+
+	// Scale from output pixels to stitched pixels
+	scaleX, scaleY := (x1-x0)/float64(width), (y1-y0)/float64(height)
+	offsetX, offsetY := x0-float64(xStart)*fsize, y0-float64(yStart)*fsize
+
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			sx := offsetX + (float64(x)+0.5)*scaleX
+			sy := offsetY + (float64(y)+0.5)*scaleY
+			out.SetNRGBA(x, y, SampleBilinear(stitched, sx, sy))
+		}
+	}
+
+	gt := MercatorGeoTransform(x0, y0, level, size)
+	gt.PixelWidth *= scaleX
+	gt.PixelHeight *= scaleY
+
+	return out, gt, nil
+}
@@ -0,0 +1,124 @@
+/**
+ * go-mapbox Maps Module Bounding Box Tests
+ * Uses synthetic tiles so no API token is required
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// syntheticCache generates tiles on demand where red encodes the global x position and green the global y position
+type syntheticCache struct {
+	fetches int
+}
+
+func (c *syntheticCache) Save(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool, img image.Image) error {
+	return nil
+}
+
+func (c *syntheticCache) Fetch(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) (image.Image, *image.Config, error) {
+	c.fetches++
+
+	size := int(SizeStandard)
+	if highDPI {
+		size = int(SizeHighDPI)
+	}
+	world := float64(uint64(size) << level)
+
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for py := 0; py < size; py++ {
+		for px := 0; px < size; px++ {
+			gx := float64(int(x)*size+px) + 0.5
+			gy := float64(int(y)*size+py) + 0.5
+			img.SetNRGBA(px, py, color.NRGBA{R: uint8(gx / world * 255), G: uint8(gy / world * 255), B: 0, A: 255})
+		}
+	}
+
+	cfg := image.Config{ColorModel: img.ColorModel(), Width: size, Height: size}
+	return img, &cfg, nil
+}
+
+func newSyntheticMaps(t *testing.T) (*Maps, *syntheticCache) {
+	b, err := base.NewBase("synthetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := &syntheticCache{}
+	m := NewMaps(b)
+	m.SetCache(cache)
+	return m, cache
+}
+
+func TestRenderBBox(t *testing.T) {
+	maps, _ := newSyntheticMaps(t)
+
+	t.Run("Renders exact bounding boxes", func(t *testing.T) {
+		bbox := base.BoundingBox{166.5, -47.3, 178.6, -34.4}
+
+		img, gt, err := maps.RenderBBox(MapIDStreets, bbox, 400, 300, MapFormatPng)
+		assert.Nil(t, err)
+		assert.EqualValues(t, image.Rect(0, 0, 400, 300), img.Bounds())
+
+		// Output corners map to the bounding box corners
+		lat, lng := MercatorMetersToLocation(gt.PixelToCoordinate(0, 0))
+		assert.InDelta(t, bbox[3], lat, 1e-6)
+		assert.InDelta(t, bbox[0], lng, 1e-6)
+		lat, lng = MercatorMetersToLocation(gt.PixelToCoordinate(400, 300))
+		assert.InDelta(t, bbox[1], lat, 1e-6)
+		assert.InDelta(t, bbox[2], lng, 1e-6)
+
+		// Pixel contents match their location
+		for _, p := range [][2]int{{0, 0}, {200, 150}, {399, 299}} {
+			_, lng := MercatorMetersToLocation(gt.PixelToCoordinate(float64(p[0])+0.5, float64(p[1])+0.5))
+			r, _, _, _ := img.At(p[0], p[1]).RGBA()
+			assert.InDelta(t, (lng+180)/360*255, float64(r>>8), 1.5)
+		}
+	})
+
+	t.Run("Renders bounding boxes crossing the antimeridian", func(t *testing.T) {
+		bbox := base.BoundingBox{170, -20, -170, -10}
+
+		img, gt, err := maps.RenderBBox(MapIDStreets, bbox, 200, 100, MapFormatPng)
+		assert.Nil(t, err)
+
+		left, _, _, _ := img.At(0, 50).RGBA()
+		right, _, _, _ := img.At(199, 50).RGBA()
+		assert.InDelta(t, 350.0/360*255, float64(left>>8), 1.5)
+		assert.InDelta(t, 10.0/360*255, float64(right>>8), 1.5)
+
+		_, lng := MercatorMetersToLocation(gt.PixelToCoordinate(200, 0))
+		assert.InDelta(t, 190, lng, 1e-6)
+	})
+
+	t.Run("Selects the lowest sufficient zoom level", func(t *testing.T) {
+		// The whole world at zoom 0 is 256px wide
+		assert.EqualValues(t, 0, bboxLevel(-180, -MaxLatitude, 180, MaxLatitude, 256, 256, SizeStandard))
+		assert.EqualValues(t, 1, bboxLevel(-180, -MaxLatitude, 180, MaxLatitude, 257, 256, SizeStandard))
+		assert.EqualValues(t, 2, bboxLevel(-180, -MaxLatitude, 180, MaxLatitude, 1024, 1024, SizeStandard))
+		assert.EqualValues(t, MaxLevel, bboxLevel(0, 0, 1e-9, 1e-9, 1024, 1024, SizeStandard))
+	})
+
+	t.Run("Rejects invalid bounding boxes", func(t *testing.T) {
+		_, _, err := maps.RenderBBox(MapIDStreets, base.BoundingBox{0, 0, 1}, 10, 10, MapFormatPng)
+		assert.NotNil(t, err)
+		_, _, err = maps.RenderBBox(MapIDStreets, base.BoundingBox{0, 10, 1, 5}, 10, 10, MapFormatPng)
+		assert.NotNil(t, err)
+		_, _, err = maps.RenderBBox(MapIDStreets, base.BoundingBox{0, 0, 1, 89}, 10, 10, MapFormatPng)
+		assert.NotNil(t, err)
+		_, _, err = maps.RenderBBox(MapIDStreets, base.BoundingBox{0, 0, 1, 1}, 0, 10, MapFormatPng)
+		assert.NotNil(t, err)
+		_, _, err = maps.RenderBBox(MapIDStreets, base.BoundingBox{10, 0, 10, 1}, 10, 10, MapFormatPng)
+		assert.NotNil(t, err)
+	})
+}
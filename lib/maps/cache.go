@@ -9,14 +9,22 @@
 package maps
 
 import (
+	"bufio"
+	"bytes"
+	"container/list"
 	"fmt"
 	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
+	"sync"
 )
 
 // FileCache is a simple file-based caching implementation for map tiles
-// This does not implement any mechanisms for deletion / removal, and as such is not suitable for production use
+// This does not implement any eviction, and as such is not suitable for production use
 type FileCache struct {
 	basePath string
 }
@@ -51,16 +59,40 @@ func (fc *FileCache) Save(mapID MapID, x, y, level uint64, format MapFormat, hig
 		return nil
 	}
 
-	// TODO: cannot currently save pngraw
-	if strings.Contains(string(format), "png") {
-		return SaveImagePNG(img, path)
+	// PNG encoding is lossless so pngraw (terrain) tiles survive the round trip
+	var encode func(w io.Writer) error
+	switch {
+	case strings.Contains(string(format), "png"):
+		encode = func(w io.Writer) error { return png.Encode(w, img) }
+	case strings.Contains(string(format), "jpg") || strings.Contains(string(format), "jpeg"):
+		encode = func(w io.Writer) error { return jpeg.Encode(w, img, nil) }
+	default:
+		return fmt.Errorf("Unrecognized file type (%s)", format)
 	}
 
-	if strings.Contains(string(format), "jpg") || strings.Contains(string(format), "jpeg") {
-		return SaveImageJPG(img, path)
+	return fc.write(path, encode)
+}
+
+// write writes a cache file via a temporary file so concurrent readers never see a partial tile
+func (fc *FileCache) write(path string, encode func(w io.Writer) error) error {
+	tmp, err := ioutil.TempFile(fc.basePath, "tmp-")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	err = encode(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
 	}
 
-	return fmt.Errorf("Unrecognized file type (%s)", format)
+	return os.Rename(tmp.Name(), path)
 }
 
 // Fetch fetches an image from the file cache if possible
@@ -68,11 +100,6 @@ func (fc *FileCache) Fetch(mapID MapID, x, y, level uint64, format MapFormat, hi
 	name := fc.getName(mapID, x, y, level, format, highDPI)
 	path := fmt.Sprintf("%s/%s", fc.basePath, name)
 
-	// TODO: work out how to load raw png files :-/
-	if format == MapFormatPngRaw {
-		return nil, nil, nil
-	}
-
 	if _, err := os.Stat(path); err != nil {
 		return nil, nil, nil
 	}
@@ -81,3 +108,175 @@ func (fc *FileCache) Fetch(mapID MapID, x, y, level uint64, format MapFormat, hi
 
 	return img, cfg, err
 }
+
+// SaveData saves encoded tile data to the file cache
+func (fc *FileCache) SaveData(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool, data []byte) error {
+	name := fc.getName(mapID, x, y, level, format, highDPI)
+	path := fmt.Sprintf("%s/%s", fc.basePath, name)
+
+	return fc.write(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// FetchData fetches encoded tile data from the file cache if possible
+func (fc *FileCache) FetchData(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) ([]byte, error) {
+	name := fc.getName(mapID, x, y, level, format, highDPI)
+	path := fmt.Sprintf("%s/%s", fc.basePath, name)
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return data, err
+}
+
+// Delete removes an image from the file cache if present
+func (fc *FileCache) Delete(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) error {
+	name := fc.getName(mapID, x, y, level, format, highDPI)
+	path := fmt.Sprintf("%s/%s", fc.basePath, name)
+
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+type memoryCacheKey struct {
+	mapID   MapID
+	x, y    uint64
+	level   uint64
+	format  MapFormat
+	highDPI bool
+}
+
+// MemoryCache is a simple in-memory caching implementation for map tiles
+// Unbounded caches never evict, and as such are best suited to short lived or bounded workloads,
+// bounded caches evict the least recently used tiles once full
+type MemoryCache struct {
+	mu    sync.Mutex
+	limit int
+	order *list.List // Entries from most to least recently used
+	tiles map[memoryCacheKey]*list.Element
+}
+
+// memoryCacheEntry holds either a decoded image or encoded data for a tile
+type memoryCacheEntry struct {
+	key  memoryCacheKey
+	img  image.Image
+	data []byte
+}
+
+// NewMemoryCache creates a new memory cache instance without a size limit
+func NewMemoryCache() *MemoryCache {
+	return NewBoundedMemoryCache(0)
+}
+
+// NewBoundedMemoryCache creates a new memory cache instance holding at most maxTiles tiles,
+// a limit of zero disables eviction
+func NewBoundedMemoryCache(maxTiles int) *MemoryCache {
+	return &MemoryCache{limit: maxTiles, order: list.New(), tiles: make(map[memoryCacheKey]*list.Element)}
+}
+
+// Len returns the number of cached tiles
+func (mc *MemoryCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.order.Len()
+}
+
+// put adds or replaces a cache entry, evicting the least recently used entries once full
+func (mc *MemoryCache) put(entry *memoryCacheEntry) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if e, ok := mc.tiles[entry.key]; ok {
+		e.Value = entry
+		mc.order.MoveToFront(e)
+		return
+	}
+
+	mc.tiles[entry.key] = mc.order.PushFront(entry)
+	for mc.limit > 0 && mc.order.Len() > mc.limit {
+		oldest := mc.order.Back()
+		mc.order.Remove(oldest)
+		delete(mc.tiles, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// get fetches a cache entry, marking it as recently used
+func (mc *MemoryCache) get(key memoryCacheKey) *memoryCacheEntry {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	e, ok := mc.tiles[key]
+	if !ok {
+		return nil
+	}
+	mc.order.MoveToFront(e)
+
+	return e.Value.(*memoryCacheEntry)
+}
+
+// Save saves an image to the memory cache
+func (mc *MemoryCache) Save(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool, img image.Image) error {
+	mc.put(&memoryCacheEntry{key: memoryCacheKey{mapID, x, y, level, format, highDPI}, img: img})
+	return nil
+}
+
+// Fetch fetches an image from the memory cache if possible, decoding tiles saved as encoded data
+func (mc *MemoryCache) Fetch(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) (image.Image, *image.Config, error) {
+	entry := mc.get(memoryCacheKey{mapID, x, y, level, format, highDPI})
+	if entry == nil {
+		return nil, nil, nil
+	}
+
+	img := entry.img
+	if img == nil {
+		var err error
+		if img, _, err = image.Decode(bytes.NewReader(entry.data)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	b := img.Bounds()
+	cfg := image.Config{ColorModel: img.ColorModel(), Width: b.Dx(), Height: b.Dy()}
+
+	return img, &cfg, nil
+}
+
+// SaveData saves encoded tile data to the memory cache
+func (mc *MemoryCache) SaveData(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool, data []byte) error {
+	mc.put(&memoryCacheEntry{key: memoryCacheKey{mapID, x, y, level, format, highDPI}, data: data})
+	return nil
+}
+
+// FetchData fetches encoded tile data from the memory cache if possible
+// Tiles saved as images are not returned, as encoding is left to the caller
+func (mc *MemoryCache) FetchData(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) ([]byte, error) {
+	entry := mc.get(memoryCacheKey{mapID, x, y, level, format, highDPI})
+	if entry == nil {
+		return nil, nil
+	}
+
+	return entry.data, nil
+}
+
+// Delete removes an image from the memory cache if present
+func (mc *MemoryCache) Delete(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	key := memoryCacheKey{mapID, x, y, level, format, highDPI}
+	if e, ok := mc.tiles[key]; ok {
+		mc.order.Remove(e)
+		delete(mc.tiles, key)
+	}
+
+	return nil
+}
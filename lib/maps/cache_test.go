@@ -0,0 +1,112 @@
+/**
+ * go-mapbox Maps Module Cache Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheDelete(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mapbox-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileCache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cache := range map[string]Cache{"file": fileCache, "memory": NewMemoryCache()} {
+		t.Run(name, func(t *testing.T) {
+			img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+			assert.Nil(t, cache.Save(MapIDStreets, 1, 2, 3, MapFormatPng, false, img))
+
+			found, _, err := cache.Fetch(MapIDStreets, 1, 2, 3, MapFormatPng, false)
+			assert.Nil(t, err)
+			assert.NotNil(t, found)
+
+			deleter, ok := cache.(CacheDeleter)
+			assert.True(t, ok)
+			assert.Nil(t, deleter.Delete(MapIDStreets, 1, 2, 3, MapFormatPng, false))
+			assert.Nil(t, deleter.Delete(MapIDStreets, 1, 2, 3, MapFormatPng, false))
+
+			found, _, err = cache.Fetch(MapIDStreets, 1, 2, 3, MapFormatPng, false)
+			assert.Nil(t, err)
+			assert.Nil(t, found)
+		})
+	}
+}
+
+func TestFileCacheConcurrentFetch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-mapbox-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7919 >> 3)
+	}
+
+	const count = 20
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for x := uint64(0); x < count; x++ {
+			cache.Save(MapIDStreets, x, 0, 5, MapFormatPng, false, img)
+		}
+	}()
+
+	// Readers must see either no tile or a complete tile, never a partially written one
+	for x := uint64(0); x < count; x++ {
+		for {
+			found, _, err := cache.Fetch(MapIDStreets, x, 0, 5, MapFormatPng, false)
+			if !assert.Nil(t, err) || found != nil {
+				break
+			}
+		}
+	}
+	<-done
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, count)
+}
+
+func TestBoundedMemoryCache(t *testing.T) {
+	cache := NewBoundedMemoryCache(2)
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	assert.Nil(t, cache.Save(MapIDStreets, 1, 0, 3, MapFormatPng, false, img))
+	assert.Nil(t, cache.Save(MapIDStreets, 2, 0, 3, MapFormatPng, false, img))
+
+	// Fetching the first tile makes the second the least recently used
+	found, _, _ := cache.Fetch(MapIDStreets, 1, 0, 3, MapFormatPng, false)
+	assert.NotNil(t, found)
+	assert.Nil(t, cache.Save(MapIDStreets, 3, 0, 3, MapFormatPng, false, img))
+	assert.EqualValues(t, 2, cache.Len())
+
+	for x, cached := range map[uint64]bool{1: true, 2: false, 3: true} {
+		found, _, err := cache.Fetch(MapIDStreets, x, 0, 3, MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.Equal(t, cached, found != nil, "tile %d", x)
+	}
+}
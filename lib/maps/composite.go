@@ -0,0 +1,144 @@
+/**
+ * go-mapbox Maps Module Compositing
+ * Blends tiles from multiple map IDs into a single tile
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sync"
+)
+
+// BlendMode selects how a layer is combined with the layers beneath it
+type BlendMode string
+
+// Blend modes
+const (
+	BlendNormal   BlendMode = "normal"
+	BlendMultiply BlendMode = "multiply"
+	BlendScreen   BlendMode = "screen"
+)
+
+// LayerSpec describes a layer in a composite tile
+type LayerSpec struct {
+	MapID     MapID
+	Format    MapFormat
+	Opacity   *float64  // Layer opacity from 0 (hidden) to 1, defaults to fully opaque (see base.Float64)
+	BlendMode BlendMode // Blend mode, defaults to BlendNormal
+	HighDPI   bool
+}
+
+func (l *LayerSpec) opacity() float64 {
+	if l.Opacity == nil {
+		return 1
+	}
+	return *l.Opacity
+}
+
+// blend applies a blend mode to normalised backdrop and source colour channels
+func (b BlendMode) blend(cb, cs float64) float64 {
+	switch b {
+	case BlendMultiply:
+		return cb * cs
+	case BlendScreen:
+		return cb + cs - cb*cs
+	default:
+		return cs
+	}
+}
+
+// GetCompositeTile fetches a tile for each layer (concurrently and through the cache if bound)
+// and blends them in order, with the first layer at the bottom
+// The result has the size of the first layer, other layers are resampled to match
+func (m *Maps) GetCompositeTile(layers []LayerSpec, z, x, y uint64) (*Tile, error) {
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("Composite tiles require at least one layer")
+	}
+	for i, l := range layers {
+		switch l.BlendMode {
+		case "", BlendNormal, BlendMultiply, BlendScreen:
+		default:
+			return nil, fmt.Errorf("Unsupported blend mode for layer %d (%s)", i, l.BlendMode)
+		}
+		if o := l.opacity(); o < 0 || o > 1 {
+			return nil, fmt.Errorf("Layer %d opacity must be between 0 and 1 (received %f)", i, o)
+		}
+	}
+
+	tiles := make([]*Tile, len(layers))
+	errs := make([]error, len(layers))
+
+	var wg sync.WaitGroup
+	wg.Add(len(layers))
+	for i, l := range layers {
+		go func(i int, l LayerSpec) {
+			defer wg.Done()
+			tiles[i], errs[i] = m.GetTile(l.MapID, x, y, z, l.Format, l.HighDPI)
+		}(i, l)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching layer %d (%s)", i, err)
+		}
+	}
+
+	first := tiles[0]
+	out := NewTile(x, y, z, first.Size, image.NewNRGBA(first.Bounds()))
+	for i, l := range layers {
+		compositeLayer(out.Image.(*image.NRGBA), tiles[i], l.BlendMode, l.opacity())
+	}
+
+	return &out, nil
+}
+
+// compositeLayer blends a source tile over a destination image
+// See https://www.w3.org/TR/compositing-1/ for the blending and compositing equations
+func compositeLayer(dst *image.NRGBA, src *Tile, mode BlendMode, opacity float64) {
+	b := dst.Bounds()
+	sb := src.Bounds()
+	resample := sb.Size() != b.Size()
+	sx, sy := float64(sb.Dx())/float64(b.Dx()), float64(sb.Dy())/float64(b.Dy())
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			var s color.NRGBA
+			if resample {
+				s = SampleBilinear(src.Image, (float64(x-b.Min.X)+0.5)*sx+float64(sb.Min.X), (float64(y-b.Min.Y)+0.5)*sy+float64(sb.Min.Y))
+			} else {
+				s = color.NRGBAModel.Convert(src.At(x-b.Min.X+sb.Min.X, y-b.Min.Y+sb.Min.Y)).(color.NRGBA)
+			}
+			d := dst.NRGBAAt(x, y)
+
+			as := float64(s.A) / 255 * opacity
+			ab := float64(d.A) / 255
+			ao := as + ab*(1-as)
+			if ao == 0 {
+				dst.SetNRGBA(x, y, color.NRGBA{})
+				continue
+			}
+
+			channel := func(cb, cs uint8) uint8 {
+				fb, fs := float64(cb)/255, float64(cs)/255
+				mixed := (1-ab)*fs + ab*mode.blend(fb, fs)
+				co := (as*mixed + (1-as)*ab*fb) / ao
+				return uint8(math.Round(math.Min(1, co) * 255))
+			}
+
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: channel(d.R, s.R),
+				G: channel(d.G, s.G),
+				B: channel(d.B, s.B),
+				A: uint8(math.Round(ao * 255)),
+			})
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/**
+ * go-mapbox Maps Module Compositing Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+func solidImage(size int, c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestCompositeTile(t *testing.T) {
+	b, err := base.NewBase("synthetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewMemoryCache()
+	maps := NewMaps(b)
+	maps.SetCache(cache)
+
+	cache.Save(MapIDSatellite, 1, 2, 3, MapFormatJpg90, false, solidImage(256, color.NRGBA{200, 100, 50, 255}))
+	cache.Save(MapIDStreets, 1, 2, 3, MapFormatPng, false, solidImage(256, color.NRGBA{128, 128, 128, 255}))
+	cache.Save(MapIDOutdoors, 1, 2, 3, MapFormatPng, true, solidImage(512, color.NRGBA{255, 0, 0, 255}))
+
+	satellite := LayerSpec{MapID: MapIDSatellite, Format: MapFormatJpg90}
+	pixel := func(layers ...LayerSpec) color.NRGBA {
+		tile, err := maps.GetCompositeTile(layers, 3, 1, 2)
+		assert.Nil(t, err)
+		assert.EqualValues(t, image.Rect(0, 0, 256, 256), tile.Bounds())
+		return color.NRGBAModel.Convert(tile.At(100, 100)).(color.NRGBA)
+	}
+
+	t.Run("Blends layers with opacity", func(t *testing.T) {
+		assert.EqualValues(t, color.NRGBA{200, 100, 50, 255}, pixel(satellite))
+		assert.EqualValues(t, color.NRGBA{164, 114, 89, 255}, pixel(satellite, LayerSpec{MapID: MapIDStreets, Format: MapFormatPng, Opacity: base.Float64(0.5)}))
+		assert.EqualValues(t, color.NRGBA{200, 100, 50, 255}, pixel(satellite, LayerSpec{MapID: MapIDStreets, Format: MapFormatPng, Opacity: base.Float64(0)}))
+	})
+
+	t.Run("Supports multiply and screen blend modes", func(t *testing.T) {
+		assert.EqualValues(t, color.NRGBA{100, 50, 25, 255}, pixel(satellite, LayerSpec{MapID: MapIDStreets, Format: MapFormatPng, BlendMode: BlendMultiply}))
+		assert.EqualValues(t, color.NRGBA{228, 178, 153, 255}, pixel(satellite, LayerSpec{MapID: MapIDStreets, Format: MapFormatPng, BlendMode: BlendScreen}))
+	})
+
+	t.Run("Resamples layers of different sizes", func(t *testing.T) {
+		assert.EqualValues(t, color.NRGBA{255, 0, 0, 255}, pixel(satellite, LayerSpec{MapID: MapIDOutdoors, Format: MapFormatPng, HighDPI: true}))
+	})
+
+	t.Run("Rejects invalid layers", func(t *testing.T) {
+		_, err := maps.GetCompositeTile(nil, 3, 1, 2)
+		assert.NotNil(t, err)
+		_, err = maps.GetCompositeTile([]LayerSpec{{MapID: MapIDStreets, Format: MapFormatPng, BlendMode: "overlay"}}, 3, 1, 2)
+		assert.NotNil(t, err)
+		_, err = maps.GetCompositeTile([]LayerSpec{{MapID: MapIDStreets, Format: MapFormatPng, Opacity: base.Float64(2)}}, 3, 1, 2)
+		assert.NotNil(t, err)
+	})
+}
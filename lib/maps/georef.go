@@ -0,0 +1,54 @@
+/**
+ * go-mapbox Maps Module Georeferencing
+ * Affine transforms between image pixels and projected coordinates
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+// EPSG codes for supported coordinate reference systems
+const (
+	EPSGWebMercator = 3857 // Spherical mercator in metres
+	EPSGWGS84       = 4326 // Geographic lat/lng in degrees
+)
+
+// GeoTransform is an affine transform from image pixels to projected coordinates (without rotation)
+// Following the GDAL convention pixel (0, 0) is the top left corner of the image, and PixelHeight
+// is negative for north up images
+type GeoTransform struct {
+	EPSG        int
+	OriginX     float64
+	OriginY     float64
+	PixelWidth  float64
+	PixelHeight float64
+}
+
+// MercatorGeoTransform builds the EPSG:3857 transform for an image whose top left pixel is at the provided
+// global pixel location for a zoom level and tile size
+func MercatorGeoTransform(x, y float64, zoom, size uint64) GeoTransform {
+	res := MercatorResolution(zoom, size)
+	return GeoTransform{
+		EPSG:        EPSGWebMercator,
+		OriginX:     x*res - pi*MercatorRadius,
+		OriginY:     pi*MercatorRadius - y*res,
+		PixelWidth:  res,
+		PixelHeight: -res,
+	}
+}
+
+// PixelToCoordinate converts an image pixel location to projected coordinates
+func (g GeoTransform) PixelToCoordinate(x, y float64) (float64, float64) {
+	return g.OriginX + x*g.PixelWidth, g.OriginY + y*g.PixelHeight
+}
+
+// CoordinateToPixel converts projected coordinates to an image pixel location
+func (g GeoTransform) CoordinateToPixel(x, y float64) (float64, float64) {
+	return (x - g.OriginX) / g.PixelWidth, (y - g.OriginY) / g.PixelHeight
+}
+
+// GeoTransform returns the EPSG:3857 transform for the tile image, this also applies to stitched tiles
+func (t *Tile) GeoTransform() GeoTransform {
+	return MercatorGeoTransform(float64(t.X*t.Size), float64(t.Y*t.Size), t.Level, t.Size)
+}
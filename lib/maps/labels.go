@@ -0,0 +1,206 @@
+/**
+ * go-mapbox Maps Module Labels
+ * Label placement with collision avoidance in global pixel space, so labels are consistent
+ * across individual tiles and stitched tile grids
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"fmt"
+	"image"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// LabelPlacement positions label text relative to its point (or marker)
+type LabelPlacement string
+
+// Label placements
+const (
+	PlaceRight       LabelPlacement = "right"
+	PlaceLeft        LabelPlacement = "left"
+	PlaceTop         LabelPlacement = "top"
+	PlaceBottom      LabelPlacement = "bottom"
+	PlaceTopRight    LabelPlacement = "top-right"
+	PlaceTopLeft     LabelPlacement = "top-left"
+	PlaceBottomRight LabelPlacement = "bottom-right"
+	PlaceBottomLeft  LabelPlacement = "bottom-left"
+)
+
+// DefaultLabelPlacements are the candidate placements tried in order when a label does not provide any
+var DefaultLabelPlacements = []LabelPlacement{
+	PlaceRight, PlaceLeft, PlaceTop, PlaceBottom,
+	PlaceTopRight, PlaceTopLeft, PlaceBottomRight, PlaceBottomLeft,
+}
+
+// DefaultLabelGap is the pixel spacing between a label and its point or marker
+const DefaultLabelGap = 2
+
+// Label is a text label with an optional marker at a location
+type Label struct {
+	Location   base.Location
+	Text       string
+	Style      TextStyle        // Text style, the anchor and offset are set by placement
+	Marker     *Marker          // Optional marker drawn at the location
+	Placements []LabelPlacement // Candidate placements, defaults to DefaultLabelPlacements
+}
+
+type placedLabel struct {
+	label  Label
+	point  Vec             // Global pixel location
+	text   image.Rectangle // Global text box, empty if the text was not placed
+	marker image.Rectangle // Global marker box, empty if there is no marker
+}
+
+// LabelLayer places labels at a zoom level without overlaps
+// Labels are placed in global pixel space then drawn onto any tiles at the same level and size
+type LabelLayer struct {
+	Level   uint64
+	Size    uint64
+	Padding int             // Minimum pixel spacing between placed boxes
+	Bounds  image.Rectangle // Optional global pixel bounds that text must fit within (such as a stitched image)
+
+	placed []placedLabel
+}
+
+// NewLabelLayer creates a label layer for tiles at the provided level and size
+func NewLabelLayer(level, size uint64) *LabelLayer {
+	return &LabelLayer{Level: level, Size: size, placed: make([]placedLabel, 0)}
+}
+
+// NewLabelLayerForTile creates a label layer covering the provided (possibly stitched) tile
+func NewLabelLayerForTile(t *Tile) *LabelLayer {
+	l := NewLabelLayer(t.Level, t.Size)
+	l.Bounds = t.Bounds().Add(image.Point{int(t.X * t.Size), int(t.Y * t.Size)})
+	return l
+}
+
+// collides checks whether a box overlaps any placed box
+func (l *LabelLayer) collides(box image.Rectangle) bool {
+	padded := box.Inset(-l.Padding)
+	for _, p := range l.placed {
+		if !p.text.Empty() && padded.Overlaps(p.text) {
+			return true
+		}
+		if !p.marker.Empty() && padded.Overlaps(p.marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// placementAnchor returns the anchor point and justification for a placement around a reference box
+func placementAnchor(ref image.Rectangle, placement LabelPlacement, gap int) (Vec, DrawConfig, error) {
+	cx, cy := float64(ref.Min.X+ref.Max.X)/2, float64(ref.Min.Y+ref.Max.Y)/2
+	left, right := float64(ref.Min.X-gap), float64(ref.Max.X+gap)
+	top, bottom := float64(ref.Min.Y-gap), float64(ref.Max.Y+gap)
+
+	switch placement {
+	case PlaceRight:
+		return Vec{right, cy}, DrawConfig{Vertical: JustifyCenter, Horizontal: JustifyLeft}, nil
+	case PlaceLeft:
+		return Vec{left, cy}, DrawConfig{Vertical: JustifyCenter, Horizontal: JustifyRight}, nil
+	case PlaceTop:
+		return Vec{cx, top}, DrawConfig{Vertical: JustifyBottom, Horizontal: JustifyCenter}, nil
+	case PlaceBottom:
+		return Vec{cx, bottom}, DrawConfig{Vertical: JustifyTop, Horizontal: JustifyCenter}, nil
+	case PlaceTopRight:
+		return Vec{right, top}, DrawConfig{Vertical: JustifyBottom, Horizontal: JustifyLeft}, nil
+	case PlaceTopLeft:
+		return Vec{left, top}, DrawConfig{Vertical: JustifyBottom, Horizontal: JustifyRight}, nil
+	case PlaceBottomRight:
+		return Vec{right, bottom}, DrawConfig{Vertical: JustifyTop, Horizontal: JustifyLeft}, nil
+	case PlaceBottomLeft:
+		return Vec{left, bottom}, DrawConfig{Vertical: JustifyTop, Horizontal: JustifyRight}, nil
+	default:
+		return Vec{}, DrawConfig{}, fmt.Errorf("Unsupported label placement (%s)", placement)
+	}
+}
+
+// Add places a label, markers are always placed while text is placed at the first candidate
+// position that does not collide with previously placed labels
+// Returns whether the text was placed
+func (l *LabelLayer) Add(label Label) (bool, error) {
+	x, y := MercatorLocationToPixel(label.Location.Latitude, label.Location.Longitude, l.Level, l.Size)
+	p := placedLabel{label: label, point: Vec{x, y}}
+
+	ref := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x)), int(math.Round(y)))
+	if label.Marker != nil {
+		p.marker = label.Marker.Bounds(x, y)
+		ref = p.marker
+	}
+
+	placements := label.Placements
+	if len(placements) == 0 {
+		placements = DefaultLabelPlacements
+	}
+
+	if label.Text != "" {
+		for _, placement := range placements {
+			anchor, config, err := placementAnchor(ref, placement, DefaultLabelGap)
+			if err != nil {
+				return false, err
+			}
+
+			style := label.Style
+			style.Anchor, style.Offset = config, Vec{}
+			box, err := textBox(label.Text, anchor.X, anchor.Y, style)
+			if err != nil {
+				return false, err
+			}
+
+			if !l.Bounds.Empty() && !box.In(l.Bounds) {
+				continue
+			}
+			if l.collides(box) || (!p.marker.Empty() && box.Overlaps(p.marker)) {
+				continue
+			}
+
+			p.text = box
+			p.label.Style = style
+			break
+		}
+	}
+
+	l.placed = append(l.placed, p)
+
+	return !p.text.Empty(), nil
+}
+
+// Draw draws all placed labels onto a tile, which must match the layer level and size
+// Markers are drawn beneath all text
+func (l *LabelLayer) Draw(t *Tile) error {
+	if t.Level != l.Level || t.Size != l.Size {
+		return fmt.Errorf("Tile level and size (%d, %d) do not match label layer (%d, %d)", t.Level, t.Size, l.Level, l.Size)
+	}
+
+	offset := Vec{float64(t.X * t.Size), float64(t.Y * t.Size)}
+
+	for _, p := range l.placed {
+		if p.label.Marker == nil {
+			continue
+		}
+		local := p.point.Sub(offset)
+		if err := t.DrawMarkerLocal(*p.label.Marker, local.X, local.Y); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range l.placed {
+		if p.text.Empty() {
+			continue
+		}
+		style := p.label.Style
+		style.Anchor, style.Offset = DrawConfig{Vertical: JustifyTop, Horizontal: JustifyLeft}, Vec{}
+		if err := t.DrawTextLocal(p.label.Text, float64(p.text.Min.X)-offset.X, float64(p.text.Min.Y)-offset.Y, style); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
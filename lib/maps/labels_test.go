@@ -0,0 +1,174 @@
+/**
+ * go-mapbox Maps Module Text, Marker and Label Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// inkBounds returns the bounds of all non-white pixels in a tile
+func inkBounds(t Tile) image.Rectangle {
+	r := image.Rectangle{}
+	b := t.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if c := color.NRGBAModel.Convert(t.At(x, y)).(color.NRGBA); c.R != 255 || c.G != 255 || c.B != 255 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+func TestText(t *testing.T) {
+	t.Run("Draws anchored text", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 64, 32)
+		size := MeasureText("AB", nil)
+		assert.EqualValues(t, image.Point{14, 13}, size)
+
+		err := tile.DrawTextLocal("AB", 10, 5, TextStyle{Anchor: DrawConfig{Vertical: JustifyTop, Horizontal: JustifyLeft}})
+		assert.Nil(t, err)
+
+		ink := inkBounds(tile)
+		assert.False(t, ink.Empty())
+		assert.True(t, ink.In(image.Rect(10, 5, 10+size.X, 5+size.Y)))
+	})
+
+	t.Run("Draws halos around text", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 64, 32)
+		red := color.NRGBA{R: 255, A: 255}
+		err := tile.DrawTextLocal("AB", 32, 16, TextStyle{HaloColor: red, HaloWidth: 2})
+		assert.Nil(t, err)
+
+		// Text is centred by default and the halo extends beyond the glyphs
+		ink := inkBounds(tile)
+		assert.InDelta(t, 32, (ink.Min.X+ink.Max.X)/2, 2)
+		assert.True(t, ink.Dx() >= 14)
+
+		c := color.NRGBAModel.Convert(tile.At(ink.Min.X, (ink.Min.Y+ink.Max.Y)/2)).(color.NRGBA)
+		assert.True(t, c.R > c.G, "edge pixels should be halo coloured")
+	})
+
+	t.Run("Rejects invalid anchors", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 16, 16)
+		err := tile.DrawTextLocal("A", 0, 0, TextStyle{Anchor: DrawConfig{Vertical: "middle", Horizontal: JustifyLeft}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestMarkers(t *testing.T) {
+	red := color.NRGBA{R: 255, A: 255}
+
+	t.Run("Draws pins with the tip at the location", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 64, 64)
+		m := Marker{Shape: MarkerPin, Color: red, Size: 20, Label: "1"}
+		assert.Nil(t, tile.DrawMarkerLocal(m, 32, 50))
+
+		ink := inkBounds(tile)
+		assert.True(t, ink.In(m.Bounds(32, 50)))
+		assert.InDelta(t, 50, ink.Max.Y, 2)
+		assert.InDelta(t, 50-30, ink.Min.Y, 2)
+
+		// Head is filled with the marker colour and the label is drawn in white
+		c := color.NRGBAModel.Convert(tile.At(26, 30)).(color.NRGBA)
+		assert.EqualValues(t, color.NRGBA{R: 255, A: 255}, c)
+	})
+
+	t.Run("Draws circles and squares centred on the location", func(t *testing.T) {
+		for _, shape := range []MarkerShape{"", MarkerCircle, MarkerSquare} {
+			tile := newBlankTile(0, 0, 1, 64, 64)
+			assert.Nil(t, tile.DrawMarkerLocal(Marker{Shape: shape, Color: red, Size: 16}, 32, 32))
+
+			ink := inkBounds(tile)
+			assert.InDelta(t, 32, (ink.Min.X+ink.Max.X)/2, 1, "shape %s", shape)
+			assert.InDelta(t, 32, (ink.Min.Y+ink.Max.Y)/2, 1, "shape %s", shape)
+		}
+	})
+
+	t.Run("Rejects unknown shapes", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 16, 16)
+		assert.NotNil(t, tile.DrawMarkerLocal(Marker{Shape: "star"}, 8, 8))
+	})
+}
+
+func TestLabelLayer(t *testing.T) {
+	level := uint64(10)
+	loc := base.Location{Latitude: -36.8485, Longitude: 174.7633}
+
+	t.Run("Avoids collisions between labels", func(t *testing.T) {
+		layer := NewLabelLayer(level, SizeStandard)
+
+		ok, err := layer.Add(Label{Location: loc, Text: "Auckland"})
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		// The same label at the same location must move to another placement
+		ok, err = layer.Add(Label{Location: loc, Text: "Auckland"})
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.False(t, layer.placed[0].text.Overlaps(layer.placed[1].text))
+		assert.NotEqual(t, layer.placed[0].text, layer.placed[1].text)
+
+		// Restricting placements means a further label cannot be placed
+		ok, err = layer.Add(Label{Location: loc, Text: "Auckland", Placements: []LabelPlacement{PlaceRight}})
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		_, err = layer.Add(Label{Location: loc, Text: "Auckland", Placements: []LabelPlacement{"middle"}})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Keeps labels within bounds", func(t *testing.T) {
+		x, y := LocationToTileID(loc, level)
+		tile := newBlankTile(x, y, level, 256, 256)
+		layer := NewLabelLayerForTile(&tile)
+
+		// A location at the right edge of the tile must be labelled on the left
+		edge, err := tile.PixelToLocation(254, 128)
+		assert.Nil(t, err)
+		ok, err := layer.Add(Label{Location: *edge, Text: "Edge"})
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.True(t, layer.placed[0].text.Max.X <= int(x*SizeStandard)+254)
+	})
+
+	t.Run("Draws consistently across stitched tiles", func(t *testing.T) {
+		x, y := LocationToTileID(loc, level)
+		layer := NewLabelLayer(level, SizeStandard)
+
+		// Place labels either side of the tile boundary
+		for i, dx := range []float64{-20, 0, 20} {
+			lat, lng := MercatorPixelToLocation(float64((x+1)*SizeStandard)+dx, float64(y*SizeStandard)+100+float64(i)*40, level, SizeStandard)
+			_, err := layer.Add(Label{Location: base.Location{Latitude: lat, Longitude: lng}, Text: "Boundary", Style: TextStyle{HaloColor: color.White, HaloWidth: 1}, Marker: &Marker{Shape: MarkerPin}})
+			assert.Nil(t, err)
+		}
+
+		stitched := newBlankTile(x, y, level, 512, 256)
+		assert.Nil(t, layer.Draw(&stitched))
+
+		for i := uint64(0); i < 2; i++ {
+			tile := newBlankTile(x+i, y, level, 256, 256)
+			assert.Nil(t, layer.Draw(&tile))
+
+			for py := 0; py < 256; py++ {
+				for px := 0; px < 256; px++ {
+					assert.InDelta(t, alphaAt(stitched, int(i)*256+px, py), alphaAt(tile, px, py), 1)
+				}
+			}
+		}
+
+		other := newBlankTile(x, y, level+1, 256, 256)
+		assert.NotNil(t, layer.Draw(&other))
+	})
+}
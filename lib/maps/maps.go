@@ -11,6 +11,7 @@ package maps
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	_ "image/jpeg"
@@ -37,6 +38,16 @@ type Cache interface {
 	Fetch(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) (image.Image, *image.Config, error)
 }
 
+// DataCache is implemented by caches that can store encoded tile data
+// This allows tiles to be served as fetched, without decoding and re-encoding
+type DataCache interface {
+	SaveData(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool, data []byte) error
+	FetchData(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) ([]byte, error)
+}
+
+// ErrorTileNotFound indicates the API has no tile at the requested location
+var ErrorTileNotFound = errors.New("Mapbox API error tile not found")
+
 // Maps api wrapper instance
 type Maps struct {
 	base  *base.Base
@@ -53,39 +64,153 @@ func (m *Maps) SetCache(c Cache) {
 	m.cache = c
 }
 
+// CacheDeleter is implemented by caches supporting tile removal
+// This allows stale tiles to be replaced following a fresh fetch
+type CacheDeleter interface {
+	Delete(mapID MapID, x, y, level uint64, format MapFormat, highDPI bool) error
+}
+
+// CheckFormat catches invalid MapID / MapFormat combinations
+func CheckFormat(mapID MapID, format MapFormat) error {
+	if mapID == MapIDSatellite && strings.Contains(string(format), "png") {
+		return fmt.Errorf("MapIDSatellite does not support png outputs")
+	}
+	if format == MapFormatPngRaw && mapID != MapIDTerrainRGB {
+		return fmt.Errorf("MapFormatPngRaw only supported for MapIDTerrainRGB")
+	}
+	if mapID == MapIDTerrainRGB && format != MapFormatPngRaw {
+		return fmt.Errorf("MapIDTerrainRGB only supports format MapFormatPngRaw")
+	}
+	return nil
+}
+
 // GetTile fetches the map tile for the specified location
 func (m *Maps) GetTile(mapID MapID, x, y, z uint64, format MapFormat, highDPI bool) (*Tile, error) {
+	if err := CheckFormat(mapID, format); err != nil {
+		return nil, err
+	}
 
-	v := url.Values{}
-
-	dpiFlag := ""
 	size := SizeStandard
 	if highDPI {
-		dpiFlag = "@2x"
 		size = SizeHighDPI
 	}
 
-	// Catch invalid MapID / MapFormat combinations here
-	if mapID == MapIDSatellite && strings.Contains(string(format), "png") {
-		return nil, fmt.Errorf("MapIDSatellite does not support png outputs")
+	// Attempt cache lookup if available
+	if m.cache != nil {
+		img, _, err := m.cache.Fetch(mapID, x, y, z, format, highDPI)
+		if err != nil {
+			log.Printf("Cache fetch error (%s)", err)
+		} else if img != nil {
+			tile := NewTile(x, y, z, size, img)
+			return &tile, nil
+		}
 	}
-	if format == MapFormatPngRaw && mapID != MapIDTerrainRGB {
-		return nil, fmt.Errorf("MapFormatPngRaw only supported for MapIDTerrainRGB")
+
+	tile, err := m.FetchTile(mapID, x, y, z, format, highDPI)
+	if err != nil {
+		return nil, err
 	}
-	if mapID == MapIDTerrainRGB && format != MapFormatPngRaw {
-		return nil, fmt.Errorf("MapIDTerrainRGB only supports format MapFormatPngRaw")
+
+	// Save to cache if available
+	// Tile is post RGB conversion (should avoid pngraw issues)
+	if m.cache != nil {
+		err = m.cache.Save(mapID, x, y, z, format, highDPI, tile.Image)
+		if err != nil {
+			log.Printf("Cache save error (%s)", err)
+		}
+	}
+
+	return tile, err
+}
+
+// GetTileData fetches the encoded map tile for the specified location, as served by the API
+// Caches implementing DataCache store tiles as fetched, tiles from other caches are re-encoded
+func (m *Maps) GetTileData(mapID MapID, x, y, z uint64, format MapFormat, highDPI bool) ([]byte, error) {
+	if err := CheckFormat(mapID, format); err != nil {
+		return nil, err
 	}
 
 	// Attempt cache lookup if available
+	dataCache, isDataCache := m.cache.(DataCache)
+	if isDataCache {
+		data, err := dataCache.FetchData(mapID, x, y, z, format, highDPI)
+		if err != nil {
+			log.Printf("Cache fetch error (%s)", err)
+		} else if data != nil {
+			return data, nil
+		}
+	}
 	if m.cache != nil {
 		img, _, err := m.cache.Fetch(mapID, x, y, z, format, highDPI)
 		if err != nil {
 			log.Printf("Cache fetch error (%s)", err)
 		} else if img != nil {
-			tile := NewTile(x, y, z, size, img)
-			return &tile, nil
+			buf := bytes.Buffer{}
+			if err := EncodeImage(&buf, img, format); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		}
+	}
+
+	data, err := m.FetchTileData(mapID, x, y, z, format, highDPI)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save to cache if available
+	if isDataCache {
+		err = dataCache.SaveData(mapID, x, y, z, format, highDPI, data)
+	} else if m.cache != nil {
+		var img image.Image
+		if img, _, err = image.Decode(bytes.NewReader(data)); err == nil {
+			err = m.cache.Save(mapID, x, y, z, format, highDPI, img)
 		}
 	}
+	if err != nil {
+		log.Printf("Cache save error (%s)", err)
+	}
+
+	return data, nil
+}
+
+// FetchTile fetches the map tile for the specified location from the API, bypassing the cache
+// The fetched tile is not saved to the cache
+func (m *Maps) FetchTile(mapID MapID, x, y, z uint64, format MapFormat, highDPI bool) (*Tile, error) {
+	data, err := m.FetchTileData(mapID, x, y, z, format, highDPI)
+	if err != nil {
+		return nil, err
+	}
+
+	size := SizeStandard
+	if highDPI {
+		size = SizeHighDPI
+	}
+
+	// Convert to image
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// Create tile
+	tile := NewTile(x, y, z, size, img)
+
+	return &tile, nil
+}
+
+// FetchTileData fetches the encoded map tile for the specified location from the API, bypassing the cache
+func (m *Maps) FetchTileData(mapID MapID, x, y, z uint64, format MapFormat, highDPI bool) ([]byte, error) {
+	if err := CheckFormat(mapID, format); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+
+	dpiFlag := ""
+	if highDPI {
+		dpiFlag = "@2x"
+	}
 
 	// Create Request
 	queryString := fmt.Sprintf("%s/%s/%d/%d/%d%s.%s", apiVersion, mapID, z, x, y, dpiFlag, format)
@@ -94,13 +219,17 @@ func (m *Maps) GetTile(mapID MapID, x, y, z uint64, format MapFormat, highDPI bo
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrorTileNotFound
+	}
 
 	// Parse content type and length
 	contentType := resp.Header.Get("Content-Type")
 	contentLength := resp.ContentLength
 
 	// Read data from body
-	defer resp.Body.Close()
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("Error reading response body (%s)", err)
@@ -113,37 +242,25 @@ func (m *Maps) GetTile(mapID MapID, x, y, z uint64, format MapFormat, highDPI bo
 		return nil, fmt.Errorf("Invalid API call: %s message: %s", resp.Request.URL, string(data))
 	}
 
-	// Decode config
+	// Decode config to check the response is an image
 	_, _, err = image.DecodeConfig(bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert to image
-	img, _, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		return nil, err
-	}
-
-	// Create tile
-	tile := NewTile(x, y, z, size, img)
-
-	// Save to cache if available
-	// Tile is post RGB conversion (should avoid pngraw issues)
-	if m.cache != nil {
-		err = m.cache.Save(mapID, x, y, z, format, highDPI, img)
-		if err != nil {
-			log.Printf("Cache save error (%s)", err)
-		}
-	}
-
-	return &tile, err
+	return data, nil
 }
 
 // GetEnclosingTiles fetches a 2d array of the tiles enclosing a given point
 func (m *Maps) GetEnclosingTiles(mapID MapID, a, b base.Location, level uint64, format MapFormat, highDPI bool) ([][]Tile, error) {
 	// Convert to tile locations
 	xStart, yStart, xEnd, yEnd := GetEnclosingTileIDs(a, b, level)
+
+	return m.getTileRange(mapID, xStart, yStart, xEnd, yEnd, level, format, highDPI)
+}
+
+// getTileRange fetches a 2d array of tiles between (inclusive) tile IDs, wrapping IDs past the edge of the world
+func (m *Maps) getTileRange(mapID MapID, xStart, yStart, xEnd, yEnd, level uint64, format MapFormat, highDPI bool) ([][]Tile, error) {
 	xLen := xEnd - xStart + 1
 	yLen := yEnd - yStart + 1
 
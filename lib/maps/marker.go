@@ -0,0 +1,153 @@
+/**
+ * go-mapbox Maps Module Markers
+ * Stock marker glyphs with optional labels
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// MarkerShape selects the marker glyph
+type MarkerShape string
+
+// Marker shapes
+const (
+	MarkerPin    MarkerShape = "pin"    // Teardrop pin with the tip at the marker location
+	MarkerCircle MarkerShape = "circle" // Circle centred on the marker location
+	MarkerSquare MarkerShape = "square" // Square centred on the marker location
+)
+
+// DefaultMarkerSize is the marker width in pixels used when a marker does not provide one
+const DefaultMarkerSize = 20.0
+
+// pinHeight is the height of pin markers as a multiple of their width
+const pinHeight = 1.5
+
+// Marker configures a stock marker glyph
+type Marker struct {
+	Shape      MarkerShape // Marker glyph, defaults to MarkerCircle
+	Color      color.Color // Fill colour, defaults to black
+	Outline    color.Color // Outline colour, defaults to white
+	Size       float64     // Marker width in pixels, defaults to DefaultMarkerSize
+	Label      string      // Optional label (such as a number) drawn inside the marker
+	LabelStyle *TextStyle  // Optional label style, defaults to white text in DefaultFace
+}
+
+func (m *Marker) size() float64 {
+	if m.Size <= 0 {
+		return DefaultMarkerSize
+	}
+	return m.Size
+}
+
+func (m *Marker) shape() MarkerShape {
+	if m.Shape == "" {
+		return MarkerCircle
+	}
+	return m.Shape
+}
+
+func (m *Marker) outlineWidth() float64 {
+	return math.Max(1, m.size()/12)
+}
+
+// center returns the centre of the marker head for a marker drawn at x/y
+func (m *Marker) center(x, y float64) Vec {
+	if m.Shape == MarkerPin {
+		return Vec{x, y - m.size()*pinHeight + m.size()/2}
+	}
+	return Vec{x, y}
+}
+
+// outline returns the marker outline ring for a marker drawn at x/y
+func (m *Marker) outline(x, y float64) ([]Vec, error) {
+	s := m.size()
+	r := s / 2
+	c := m.center(x, y)
+
+	switch m.shape() {
+	case MarkerCircle:
+		return circleRing(c, r), nil
+	case MarkerSquare:
+		return []Vec{{c.X - r, c.Y - r}, {c.X + r, c.Y - r}, {c.X + r, c.Y + r}, {c.X - r, c.Y + r}}, nil
+	case MarkerPin:
+		// Arc around the head between the tangent points from the tip, then back to the tip
+		tip := Vec{x, y}
+		beta := math.Acos(r / (tip.Y - c.Y))
+		n := int(math.Min(128, math.Max(8, math.Ceil(s*2))))
+		start, end := math.Pi/2-beta, -3*math.Pi/2+beta
+
+		ring := []Vec{tip}
+		for i := 0; i <= n; i++ {
+			a := start + (end-start)*float64(i)/float64(n)
+			ring = append(ring, Vec{c.X + r*math.Cos(a), c.Y + r*math.Sin(a)})
+		}
+		return ring, nil
+	default:
+		return nil, fmt.Errorf("Unsupported marker shape (%s)", m.Shape)
+	}
+}
+
+// Bounds returns the pixel box covered by a marker drawn at x/y
+func (m *Marker) Bounds(x, y float64) image.Rectangle {
+	s, w := m.size(), m.outlineWidth()/2
+	c := m.center(x, y)
+
+	top, bottom := c.Y-s/2, c.Y+s/2
+	if m.Shape == MarkerPin {
+		bottom = y
+	}
+
+	return image.Rect(
+		int(math.Floor(c.X-s/2-w)), int(math.Floor(top-w)),
+		int(math.Ceil(c.X+s/2+w)), int(math.Ceil(bottom+w)),
+	)
+}
+
+// DrawMarkerLocal draws a marker at the local X/Y coordinates
+func (t *Tile) DrawMarkerLocal(m Marker, x, y float64) error {
+	ring, err := m.outline(x, y)
+	if err != nil {
+		return err
+	}
+
+	fill, outline := m.Color, m.Outline
+	if fill == nil {
+		fill = color.Black
+	}
+	if outline == nil {
+		outline = color.White
+	}
+
+	t.FillLocal([][]Vec{ring}, fill)
+	t.StrokeLocal(ring, true, StrokeStyle{Color: outline, Width: m.outlineWidth(), Join: LineJoinRound})
+
+	if m.Label == "" {
+		return nil
+	}
+
+	style := TextStyle{Color: color.White}
+	if m.LabelStyle != nil {
+		style = *m.LabelStyle
+	}
+	style.Anchor = Center
+
+	c := m.center(x, y)
+	return t.DrawTextLocal(m.Label, c.X, c.Y, style)
+}
+
+// DrawMarker draws a marker at the provided location
+func (t *Tile) DrawMarker(loc base.Location, m Marker) error {
+	p := t.LocationToLocal(loc)
+	return t.DrawMarkerLocal(m, p.X, p.Y)
+}
@@ -1,6 +1,6 @@
 /**
  * go-mapbox Maps Module
- * Naive Spherical Mercator (EPSG:3857) Implementation
+ * Spherical Mercator (EPSG:3857) Implementation with integer and fractional zoom levels
  * See http://wiki.openstreetmap.org/wiki/Slippy_map_tilenames for examples
  *
  * https://github.com/tumasgiu/go-mapbox
@@ -19,15 +19,29 @@ const (
 	// R2D helper for converting radians to degrees
 	R2D = 180 / math.Pi
 
+	// MercatorRadius is the sphere radius used by the spherical mercator (EPSG:3857) projection
+	MercatorRadius = 6378137.0
+
 	pi = math.Pi
 )
 
+// ClampLatitude limits a latitude (in degrees) to the +/- MaxLatitude range of the mercator projection
+func ClampLatitude(lat float64) float64 {
+	return math.Max(math.Min(lat, MaxLatitude), -MaxLatitude)
+}
+
 // MercatorLocationToPixel converts a lat/lng/zoom location (in degrees) to a pixel location in the global space
 func MercatorLocationToPixel(lat, lng float64, zoom, size uint64) (float64, float64) {
-	latRad, lngRad := lat*D2R, lng*D2R
-	fsize := float64(size)
-	x := (fsize / 2 / pi) * math.Pow(2, float64(zoom)) * (lngRad + pi)
-	y := (fsize / 2 / pi) * math.Pow(2, float64(zoom)) * (pi - math.Log(math.Tan(pi/4+latRad/2)))
+	return MercatorLocationToPixelF(lat, lng, float64(zoom), size)
+}
+
+// MercatorLocationToPixelF converts a lat/lng location (in degrees) at a fractional zoom to a pixel location
+// in the global space. Latitudes are clamped to the limits of the projection
+func MercatorLocationToPixelF(lat, lng, zoom float64, size uint64) (float64, float64) {
+	latRad, lngRad := ClampLatitude(lat)*D2R, lng*D2R
+	scale := float64(size) / 2 / pi * math.Exp2(zoom)
+	x := scale * (lngRad + pi)
+	y := scale * (pi - math.Log(math.Tan(pi/4+latRad/2)))
 	return x, y
 }
 
@@ -41,8 +55,38 @@ func MercatorLocationToTileID(lat, lng float64, zoom, size uint64) (uint64, uint
 
 // MercatorPixelToLocation converts a given (global) pixel location and zoom level to a lat and lng (in degrees)
 func MercatorPixelToLocation(x, y float64, zoom, size uint64) (float64, float64) {
-	fsize := float64(size)
-	lng := x*(pi/fsize*2)/math.Pow(2, float64(zoom)) - pi
-	lat := (math.Atan(math.Pow(math.E, (-y*(pi/fsize*2)/math.Pow(2, float64(zoom))+pi))) - pi/4) * 2
+	return MercatorPixelToLocationF(x, y, float64(zoom), size)
+}
+
+// MercatorPixelToLocationF converts a given (global) pixel location at a fractional zoom to a lat and lng (in degrees)
+func MercatorPixelToLocationF(x, y, zoom float64, size uint64) (float64, float64) {
+	scale := float64(size) / 2 / pi * math.Exp2(zoom)
+	lng := x/scale - pi
+	lat := 2*math.Atan(math.Exp(pi-y/scale)) - pi/2
 	return lat * R2D, lng * R2D
 }
+
+// MercatorLocationToMeters converts a lat/lng location (in degrees) to EPSG:3857 coordinates in metres
+// Latitudes are clamped to the limits of the projection
+func MercatorLocationToMeters(lat, lng float64) (float64, float64) {
+	x := MercatorRadius * lng * D2R
+	y := MercatorRadius * math.Log(math.Tan(pi/4+ClampLatitude(lat)*D2R/2))
+	return x, y
+}
+
+// MercatorMetersToLocation converts EPSG:3857 coordinates in metres to a lat and lng (in degrees)
+func MercatorMetersToLocation(x, y float64) (float64, float64) {
+	lng := x / MercatorRadius
+	lat := 2*math.Atan(math.Exp(y/MercatorRadius)) - pi/2
+	return lat * R2D, lng * R2D
+}
+
+// MercatorResolution returns the size of a global pixel in EPSG:3857 metres at the provided zoom and tile size
+func MercatorResolution(zoom, size uint64) float64 {
+	return MercatorResolutionF(float64(zoom), size)
+}
+
+// MercatorResolutionF returns the size of a global pixel in EPSG:3857 metres at a fractional zoom and tile size
+func MercatorResolutionF(zoom float64, size uint64) float64 {
+	return 2 * pi * MercatorRadius / (float64(size) * math.Exp2(zoom))
+}
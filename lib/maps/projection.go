@@ -0,0 +1,193 @@
+/**
+ * go-mapbox Maps Module Projections
+ * Conversions between WGS84 locations and projected coordinate systems
+ * Supports geographic (EPSG:4326), spherical mercator (EPSG:3857) and UTM (EPSG:326xx / EPSG:327xx)
+ * UTM uses the third order Krüger series, see https://en.wikipedia.org/wiki/Universal_Transverse_Mercator_coordinate_system
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"fmt"
+	"math"
+)
+
+// Projection converts between lat/lng locations (in degrees) and projected coordinates
+type Projection interface {
+	// Code returns the EPSG code of the projection, eg. EPSG:3857
+	Code() string
+	// Project converts a lat/lng location to projected x/y coordinates
+	Project(lat, lng float64) (float64, float64)
+	// Unproject converts projected x/y coordinates to a lat/lng location
+	Unproject(x, y float64) (float64, float64)
+}
+
+// Geographic is the WGS84 geographic (EPSG:4326) coordinate system, with x as longitude and y as latitude
+type Geographic struct{}
+
+// Code returns the EPSG code of the projection
+func (Geographic) Code() string { return "EPSG:4326" }
+
+// Project converts a lat/lng location to lng/lat coordinates
+func (Geographic) Project(lat, lng float64) (float64, float64) { return lng, lat }
+
+// Unproject converts lng/lat coordinates to a lat/lng location
+func (Geographic) Unproject(x, y float64) (float64, float64) { return y, x }
+
+// WebMercator is the spherical mercator (EPSG:3857) projection in metres
+// Latitudes are clamped to +/- MaxLatitude
+type WebMercator struct{}
+
+// Code returns the EPSG code of the projection
+func (WebMercator) Code() string { return "EPSG:3857" }
+
+// Project converts a lat/lng location to EPSG:3857 metres
+func (WebMercator) Project(lat, lng float64) (float64, float64) {
+	return MercatorLocationToMeters(lat, lng)
+}
+
+// Unproject converts EPSG:3857 metres to a lat/lng location
+func (WebMercator) Unproject(x, y float64) (float64, float64) { return MercatorMetersToLocation(x, y) }
+
+// WGS84 ellipsoid parameters
+const (
+	WGS84SemiMajorAxis = 6378137.0
+	WGS84Flattening    = 1 / 298.257223563
+)
+
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0
+	utmMinLatitude   = -80.0
+	utmMaxLatitude   = 84.0
+)
+
+// Krüger series coefficients for the WGS84 ellipsoid
+var utmN, utmA, utmAlpha, utmBeta, utmDelta = func() (float64, float64, [3]float64, [3]float64, [3]float64) {
+	n := WGS84Flattening / (2 - WGS84Flattening)
+	n2, n3 := n*n, n*n*n
+	a := WGS84SemiMajorAxis / (1 + n) * (1 + n2/4 + n2*n2/64)
+	alpha := [3]float64{n/2 - 2*n2/3 + 5*n3/16, 13*n2/48 - 3*n3/5, 61 * n3 / 240}
+	beta := [3]float64{n/2 - 2*n2/3 + 37*n3/96, n2/48 + n3/15, 17 * n3 / 480}
+	delta := [3]float64{2*n - 2*n2/3 - 2*n3, 7*n2/3 - 8*n3/5, 56 * n3 / 15}
+	return n, a, alpha, beta, delta
+}()
+
+// UTM is a Universal Transverse Mercator zone on the WGS84 ellipsoid, with coordinates in metres
+type UTM struct {
+	Zone  int  // Zone number from 1 to 60
+	South bool // Southern hemisphere (false northing of 10,000km)
+}
+
+// NewUTM creates a UTM projection for the provided zone and hemisphere
+func NewUTM(zone int, south bool) (*UTM, error) {
+	if zone < 1 || zone > 60 {
+		return nil, fmt.Errorf("UTM zone must be between 1 and 60 (received %d)", zone)
+	}
+	return &UTM{Zone: zone, South: south}, nil
+}
+
+// UTMForLocation returns the UTM zone containing a lat/lng location, including the Norway and Svalbard exceptions
+func UTMForLocation(lat, lng float64) (*UTM, error) {
+	if lat < utmMinLatitude || lat > utmMaxLatitude {
+		return nil, fmt.Errorf("UTM is only defined between %.0f and %.0f latitude (received %f)", utmMinLatitude, utmMaxLatitude, lat)
+	}
+
+	lng = math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+	zone := int((lng+180)/6) + 1
+	if zone > 60 {
+		zone = 60
+	}
+
+	switch {
+	case lat >= 56 && lat < 64 && lng >= 3 && lng < 12:
+		zone = 32
+	case lat >= 72:
+		switch {
+		case lng >= 0 && lng < 9:
+			zone = 31
+		case lng >= 9 && lng < 21:
+			zone = 33
+		case lng >= 21 && lng < 33:
+			zone = 35
+		case lng >= 33 && lng < 42:
+			zone = 37
+		}
+	}
+
+	return &UTM{Zone: zone, South: lat < 0}, nil
+}
+
+// Code returns the EPSG code of the projection
+func (u *UTM) Code() string {
+	if u.South {
+		return fmt.Sprintf("EPSG:327%02d", u.Zone)
+	}
+	return fmt.Sprintf("EPSG:326%02d", u.Zone)
+}
+
+// centralMeridian returns the central meridian of the zone in degrees
+func (u *UTM) centralMeridian() float64 {
+	return float64(u.Zone)*6 - 183
+}
+
+// Project converts a lat/lng location to UTM easting and northing in metres
+func (u *UTM) Project(lat, lng float64) (float64, float64) {
+	phi := lat * D2R
+	lambda := math.Remainder(lng-u.centralMeridian(), 360) * D2R
+
+	c := 2 * math.Sqrt(utmN) / (1 + utmN)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - c*math.Atanh(c*math.Sin(phi)))
+	xi := math.Atan2(t, math.Cos(lambda))
+	eta := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+
+	e, n := eta, xi
+	for j, a := range utmAlpha {
+		k := 2 * float64(j+1)
+		e += a * math.Cos(k*xi) * math.Sinh(k*eta)
+		n += a * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+
+	x := utmFalseEasting + utmScale*utmA*e
+	y := utmScale * utmA * n
+	if u.South {
+		y += utmFalseNorthing
+	}
+	return x, y
+}
+
+// Unproject converts UTM easting and northing in metres to a lat/lng location
+func (u *UTM) Unproject(x, y float64) (float64, float64) {
+	if u.South {
+		y -= utmFalseNorthing
+	}
+	xi := y / (utmScale * utmA)
+	eta := (x - utmFalseEasting) / (utmScale * utmA)
+
+	xiP, etaP := xi, eta
+	for j, b := range utmBeta {
+		k := 2 * float64(j+1)
+		xiP -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaP -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+
+	chi := math.Asin(math.Sin(xiP) / math.Cosh(etaP))
+	phi := chi
+	for j, d := range utmDelta {
+		phi += d * math.Sin(2*float64(j+1)*chi)
+	}
+	lambda := math.Atan2(math.Sinh(etaP), math.Cos(xiP))
+
+	lng := math.Remainder(u.centralMeridian()+lambda*R2D, 360)
+	return phi * R2D, lng
+}
+
+// Transform converts projected coordinates between two projections
+func Transform(from, to Projection, x, y float64) (float64, float64) {
+	lat, lng := from.Unproject(x, y)
+	return to.Project(lat, lng)
+}
@@ -0,0 +1,129 @@
+/**
+ * go-mapbox Maps Module Projection Tests
+ * Includes property based round trip tests using testing/quick
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"math"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// scaled maps a random seed onto the range [min, max]
+func scaled(seed uint32, min, max float64) float64 {
+	return min + float64(seed)/math.MaxUint32*(max-min)
+}
+
+func TestProjections(t *testing.T) {
+	t.Run("Round trips fractional zoom mercator pixels", func(t *testing.T) {
+		f := func(latSeed, lngSeed, zoomSeed uint32, highDPI bool) bool {
+			lat, lng, zoom := scaled(latSeed, -MaxLatitude, MaxLatitude), scaled(lngSeed, -180, 180), scaled(zoomSeed, 0, 22)
+			size := SizeStandard
+			if highDPI {
+				size = SizeHighDPI
+			}
+			x, y := MercatorLocationToPixelF(lat, lng, zoom, size)
+			lat2, lng2 := MercatorPixelToLocationF(x, y, zoom, size)
+			return math.Abs(lat-lat2) < 1e-9 && math.Abs(lng-lng2) < 1e-9
+		}
+		assert.Nil(t, quick.Check(f, nil))
+	})
+
+	t.Run("Matches integer zoom mercator pixels", func(t *testing.T) {
+		f := func(latSeed, lngSeed uint32, zoom uint8) bool {
+			lat, lng, z := scaled(latSeed, -MaxLatitude, MaxLatitude), scaled(lngSeed, -180, 180), uint64(zoom%23)
+			x1, y1 := MercatorLocationToPixel(lat, lng, z, SizeStandard)
+			x2, y2 := MercatorLocationToPixelF(lat, lng, float64(z), SizeStandard)
+
+			// Half a zoom level scales pixels by sqrt(2)
+			x3, y3 := MercatorLocationToPixelF(lat, lng, float64(z)+0.5, SizeStandard)
+			return x1 == x2 && y1 == y2 && math.Abs(x3/x1-math.Sqrt2) < 1e-9 && math.Abs(y3/y1-math.Sqrt2) < 1e-9
+		}
+		assert.Nil(t, quick.Check(f, nil))
+	})
+
+	t.Run("Clamps latitudes", func(t *testing.T) {
+		x, y := MercatorLocationToPixelF(90, 0, 0, SizeStandard)
+		assert.InDelta(t, 128, x, delta)
+		assert.InDelta(t, 0, y, delta)
+
+		_, y = MercatorLocationToPixelF(-90, 0, 0, SizeStandard)
+		assert.InDelta(t, 256, y, delta)
+
+		_, my := MercatorLocationToMeters(89, 0)
+		assert.InDelta(t, MercatorRadius*math.Pi, my, 1e-6)
+	})
+
+	t.Run("Round trips projections", func(t *testing.T) {
+		for _, p := range []Projection{Geographic{}, WebMercator{}} {
+			f := func(latSeed, lngSeed uint32) bool {
+				lat, lng := scaled(latSeed, -MaxLatitude, MaxLatitude), scaled(lngSeed, -180, 180)
+				lat2, lng2 := p.Unproject(p.Project(lat, lng))
+				return math.Abs(lat-lat2) < 1e-9 && math.Abs(lng-lng2) < 1e-9
+			}
+			assert.Nil(t, quick.Check(f, nil), p.Code())
+		}
+
+		f := func(latSeed, lngSeed uint32) bool {
+			lat, lng := scaled(latSeed, utmMinLatitude, utmMaxLatitude), scaled(lngSeed, -180, 180)
+			u, err := UTMForLocation(lat, lng)
+			if err != nil {
+				return false
+			}
+			lat2, lng2 := u.Unproject(u.Project(lat, lng))
+			return math.Abs(lat-lat2) < 1e-8 && math.Abs(lng-lng2) < 1e-8
+		}
+		assert.Nil(t, quick.Check(f, nil), "UTM")
+	})
+
+	t.Run("Projects UTM coordinates", func(t *testing.T) {
+		// Eiffel Tower, 31U 448253 5411939 (as computed by Snyder's transverse mercator series)
+		u, err := UTMForLocation(48.85826, 2.294516)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "EPSG:32631", u.Code())
+		x, y := u.Project(48.85826, 2.294516)
+		assert.InDelta(t, 448253.03, x, 0.01)
+		assert.InDelta(t, 5411939.34, y, 0.01)
+
+		// Central meridian on the equator
+		x, y = u.Project(0, 3)
+		assert.InDelta(t, 500000, x, 1e-6)
+		assert.InDelta(t, 0, y, 1e-6)
+
+		// Southern hemisphere zones use a false northing
+		u, err = UTMForLocation(-41.2865, 174.7762)
+		assert.Nil(t, err)
+		assert.EqualValues(t, "EPSG:32760", u.Code())
+		_, y = u.Project(-41.2865, 174.7762)
+		assert.True(t, y > 5000000 && y < 10000000)
+
+		// Norway and Svalbard exceptions
+		u, _ = UTMForLocation(60, 5)
+		assert.EqualValues(t, 32, u.Zone)
+		u, _ = UTMForLocation(78, 15)
+		assert.EqualValues(t, 33, u.Zone)
+
+		_, err = UTMForLocation(85, 0)
+		assert.NotNil(t, err)
+		_, err = NewUTM(61, false)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Transforms between projections", func(t *testing.T) {
+		x, y := Transform(Geographic{}, WebMercator{}, 180, 0)
+		assert.InDelta(t, MercatorRadius*math.Pi, x, 1e-6)
+		assert.InDelta(t, 0, y, 1e-6)
+
+		u, _ := NewUTM(31, false)
+		lng, lat := Transform(u, Geographic{}, 500000, 0)
+		assert.InDelta(t, 3, lng, 1e-9)
+		assert.InDelta(t, 0, lat, 1e-9)
+	})
+}
@@ -0,0 +1,263 @@
+/**
+ * go-mapbox Maps Module Rasteriser
+ * Anti-aliased polygon rasterisation using signed area coverage accumulation
+ * See https://medium.com/@raphlinus/inside-the-fastest-font-renderer-in-the-world-75ae5270c445 for the approach
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Vec is a point in local (image) pixel space
+type Vec struct {
+	X, Y float64
+}
+
+// Add returns the sum of two vectors
+func (v Vec) Add(o Vec) Vec { return Vec{v.X + o.X, v.Y + o.Y} }
+
+// Sub returns the difference of two vectors
+func (v Vec) Sub(o Vec) Vec { return Vec{v.X - o.X, v.Y - o.Y} }
+
+// Mul scales a vector
+func (v Vec) Mul(s float64) Vec { return Vec{v.X * s, v.Y * s} }
+
+// Dot returns the dot product of two vectors
+func (v Vec) Dot(o Vec) float64 { return v.X*o.X + v.Y*o.Y }
+
+// Len returns the length of a vector
+func (v Vec) Len() float64 { return math.Hypot(v.X, v.Y) }
+
+// Norm returns the unit vector in the direction of v
+func (v Vec) Norm() Vec {
+	l := v.Len()
+	if l == 0 {
+		return Vec{}
+	}
+	return Vec{v.X / l, v.Y / l}
+}
+
+// rasterizer accumulates signed area coverage of polygon edges over a region of an image
+// Overlapping rings with the same orientation combine (non-zero), rings with opposite orientation cut holes
+type rasterizer struct {
+	region image.Rectangle
+	w, h   int
+	acc    []float64
+}
+
+func newRasterizer(region image.Rectangle) *rasterizer {
+	w, h := region.Dx(), region.Dy()
+	return &rasterizer{
+		region: region,
+		w:      w,
+		h:      h,
+		acc:    make([]float64, (w+2)*h),
+	}
+}
+
+// pathBounds returns the integer bounds of a set of rings clipped to the provided rectangle
+func pathBounds(rings [][]Vec, clip image.Rectangle) image.Rectangle {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, ring := range rings {
+		for _, p := range ring {
+			minX, minY = math.Min(minX, p.X), math.Min(minY, p.Y)
+			maxX, maxY = math.Max(maxX, p.X), math.Max(maxY, p.Y)
+		}
+	}
+	if math.IsInf(minX, 0) {
+		return image.Rectangle{}
+	}
+
+	// Guard against overflow when converting far off-image points
+	limit := func(v float64) int {
+		return int(math.Max(-1<<30, math.Min(1<<30, v)))
+	}
+
+	r := image.Rect(limit(math.Floor(minX)), limit(math.Floor(minY)), limit(math.Ceil(maxX))+1, limit(math.Ceil(maxY))+1)
+	return r.Intersect(clip)
+}
+
+// addRing adds the edges of a closed ring in image pixel space
+func (r *rasterizer) addRing(ring []Vec) {
+	for i := range ring {
+		r.addLine(ring[i], ring[(i+1)%len(ring)])
+	}
+}
+
+// addLine adds an edge, splitting it at the left and right edges of the region so it can be clamped
+// Clamping preserves coverage, edges left of the region cover the entire row and edges to the right cover nothing
+func (r *rasterizer) addLine(a, b Vec) {
+	origin := Vec{float64(r.region.Min.X), float64(r.region.Min.Y)}
+	a, b = a.Sub(origin), b.Sub(origin)
+
+	ts := []float64{0}
+	for _, cx := range []float64{0, float64(r.w)} {
+		if (a.X < cx) != (b.X < cx) {
+			ts = append(ts, (cx-a.X)/(b.X-a.X))
+		}
+	}
+	if len(ts) == 3 && ts[2] < ts[1] {
+		ts[1], ts[2] = ts[2], ts[1]
+	}
+	ts = append(ts, 1)
+
+	clamp := func(p Vec) Vec {
+		p.X = math.Max(0, math.Min(float64(r.w), p.X))
+		return p
+	}
+
+	for i := 0; i < len(ts)-1; i++ {
+		p0 := a.Add(b.Sub(a).Mul(ts[i]))
+		p1 := a.Add(b.Sub(a).Mul(ts[i+1]))
+		r.accumulate(clamp(p0), clamp(p1))
+	}
+}
+
+// accumulate adds the signed area contribution of a line to the accumulation buffer
+func (r *rasterizer) accumulate(p0, p1 Vec) {
+	if p0.Y == p1.Y {
+		return
+	}
+
+	dir := 1.0
+	if p0.Y > p1.Y {
+		dir = -1.0
+		p0, p1 = p1, p0
+	}
+	if p1.Y <= 0 || p0.Y >= float64(r.h) {
+		return
+	}
+
+	dxdy := (p1.X - p0.X) / (p1.Y - p0.Y)
+	x := p0.X
+	if p0.Y < 0 {
+		x -= p0.Y * dxdy
+	}
+
+	stride := r.w + 2
+	yStart := int(math.Max(0, math.Floor(p0.Y)))
+	yEnd := int(math.Min(float64(r.h), math.Ceil(p1.Y)))
+
+	for y := yStart; y < yEnd; y++ {
+		line := r.acc[y*stride : (y+1)*stride]
+
+		dy := math.Min(float64(y+1), p1.Y) - math.Max(float64(y), p0.Y)
+		xNext := math.Max(0, math.Min(float64(r.w), x+dxdy*dy))
+		d := dy * dir
+
+		x0, x1 := x, xNext
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		x0Floor := math.Floor(x0)
+		x0i := int(x0Floor)
+		x1Ceil := math.Ceil(x1)
+		x1i := int(x1Ceil)
+
+		if x1i <= x0i+1 {
+			// Line stays within a single pixel column
+			xmf := 0.5*(x+xNext) - x0Floor
+			line[x0i] += d - d*xmf
+			line[x0i+1] += d * xmf
+		} else {
+			s := 1 / (x1 - x0)
+			x0f := x0 - x0Floor
+			a0 := 0.5 * s * (1 - x0f) * (1 - x0f)
+			x1f := x1 - x1Ceil + 1
+			am := 0.5 * s * x1f * x1f
+
+			line[x0i] += d * a0
+			if x1i == x0i+2 {
+				line[x0i+1] += d * (1 - a0 - am)
+			} else {
+				a1 := s * (1.5 - x0f)
+				line[x0i+1] += d * (a1 - a0)
+				for xi := x0i + 2; xi < x1i-1; xi++ {
+					line[xi] += d * s
+				}
+				a2 := a1 + float64(x1i-x0i-3)*s
+				line[x1i-1] += d * (1 - a2 - am)
+			}
+			line[x1i] += d * am
+		}
+
+		x = xNext
+	}
+}
+
+// mask resolves accumulated coverage into an alpha mask covering the region
+func (r *rasterizer) mask() *image.Alpha {
+	m := image.NewAlpha(r.region)
+	stride := r.w + 2
+
+	for y := 0; y < r.h; y++ {
+		sum := 0.0
+		for x := 0; x < r.w; x++ {
+			sum += r.acc[y*stride+x]
+			coverage := math.Min(1, math.Abs(sum))
+			m.Pix[y*m.Stride+x] = uint8(coverage*255 + 0.5)
+		}
+	}
+
+	return m
+}
+
+// signedArea computes the signed area of a ring (positive for clockwise rings in image space)
+func signedArea(ring []Vec) float64 {
+	area := 0.0
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// orient returns a copy of the ring with the requested orientation
+func orient(ring []Vec, clockwise bool) []Vec {
+	out := make([]Vec, len(ring))
+	if (signedArea(ring) >= 0) == clockwise {
+		copy(out, ring)
+		return out
+	}
+	for i := range ring {
+		out[i] = ring[len(ring)-1-i]
+	}
+	return out
+}
+
+// fillMask rasterises rings into an alpha mask clipped to the provided bounds
+// Rings are reoriented so the first ring is filled and subsequent rings are holes when holes is set,
+// otherwise all rings are combined
+func fillMask(rings [][]Vec, clip image.Rectangle, holes bool) *image.Alpha {
+	region := pathBounds(rings, clip)
+	if region.Empty() {
+		return nil
+	}
+
+	r := newRasterizer(region)
+	for i, ring := range rings {
+		if len(ring) < 3 {
+			continue
+		}
+		r.addRing(orient(ring, !holes || i == 0))
+	}
+
+	return r.mask()
+}
+
+// drawMask alpha blends a colour through a mask onto an image
+func drawMask(dst draw.Image, mask *image.Alpha, c color.Color) {
+	if mask == nil || c == nil {
+		return
+	}
+	draw.DrawMask(dst, mask.Bounds(), image.NewUniform(c), image.Point{}, mask, mask.Bounds().Min, draw.Over)
+}
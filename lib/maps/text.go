@@ -0,0 +1,160 @@
+/**
+ * go-mapbox Maps Module Text Drawing
+ * Text rendering with halos and anchors on tiles and stitched tiles
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// DefaultFace is the bundled font face used when a text style does not provide one
+var DefaultFace font.Face = basicfont.Face7x13
+
+// TextStyle configures text drawing
+type TextStyle struct {
+	Face      font.Face   // Font face, defaults to DefaultFace
+	Color     color.Color // Text colour, defaults to black
+	HaloColor color.Color // Optional halo (outline) colour
+	HaloWidth int         // Halo width in pixels
+	Anchor    DrawConfig  // Justification of the text box about the drawing point, defaults to Center
+	Offset    Vec         // Pixel offset applied to the drawing point
+}
+
+func (s *TextStyle) face() font.Face {
+	if s.Face == nil {
+		return DefaultFace
+	}
+	return s.Face
+}
+
+func (s *TextStyle) halo() int {
+	if s.HaloColor == nil || s.HaloWidth < 0 {
+		return 0
+	}
+	return s.HaloWidth
+}
+
+func (s *TextStyle) anchor() DrawConfig {
+	if s.Anchor == (DrawConfig{}) {
+		return Center
+	}
+	return s.Anchor
+}
+
+// MeasureText returns the size in pixels of a line of text in the provided face (excluding any halo)
+func MeasureText(text string, face font.Face) image.Point {
+	if face == nil {
+		face = DefaultFace
+	}
+	m := face.Metrics()
+	return image.Point{
+		X: font.MeasureString(face, text).Ceil(),
+		Y: (m.Ascent + m.Descent).Ceil(),
+	}
+}
+
+// textBox returns the pixel box covered by text (including halo) drawn at x/y with the provided style
+func textBox(text string, x, y float64, style TextStyle) (image.Rectangle, error) {
+	halo := style.halo()
+	size := MeasureText(text, style.face()).Add(image.Point{2 * halo, 2 * halo})
+
+	px := int(math.Round(x + style.Offset.X))
+	py := int(math.Round(y + style.Offset.Y))
+
+	dp, err := justify(size, px, py, style.anchor())
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+
+	return image.Rectangle{dp, dp.Add(size)}, nil
+}
+
+// textMask renders text into an alpha mask covering the provided box, offset by the halo width
+func textMask(text string, box image.Rectangle, face font.Face, halo int) *image.Alpha {
+	mask := image.NewAlpha(box)
+	d := font.Drawer{
+		Dst:  mask,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(box.Min.X+halo, box.Min.Y+halo+face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(text)
+	return mask
+}
+
+// dilate grows a mask by the provided radius with an anti-aliased edge
+func dilate(m *image.Alpha, radius int) *image.Alpha {
+	type offset struct {
+		dx, dy int
+		w      float64
+	}
+
+	offsets := make([]offset, 0)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			w := math.Min(1, float64(radius)+0.5-math.Hypot(float64(dx), float64(dy)))
+			if w > 0 {
+				offsets = append(offsets, offset{dx, dy, w})
+			}
+		}
+	}
+
+	b := m.Bounds()
+	out := image.NewAlpha(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			v := 0.0
+			for _, o := range offsets {
+				a := float64(m.AlphaAt(x+o.dx, y+o.dy).A) * o.w
+				if a > v {
+					v = a
+				}
+			}
+			out.SetAlpha(x, y, color.Alpha{A: uint8(v + 0.5)})
+		}
+	}
+
+	return out
+}
+
+// DrawTextLocal draws a line of text at the local X/Y coordinates
+func (t *Tile) DrawTextLocal(text string, x, y float64, style TextStyle) error {
+	box, err := textBox(text, x, y, style)
+	if err != nil {
+		return err
+	}
+
+	halo := style.halo()
+	mask := textMask(text, box, style.face(), halo)
+
+	if halo > 0 {
+		drawMask(t.Image, dilate(mask, halo), style.HaloColor)
+	}
+
+	c := style.Color
+	if c == nil {
+		c = color.Black
+	}
+	drawMask(t.Image, mask, c)
+
+	return nil
+}
+
+// DrawText draws a line of text at the provided location
+func (t *Tile) DrawText(loc base.Location, text string, style TextStyle) error {
+	p := t.LocationToLocal(loc)
+	return t.DrawTextLocal(text, p.X, p.Y, style)
+}
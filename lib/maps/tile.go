@@ -50,7 +50,7 @@ func (t *Tile) Clone() Tile {
 
 // LocationToPixel translates a global location to a pixel on the tile
 func (t *Tile) LocationToPixel(loc base.Location) (float64, float64, error) {
-	x, y := MercatorLocationToPixel(loc.Latitude, loc.Longitude, t.Level, t.Size)
+	x, y := MercatorLocationToPixelF(loc.Latitude, loc.Longitude, float64(t.Level), t.Size)
 	offsetX, offsetY := x-float64(t.X*t.Size), y-float64(t.Y*t.Size)
 
 	if xMax := float64(t.Image.Bounds().Max.X); (offsetX < 0) || (offsetX > xMax) {
@@ -73,7 +73,7 @@ func (t *Tile) PixelToLocation(x, y float64) (*base.Location, error) {
 	}
 
 	offsetX, offsetY := x+float64(t.X*t.Size), y+float64(t.Y*t.Size)
-	lat, lng := MercatorPixelToLocation(offsetX, offsetY, t.Level, t.Size)
+	lat, lng := MercatorPixelToLocationF(offsetX, offsetY, float64(t.Level), t.Size)
 
 	return &base.Location{Latitude: lat, Longitude: lng}, nil
 }
@@ -99,30 +99,40 @@ type DrawConfig struct {
 // Center preconfigured centering helper
 var Center = DrawConfig{JustifyCenter, JustifyCenter}
 
-// DrawLocalXY draws the provided image at the local X/Y coordinates
-func (t *Tile) DrawLocalXY(src image.Image, x, y int, config DrawConfig) error {
+// justify returns the top left point of a box of the provided size positioned at x/y with the provided justification
+func justify(size image.Point, x, y int, config DrawConfig) (image.Point, error) {
 	dp := image.Point{}
 
 	switch config.Horizontal {
 	case JustifyLeft:
 		dp.X = x
 	case JustifyCenter:
-		dp.X = x - src.Bounds().Dx()/2
+		dp.X = x - size.X/2
 	case JustifyRight:
-		dp.X = x - src.Bounds().Dx()
+		dp.X = x - size.X
 	default:
-		return fmt.Errorf("Unsupported horizontal justification (%s)", config.Horizontal)
+		return dp, fmt.Errorf("Unsupported horizontal justification (%s)", config.Horizontal)
 	}
 
 	switch config.Vertical {
 	case JustifyTop:
 		dp.Y = y
 	case JustifyCenter:
-		dp.Y = y - src.Bounds().Dy()/2
+		dp.Y = y - size.Y/2
 	case JustifyBottom:
-		dp.Y = y - src.Bounds().Dy()
+		dp.Y = y - size.Y
 	default:
-		return fmt.Errorf("Unsupported vertical justification (%s)", config.Horizontal)
+		return dp, fmt.Errorf("Unsupported vertical justification (%s)", config.Vertical)
+	}
+
+	return dp, nil
+}
+
+// DrawLocalXY draws the provided image at the local X/Y coordinates
+func (t *Tile) DrawLocalXY(src image.Image, x, y int, config DrawConfig) error {
+	dp, err := justify(src.Bounds().Size(), x, y, config)
+	if err != nil {
+		return err
 	}
 
 	r := image.Rectangle{dp, dp.Add(src.Bounds().Size())}
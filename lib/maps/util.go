@@ -12,12 +12,18 @@ package maps
 import (
 	"bufio"
 	"bytes"
+	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	"image/jpeg"
 	"image/png"
+	"io"
 	"io/ioutil"
+	"math"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/tumasgiu/go-mapbox/lib/base"
 )
@@ -40,8 +46,8 @@ func TileIDToLocation(x, y float64, level uint64) base.Location {
 // eg. Tile (X:16, Y:10, level:4 ) will become (X:0, Y:10, level:4)
 func WrapTileID(x, y, level uint64) (uint64, uint64) {
 	// Limit to 2^n tile range for a given level
-	x = x % (2 << (level - 1))
-	y = y % (2 << (level - 1))
+	x = x % (1 << level)
+	y = y % (1 << level)
 
 	return x, y
 }
@@ -169,6 +175,37 @@ func SaveImagePNG(img image.Image, file string) error {
 	return nil
 }
 
+// FormatContentType returns the MIME type for a map format
+func FormatContentType(format MapFormat) string {
+	switch {
+	case strings.HasPrefix(string(format), "jpg"):
+		return "image/jpeg"
+	case strings.HasPrefix(string(format), "png"):
+		return "image/png"
+	case format == MapFormatVectorTile:
+		return "application/vnd.mapbox-vector-tile"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// EncodeImage encodes an image in the provided (raster) map format
+// JPG formats use the quality from the format name, PNG formats are encoded as true colour
+func EncodeImage(w io.Writer, img image.Image, format MapFormat) error {
+	switch {
+	case strings.HasPrefix(string(format), "jpg"):
+		quality, err := strconv.Atoi(strings.TrimPrefix(string(format), "jpg"))
+		if err != nil {
+			return fmt.Errorf("Unrecognized jpg quality (%s)", format)
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case strings.HasPrefix(string(format), "png"):
+		return png.Encode(w, img)
+	default:
+		return fmt.Errorf("Unsupported image format (%s)", format)
+	}
+}
+
 // PixelToHeight Converts a pixel to a height value for mapbox terrain tiles
 // Equation from https://www.mapbox.com/blog/terrain-rgb/
 func PixelToHeight(r, g, b uint8) float64 {
@@ -176,10 +213,48 @@ func PixelToHeight(r, g, b uint8) float64 {
 	return -10000 + ((R*256*256 + G*256 + B) * 0.1)
 }
 
+// HeightToPixel Converts a height value to a pixel for mapbox terrain tiles (the inverse of PixelToHeight)
 func HeightToPixel(alt float64) (uint8, uint8, uint8) {
-	increments := int((alt + 10000) / 0.1)
-	b := uint8((increments >> 0) % 0xFF)
-	g := uint8((increments >> 8) % 0xFF)
-	r := uint8((increments >> 16) % 0xFF)
+	increments := int(math.Round((alt + 10000) * 10))
+	b := uint8((increments >> 0) & 0xFF)
+	g := uint8((increments >> 8) & 0xFF)
+	r := uint8((increments >> 16) & 0xFF)
 	return r, g, b
 }
+
+// SampleBilinear samples an image at a fractional pixel location using bilinear interpolation
+// Pixel values apply to pixel centres and locations outside the image are clamped to the edges
+func SampleBilinear(img image.Image, x, y float64) color.NRGBA {
+	b := img.Bounds()
+
+	x, y = x-0.5, y-0.5
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+
+	clamp := func(v, min, max int) int {
+		if v < min {
+			return min
+		}
+		if v > max-1 {
+			return max - 1
+		}
+		return v
+	}
+
+	xi0, xi1 := clamp(b.Min.X+int(x0), b.Min.X, b.Max.X), clamp(b.Min.X+int(x0)+1, b.Min.X, b.Max.X)
+	yi0, yi1 := clamp(b.Min.Y+int(y0), b.Min.Y, b.Max.Y), clamp(b.Min.Y+int(y0)+1, b.Min.Y, b.Max.Y)
+
+	// Interpolate in premultiplied space to avoid colour fringes around transparent pixels
+	var out [4]float64
+	weights := [4]float64{(1 - fx) * (1 - fy), fx * (1 - fy), (1 - fx) * fy, fx * fy}
+	for i, p := range [4][2]int{{xi0, yi0}, {xi1, yi0}, {xi0, yi1}, {xi1, yi1}} {
+		r, g, b, a := img.At(p[0], p[1]).RGBA()
+		out[0] += float64(r) * weights[i]
+		out[1] += float64(g) * weights[i]
+		out[2] += float64(b) * weights[i]
+		out[3] += float64(a) * weights[i]
+	}
+
+	c := color.RGBA64{R: uint16(out[0] + 0.5), G: uint16(out[1] + 0.5), B: uint16(out[2] + 0.5), A: uint16(out[3] + 0.5)}
+	return color.NRGBAModel.Convert(c).(color.NRGBA)
+}
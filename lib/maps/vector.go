@@ -0,0 +1,235 @@
+/**
+ * go-mapbox Maps Module Vector Drawing
+ * Anti-aliased polylines, polygons and circles on tiles and stitched tiles
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// LineCap sets the shape of the ends of stroked lines
+type LineCap string
+
+// Line cap types
+const (
+	LineCapButt   LineCap = "butt"
+	LineCapRound  LineCap = "round"
+	LineCapSquare LineCap = "square"
+)
+
+// LineJoin sets the shape of the corners of stroked lines
+type LineJoin string
+
+// Line join types
+const (
+	LineJoinMiter LineJoin = "miter"
+	LineJoinRound LineJoin = "round"
+	LineJoinBevel LineJoin = "bevel"
+)
+
+// DefaultMiterLimit is the miter length (as a multiple of the line width) at which miter joins become bevels
+const DefaultMiterLimit = 4.0
+
+// StrokeStyle configures line drawing
+type StrokeStyle struct {
+	Color      color.Color
+	Width      float64 // Line width in pixels
+	Cap        LineCap
+	Join       LineJoin
+	MiterLimit float64
+}
+
+// LocationToLocal projects a location into local pixel space without bounds checks
+// Locations outside the tile return pixels outside the image, which are clipped when drawing
+func (t *Tile) LocationToLocal(loc base.Location) Vec {
+	x, y := MercatorLocationToPixel(loc.Latitude, loc.Longitude, t.Level, t.Size)
+	return Vec{x - float64(t.X*t.Size), y - float64(t.Y*t.Size)}
+}
+
+func (t *Tile) locationsToLocal(locs []base.Location) []Vec {
+	points := make([]Vec, len(locs))
+	for i, l := range locs {
+		points[i] = t.LocationToLocal(l)
+	}
+	return points
+}
+
+// FillLocal fills a polygon in local pixel space, the first ring is the outline and any further rings are holes
+func (t *Tile) FillLocal(rings [][]Vec, c color.Color) {
+	drawMask(t.Image, fillMask(rings, t.Bounds(), true), c)
+}
+
+// StrokeLocal strokes a line in local pixel space, closed lines are joined back to their start
+func (t *Tile) StrokeLocal(points []Vec, closed bool, style StrokeStyle) {
+	drawMask(t.Image, fillMask(strokeOutline(points, closed, style), t.Bounds(), false), style.Color)
+}
+
+// DrawPolyline draws an anti-aliased line through the provided locations
+func (t *Tile) DrawPolyline(locs []base.Location, style StrokeStyle) {
+	t.StrokeLocal(t.locationsToLocal(locs), false, style)
+}
+
+// DrawPolygon draws a polygon with an outer ring and optional holes
+// Fill and stroke are optional (pass nil to skip)
+func (t *Tile) DrawPolygon(rings [][]base.Location, fill color.Color, stroke *StrokeStyle) {
+	local := make([][]Vec, len(rings))
+	for i, r := range rings {
+		local[i] = t.locationsToLocal(r)
+	}
+
+	if fill != nil {
+		t.FillLocal(local, fill)
+	}
+	if stroke != nil {
+		for _, r := range local {
+			t.StrokeLocal(r, true, *stroke)
+		}
+	}
+}
+
+// DrawCircle draws a geodesic circle with a radius in metres around a location
+// Fill and stroke are optional (pass nil to skip)
+func (t *Tile) DrawCircle(center base.Location, radius float64, fill color.Color, stroke *StrokeStyle) {
+	// Scale the number of points with the projected size of the circle
+	pixelRadius := radius / (MercatorResolution(t.Level, t.Size) * math.Cos(center.Latitude*D2R))
+	n := int(math.Min(360, math.Max(16, math.Ceil(pixelRadius))))
+
+	ring := make([]base.Location, n)
+	for i := range ring {
+		loc := base.Destination(center, float64(i)*360/float64(n), radius)
+
+		// Keep longitudes continuous for circles crossing the antimeridian
+		if loc.Longitude-center.Longitude > 180 {
+			loc.Longitude -= 360
+		} else if loc.Longitude-center.Longitude < -180 {
+			loc.Longitude += 360
+		}
+		ring[i] = loc
+	}
+
+	t.DrawPolygon([][]base.Location{ring}, fill, stroke)
+}
+
+// strokeOutline builds the rings covering a stroked line, all rings share an orientation so they combine when filled
+func strokeOutline(points []Vec, closed bool, style StrokeStyle) [][]Vec {
+	hw := style.Width / 2
+	if hw <= 0 {
+		return nil
+	}
+	miterLimit := style.MiterLimit
+	if miterLimit <= 0 {
+		miterLimit = DefaultMiterLimit
+	}
+
+	// Drop repeated points which have no direction
+	pts := make([]Vec, 0, len(points))
+	for _, p := range points {
+		if len(pts) == 0 || p.Sub(pts[len(pts)-1]).Len() > 1e-9 {
+			pts = append(pts, p)
+		}
+	}
+	if closed && len(pts) > 1 && pts[0].Sub(pts[len(pts)-1]).Len() <= 1e-9 {
+		pts = pts[:len(pts)-1]
+	}
+
+	rings := make([][]Vec, 0)
+
+	if len(pts) == 1 {
+		if style.Cap == LineCapRound {
+			rings = append(rings, circleRing(pts[0], hw))
+		} else if style.Cap == LineCapSquare {
+			p := pts[0]
+			rings = append(rings, []Vec{{p.X - hw, p.Y - hw}, {p.X + hw, p.Y - hw}, {p.X + hw, p.Y + hw}, {p.X - hw, p.Y + hw}})
+		}
+		return rings
+	}
+
+	segments := len(pts) - 1
+	if closed {
+		segments = len(pts)
+	}
+
+	for i := 0; i < segments; i++ {
+		a, b := pts[i], pts[(i+1)%len(pts)]
+		d := b.Sub(a).Norm()
+
+		// Square caps extend the first and last segments
+		if !closed && style.Cap == LineCapSquare {
+			if i == 0 {
+				a = a.Sub(d.Mul(hw))
+			}
+			if i == segments-1 {
+				b = b.Add(d.Mul(hw))
+			}
+		}
+
+		n := Vec{-d.Y, d.X}.Mul(hw)
+		rings = append(rings, []Vec{a.Add(n), b.Add(n), b.Sub(n), a.Sub(n)})
+	}
+
+	// Joins between segments
+	for i := 0; i < len(pts); i++ {
+		if !closed && (i == 0 || i == len(pts)-1) {
+			continue
+		}
+		prev, p, next := pts[(i+len(pts)-1)%len(pts)], pts[i], pts[(i+1)%len(pts)]
+		rings = append(rings, joinRings(prev, p, next, hw, style.Join, miterLimit)...)
+	}
+
+	if !closed && style.Cap == LineCapRound {
+		rings = append(rings, circleRing(pts[0], hw), circleRing(pts[len(pts)-1], hw))
+	}
+
+	return rings
+}
+
+// joinRings builds the join at p between the segments prev->p and p->next
+func joinRings(prev, p, next Vec, hw float64, join LineJoin, miterLimit float64) [][]Vec {
+	d1, d2 := p.Sub(prev).Norm(), next.Sub(p).Norm()
+	n1, n2 := Vec{-d1.Y, d1.X}, Vec{-d2.Y, d2.X}
+
+	// The outer side of the corner is away from the direction of the turn
+	turn := d2.Dot(n1)
+	if math.Abs(turn) < 1e-12 && d1.Dot(d2) > 0 {
+		return nil
+	}
+	side := -1.0
+	if turn < 0 {
+		side = 1.0
+	}
+	o1, o2 := p.Add(n1.Mul(hw*side)), p.Add(n2.Mul(hw*side))
+
+	switch join {
+	case LineJoinRound:
+		return [][]Vec{circleRing(p, hw)}
+	case LineJoinBevel:
+		return [][]Vec{{p, o1, o2}}
+	default:
+		bisector := n1.Add(n2).Norm()
+		cosHalf := bisector.Dot(n1)
+		if cosHalf <= 1e-9 || 1/cosHalf > miterLimit {
+			return [][]Vec{{p, o1, o2}}
+		}
+		miter := p.Add(bisector.Mul(hw * side / cosHalf))
+		return [][]Vec{{p, o1, miter, o2}}
+	}
+}
+
+// circleRing approximates a circle in pixel space
+func circleRing(c Vec, r float64) []Vec {
+	n := int(math.Min(128, math.Max(8, math.Ceil(r*2))))
+	ring := make([]Vec, n)
+	for i := range ring {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		ring[i] = Vec{c.X + r*math.Cos(a), c.Y + r*math.Sin(a)}
+	}
+	return ring
+}
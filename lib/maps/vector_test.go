@@ -0,0 +1,132 @@
+/**
+ * go-mapbox Maps Module Vector Drawing Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+func newBlankTile(x, y, level uint64, w, h int) Tile {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	return NewTile(x, y, level, SizeStandard, img)
+}
+
+func alphaAt(t Tile, x, y int) uint8 {
+	// Drawing black onto white so coverage is the inverse of the red channel
+	return 255 - color.NRGBAModel.Convert(t.At(x, y)).(color.NRGBA).R
+}
+
+func TestVector(t *testing.T) {
+	black := color.NRGBA{A: 255}
+
+	t.Run("Fills polygons with anti-aliased edges", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 32, 32)
+		tile.FillLocal([][]Vec{{{4, 4}, {20, 4}, {20, 20.5}, {4, 20.5}}}, black)
+
+		assert.EqualValues(t, 255, alphaAt(tile, 10, 10))
+		assert.EqualValues(t, 0, alphaAt(tile, 2, 10))
+		assert.EqualValues(t, 0, alphaAt(tile, 21, 10))
+		assert.InDelta(t, 128, alphaAt(tile, 10, 20), 1)
+	})
+
+	t.Run("Cuts holes in polygons", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 32, 32)
+		outer := []Vec{{2, 2}, {30, 2}, {30, 30}, {2, 30}}
+		hole := []Vec{{10, 10}, {20, 10}, {20, 20}, {10, 20}}
+		tile.FillLocal([][]Vec{outer, hole}, black)
+
+		assert.EqualValues(t, 255, alphaAt(tile, 5, 5))
+		assert.EqualValues(t, 0, alphaAt(tile, 15, 15))
+	})
+
+	t.Run("Alpha blends colours", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 8, 8)
+		tile.FillLocal([][]Vec{{{0, 0}, {8, 0}, {8, 8}, {0, 8}}}, color.NRGBA{R: 255, A: 128})
+
+		c := color.NRGBAModel.Convert(tile.At(4, 4)).(color.NRGBA)
+		assert.EqualValues(t, 255, c.R)
+		assert.InDelta(t, 127, c.G, 1)
+	})
+
+	t.Run("Clips shapes outside the tile", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 16, 16)
+		tile.FillLocal([][]Vec{{{-100, -100}, {8, -100}, {8, 1000}, {-100, 1000}}}, black)
+
+		assert.EqualValues(t, 255, alphaAt(tile, 0, 0))
+		assert.EqualValues(t, 255, alphaAt(tile, 7, 15))
+		assert.EqualValues(t, 0, alphaAt(tile, 8, 8))
+	})
+
+	t.Run("Strokes lines with width and caps", func(t *testing.T) {
+		tile := newBlankTile(0, 0, 1, 32, 32)
+		tile.StrokeLocal([]Vec{{8, 16}, {24, 16}}, false, StrokeStyle{Color: black, Width: 4, Cap: LineCapButt})
+
+		assert.EqualValues(t, 255, alphaAt(tile, 16, 14))
+		assert.EqualValues(t, 255, alphaAt(tile, 16, 17))
+		assert.EqualValues(t, 0, alphaAt(tile, 16, 19))
+		assert.EqualValues(t, 0, alphaAt(tile, 6, 16))
+
+		tile = newBlankTile(0, 0, 1, 32, 32)
+		tile.StrokeLocal([]Vec{{8, 16}, {24, 16}}, false, StrokeStyle{Color: black, Width: 4, Cap: LineCapSquare})
+		assert.EqualValues(t, 255, alphaAt(tile, 6, 16))
+	})
+
+	t.Run("Joins overlapping segments without double blending", func(t *testing.T) {
+		half := color.NRGBA{A: 128}
+		for _, join := range []LineJoin{LineJoinMiter, LineJoinRound, LineJoinBevel} {
+			tile := newBlankTile(0, 0, 1, 32, 32)
+			tile.StrokeLocal([]Vec{{4, 4}, {16, 16}, {28, 4}}, false, StrokeStyle{Color: half, Width: 6, Join: join})
+			assert.InDelta(t, 128, alphaAt(tile, 16, 15), 1, "join %s", join)
+		}
+	})
+
+	t.Run("Draws consistently across stitched tile edges", func(t *testing.T) {
+		level := uint64(8)
+		a, b := base.Location{Latitude: -36.80, Longitude: 174.20}, base.Location{Latitude: -37.20, Longitude: 175.30}
+		style := StrokeStyle{Color: black, Width: 3, Cap: LineCapRound, Join: LineJoinRound}
+
+		x, y := LocationToTileID(a, level)
+		stitched := newBlankTile(x, y, level, 512, 256)
+		stitched.DrawPolyline([]base.Location{a, b}, style)
+
+		for i := uint64(0); i < 2; i++ {
+			tile := newBlankTile(x+i, y, level, 256, 256)
+			tile.DrawPolyline([]base.Location{a, b}, style)
+
+			for py := 0; py < 256; py++ {
+				for px := 0; px < 256; px++ {
+					assert.InDelta(t, alphaAt(stitched, int(i)*256+px, py), alphaAt(tile, px, py), 1)
+				}
+			}
+		}
+	})
+
+	t.Run("Draws geodesic circles", func(t *testing.T) {
+		level := uint64(14)
+		center := base.Location{Latitude: -41.2865, Longitude: 174.7762}
+		x, y := LocationToTileID(center, level)
+		tile := newBlankTile(x-1, y-1, level, 768, 768)
+
+		tile.DrawCircle(center, 500, black, nil)
+
+		c := tile.LocationToLocal(center)
+		inside := tile.LocationToLocal(base.Destination(center, 90, 450))
+		outside := tile.LocationToLocal(base.Destination(center, 0, 550))
+		assert.EqualValues(t, 255, alphaAt(tile, int(c.X), int(c.Y)))
+		assert.EqualValues(t, 255, alphaAt(tile, int(inside.X), int(inside.Y)))
+		assert.EqualValues(t, 0, alphaAt(tile, int(outside.X), int(outside.Y)))
+	})
+}
@@ -0,0 +1,217 @@
+/**
+ * go-mapbox Maps Module Viewport Fitting
+ * Computes the centre and zoom at which a set of locations or a bounding box fits an image,
+ * in the same manner as fitBounds in web map libraries
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+// zoomEpsilon avoids rounding an exactly fitting zoom down to the level below
+const zoomEpsilon = 1e-9
+
+// FitOpts configures viewport fitting
+type FitOpts struct {
+	Padding int     // Padding in output pixels kept clear on each side of the bounds
+	MinZoom float64 // Minimum zoom returned
+	MaxZoom float64 // Maximum zoom returned, defaults to MaxLevel
+	HighDPI bool    // Fit using high DPI (512px) tiles rather than standard (256px) tiles
+}
+
+func withFitDefaults(opts *FitOpts) FitOpts {
+	o := FitOpts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxZoom == 0 {
+		o.MaxZoom = float64(MaxLevel)
+	}
+	return o
+}
+
+// Viewport is a map view of a given pixel size centred on a location
+type Viewport struct {
+	Center base.Location
+	Zoom   float64 // Fractional zoom at which the bounds fit the viewport
+	Level  uint64  // Integer zoom level at which the bounds fit the viewport (the floor of Zoom)
+	Width  int
+	Height int
+	Size   uint64 // Tile size, SizeStandard or SizeHighDPI
+}
+
+// boundsZoom returns the fractional zooms at which the (unwrapped) bounds span exactly the provided
+// number of pixels horizontally and vertically
+func boundsZoom(west, south, east, north, width, height float64, size uint64) (float64, float64) {
+	x0, y0 := MercatorLocationToPixel(north, west, 0, size)
+	x1, y1 := MercatorLocationToPixel(south, east, 0, size)
+	return math.Log2(width / (x1 - x0)), math.Log2(height / (y1 - y0))
+}
+
+// FitBounds computes the viewport in which a [minLng, minLat, maxLng, maxLat] bounding box fits an image of
+// the provided size. Boxes crossing the antimeridian (minLng > maxLng) are supported, latitudes are clamped
+// to the limits of the mercator projection and zero size boxes are shown at the maximum zoom
+func FitBounds(bbox base.BoundingBox, width, height int, opts *FitOpts) (*Viewport, error) {
+	o := withFitDefaults(opts)
+
+	if len(bbox) != 4 {
+		return nil, fmt.Errorf("Bounding box must contain 4 values (received %d)", len(bbox))
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("Viewport size must be positive (received %dx%d)", width, height)
+	}
+	if width <= 2*o.Padding || height <= 2*o.Padding {
+		return nil, fmt.Errorf("Padding (%d) leaves no space in a %dx%d viewport", o.Padding, width, height)
+	}
+	if o.MinZoom > o.MaxZoom {
+		return nil, fmt.Errorf("Minimum zoom (%f) exceeds maximum zoom (%f)", o.MinZoom, o.MaxZoom)
+	}
+
+	west, south, east, north := bbox[0], bbox[1], bbox[2], bbox[3]
+	if south > north {
+		return nil, fmt.Errorf("Bounding box south (%f) must not exceed north (%f)", south, north)
+	}
+	if west < -180 || west > 180 || east < -180 || east > 180 {
+		return nil, fmt.Errorf("Bounding box longitudes must be within +/- 180")
+	}
+	south = math.Max(math.Min(south, MaxLatitude), -MaxLatitude)
+	north = math.Max(math.Min(north, MaxLatitude), -MaxLatitude)
+	east = unwrapEast(west, east)
+
+	size := SizeStandard
+	if o.HighDPI {
+		size = SizeHighDPI
+	}
+
+	zx, zy := boundsZoom(west, south, east, north, float64(width-2*o.Padding), float64(height-2*o.Padding), size)
+	zoom := math.Max(math.Min(math.Min(zx, zy), o.MaxZoom), o.MinZoom)
+
+	// The centre is taken in projected space so the bounds are centred in the image
+	x0, y0 := MercatorLocationToPixel(north, west, 0, size)
+	x1, y1 := MercatorLocationToPixel(south, east, 0, size)
+	lat, lng := MercatorPixelToLocation((x0+x1)/2, (y0+y1)/2, 0, size)
+	if lng > 180 {
+		lng -= 360
+	}
+
+	return &Viewport{
+		Center: base.Location{Latitude: lat, Longitude: lng},
+		Zoom:   zoom,
+		Level:  uint64(math.Max(math.Floor(zoom+zoomEpsilon), 0)),
+		Width:  width,
+		Height: height,
+		Size:   size,
+	}, nil
+}
+
+// LocationsBBox returns the smallest [minLng, minLat, maxLng, maxLat] bounding box containing the locations,
+// crossing the antimeridian (minLng > maxLng) where that results in a narrower box
+func LocationsBBox(locs []base.Location) (base.BoundingBox, error) {
+	if len(locs) == 0 {
+		return nil, fmt.Errorf("At least one location is required")
+	}
+
+	south, north := locs[0].Latitude, locs[0].Latitude
+	west, east := locs[0].Longitude, locs[0].Longitude
+	// Longitudes shifted to [0, 360) to find boxes crossing the antimeridian
+	shift := func(lng float64) float64 {
+		if lng < 0 {
+			return lng + 360
+		}
+		return lng
+	}
+	westShifted, eastShifted := shift(west), shift(west)
+
+	for _, l := range locs[1:] {
+		south, north = math.Min(south, l.Latitude), math.Max(north, l.Latitude)
+		west, east = math.Min(west, l.Longitude), math.Max(east, l.Longitude)
+		westShifted, eastShifted = math.Min(westShifted, shift(l.Longitude)), math.Max(eastShifted, shift(l.Longitude))
+	}
+
+	if eastShifted-westShifted < east-west {
+		west, east = westShifted, eastShifted
+		if west > 180 {
+			west -= 360
+		}
+		if east > 180 {
+			east -= 360
+		}
+	}
+
+	return base.BoundingBox{west, south, east, north}, nil
+}
+
+// FitLocations computes the viewport in which all of the locations fit an image of the provided size
+func FitLocations(locs []base.Location, width, height int, opts *FitOpts) (*Viewport, error) {
+	bbox, err := LocationsBBox(locs)
+	if err != nil {
+		return nil, err
+	}
+	return FitBounds(bbox, width, height, opts)
+}
+
+// PixelBounds returns the global pixel extents of the viewport at the integer zoom level
+// The east (x1) extent may exceed the world size for viewports crossing the antimeridian
+func (v *Viewport) PixelBounds() (float64, float64, float64, float64) {
+	cx, cy := MercatorLocationToPixel(v.Center.Latitude, v.Center.Longitude, v.Level, v.Size)
+	scale := math.Pow(2, float64(v.Level)-v.Zoom)
+	w, h := float64(v.Width)*scale/2, float64(v.Height)*scale/2
+	return cx - w, cy - h, cx + w, cy + h
+}
+
+// Bounds returns the [minLng, minLat, maxLng, maxLat] bounding box visible in the viewport
+// The box may include padding and additional area due to the aspect ratio of the viewport
+func (v *Viewport) Bounds() base.BoundingBox {
+	x0, y0, x1, y1 := v.PixelBounds()
+	world := float64(v.Size << v.Level)
+
+	clampY := func(y float64) float64 {
+		return math.Max(math.Min(y, world), 0)
+	}
+	north, west := MercatorPixelToLocation(x0, clampY(y0), v.Level, v.Size)
+	south, east := MercatorPixelToLocation(x1, clampY(y1), v.Level, v.Size)
+
+	if x1-x0 >= world {
+		return base.BoundingBox{-180, south, 180, north}
+	}
+
+	wrap := func(lng float64) float64 {
+		return math.Mod(math.Mod(lng+180, 360)+360, 360) - 180
+	}
+	return base.BoundingBox{wrap(west), south, wrap(east), north}
+}
+
+// TileIDs returns the (unwrapped) range of tile IDs at the integer zoom level covering the viewport
+func (v *Viewport) TileIDs() (uint64, uint64, uint64, uint64) {
+	x0, y0, x1, y1 := v.PixelBounds()
+	fsize := float64(v.Size)
+	world := float64(v.Size << v.Level)
+
+	// Wrap the viewport start into the world, the end may then extend past the edge
+	offset := math.Floor(x0/world) * world
+	x0, x1 = x0-offset, x1-offset
+
+	clamp := func(t float64) uint64 {
+		return uint64(math.Max(math.Min(t, float64(uint64(1)<<v.Level)-1), 0))
+	}
+
+	xStart, xEnd := math.Floor(x0/fsize), math.Ceil(x1/fsize)-1
+	if xEnd < xStart {
+		xEnd = xStart
+	}
+	return uint64(xStart), clamp(math.Floor(y0 / fsize)), uint64(xEnd), clamp(math.Ceil(y1/fsize) - 1)
+}
+
+// GetViewportTiles fetches a 2d array of the tiles covering a viewport, wrapping tiles past the antimeridian
+func (m *Maps) GetViewportTiles(mapID MapID, v *Viewport, format MapFormat) ([][]Tile, error) {
+	xStart, yStart, xEnd, yEnd := v.TileIDs()
+	return m.getTileRange(mapID, xStart, yStart, xEnd, yEnd, v.Level, format, v.Size == SizeHighDPI)
+}
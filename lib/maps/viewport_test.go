@@ -0,0 +1,105 @@
+/**
+ * go-mapbox Maps Module Viewport Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package maps
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+func TestViewport(t *testing.T) {
+	t.Run("Fits the world", func(t *testing.T) {
+		v, err := FitBounds(base.BoundingBox{-180, -MaxLatitude, 180, MaxLatitude}, 512, 512, nil)
+		assert.Nil(t, err)
+		assert.InDelta(t, 1, v.Zoom, 1e-9)
+		assert.EqualValues(t, 1, v.Level)
+		assert.InDelta(t, 0, v.Center.Latitude, 1e-9)
+		assert.InDelta(t, 0, v.Center.Longitude, 1e-9)
+
+		// High DPI tiles are twice the size so the same view is one zoom level lower
+		v, err = FitBounds(base.BoundingBox{-180, -MaxLatitude, 180, MaxLatitude}, 512, 512, &FitOpts{HighDPI: true})
+		assert.Nil(t, err)
+		assert.InDelta(t, 0, v.Zoom, 1e-9)
+		assert.EqualValues(t, SizeHighDPI, v.Size)
+	})
+
+	t.Run("Fits bounds with padding", func(t *testing.T) {
+		bbox := base.BoundingBox{174.6, -41.4, 174.9, -41.2}
+		v, err := FitBounds(bbox, 800, 600, &FitOpts{Padding: 20})
+		assert.Nil(t, err)
+
+		// The bounds fit within the padded viewport, exactly along the vertical axis
+		zx, zy := boundsZoom(bbox[0], bbox[1], bbox[2], bbox[3], 760, 560, SizeStandard)
+		assert.InDelta(t, zy, v.Zoom, 1e-9)
+		assert.True(t, zx > v.Zoom)
+		assert.EqualValues(t, 11, v.Level)
+
+		// The centre is the projected centre of the bounds
+		x, y := MercatorLocationToPixel(v.Center.Latitude, v.Center.Longitude, 0, SizeStandard)
+		x0, y0 := MercatorLocationToPixel(bbox[3], bbox[0], 0, SizeStandard)
+		x1, y1 := MercatorLocationToPixel(bbox[1], bbox[2], 0, SizeStandard)
+		assert.InDelta(t, (x0+x1)/2, x, 1e-9)
+		assert.InDelta(t, (y0+y1)/2, y, 1e-9)
+
+		visible := v.Bounds()
+		assert.True(t, visible[0] < bbox[0] && visible[1] < bbox[1] && visible[2] > bbox[2] && visible[3] > bbox[3])
+
+		xStart, yStart, xEnd, yEnd := v.TileIDs()
+		aX, aY := LocationToTileID(base.Location{Latitude: visible[3], Longitude: visible[0]}, v.Level)
+		bX, bY := LocationToTileID(base.Location{Latitude: visible[1], Longitude: visible[2]}, v.Level)
+		assert.EqualValues(t, []uint64{aX, aY, bX, bY}, []uint64{xStart, yStart, xEnd, yEnd})
+	})
+
+	t.Run("Fits locations across the antimeridian", func(t *testing.T) {
+		locs := []base.Location{{Latitude: -15, Longitude: 175}, {Latitude: -20, Longitude: -175}, {Latitude: -17, Longitude: 179}}
+
+		bbox, err := LocationsBBox(locs)
+		assert.Nil(t, err)
+		assert.EqualValues(t, base.BoundingBox{175, -20, -175, -15}, bbox)
+
+		v, err := FitLocations(locs, 512, 512, nil)
+		assert.Nil(t, err)
+		assert.InDelta(t, 180, v.Center.Longitude, 1e-9)
+
+		xStart, _, xEnd, _ := v.TileIDs()
+		assert.True(t, xEnd >= 1<<v.Level, "tile range extends past the edge of the world")
+		assert.True(t, xStart < 1<<v.Level)
+
+		maps, _ := newSyntheticMaps(t)
+		tiles, err := maps.GetViewportTiles(MapIDStreets, v, MapFormatPng)
+		assert.Nil(t, err)
+		assert.EqualValues(t, xEnd-xStart+1, len(tiles[0]))
+		assert.EqualValues(t, 0, tiles[0][len(tiles[0])-1].X)
+	})
+
+	t.Run("Clamps zoom", func(t *testing.T) {
+		v, err := FitLocations([]base.Location{{Latitude: 10, Longitude: 10}}, 256, 256, nil)
+		assert.Nil(t, err)
+		assert.EqualValues(t, float64(MaxLevel), v.Zoom)
+		assert.InDelta(t, 10, v.Center.Latitude, 1e-9)
+		assert.InDelta(t, 10, v.Center.Longitude, 1e-9)
+
+		v, err = FitBounds(base.BoundingBox{-180, -MaxLatitude, 180, MaxLatitude}, 256, 256, &FitOpts{MinZoom: 2, MaxZoom: 4})
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, v.Zoom)
+	})
+
+	t.Run("Rejects invalid input", func(t *testing.T) {
+		_, err := FitBounds(base.BoundingBox{0, 0, 1}, 10, 10, nil)
+		assert.NotNil(t, err)
+		_, err = FitBounds(base.BoundingBox{0, 10, 1, 5}, 10, 10, nil)
+		assert.NotNil(t, err)
+		_, err = FitBounds(base.BoundingBox{0, 0, 1, 1}, 10, 10, &FitOpts{Padding: 5})
+		assert.NotNil(t, err)
+		_, err = FitLocations(nil, 10, 10, nil)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,209 @@
+/**
+ * go-mapbox Playback Module Encoding
+ * Encodes animations as animated GIF or APNG
+ * See https://wiki.mozilla.org/APNG_Specification for APNG information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package playback
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"time"
+)
+
+// pngSignature is the eight byte header of PNG (and APNG) files
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// apngDelay returns the APNG frame delay as a 16 bit fraction of a second, using the finest of
+// millisecond, centisecond, decisecond or second precision that can represent the delay
+func apngDelay(d time.Duration) (uint16, uint16, error) {
+	if d < 0 {
+		return 0, 0, fmt.Errorf("Frame delay must not be negative (received %s)", d)
+	}
+	for _, den := range []time.Duration{1000, 100, 10, 1} {
+		num := (d + time.Second/den/2) / (time.Second / den)
+		if num <= math.MaxUint16 {
+			return uint16(num), uint16(den), nil
+		}
+	}
+	return 0, 0, fmt.Errorf("Frame delay (%s) exceeds the APNG maximum of %d seconds", d, math.MaxUint16)
+}
+
+// apngFrame encodes with an alpha channel regardless of frame content, as image/png selects RGB
+// for opaque images and all APNG frames must share the colour type of the header
+type apngFrame struct {
+	*image.NRGBA
+}
+
+// Opaque reports false so every frame is encoded with the same colour type
+func (f apngFrame) Opaque() bool {
+	return false
+}
+
+// EncodeGIF encodes the animation as a looping animated GIF
+// Frames are quantised to the web safe palette with Floyd-Steinberg dithering
+func (a *Animation) EncodeGIF(w io.Writer) error {
+	if len(a.Frames) == 0 {
+		return fmt.Errorf("Animation contains no frames")
+	}
+
+	// GIF delays are in hundredths of a second
+	delay := int((a.Delay + 5*time.Millisecond) / (10 * time.Millisecond))
+
+	g := gif.GIF{
+		Image: make([]*image.Paletted, len(a.Frames)),
+		Delay: make([]int, len(a.Frames)),
+	}
+	for i, f := range a.Frames {
+		p := image.NewPaletted(f.Bounds(), palette.WebSafe)
+		draw.FloydSteinberg.Draw(p, f.Bounds(), f, f.Bounds().Min)
+		g.Image[i] = p
+		g.Delay[i] = delay
+	}
+
+	return gif.EncodeAll(w, &g)
+}
+
+type pngChunk struct {
+	name string
+	data []byte
+}
+
+// readChunks splits an encoded PNG into chunks, verifying the signature
+func readChunks(data []byte) ([]pngChunk, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("Invalid PNG signature")
+	}
+	data = data[len(pngSignature):]
+
+	chunks := make([]pngChunk, 0)
+	for len(data) >= 12 {
+		length := int(binary.BigEndian.Uint32(data[:4]))
+		if len(data) < 12+length {
+			return nil, fmt.Errorf("Truncated PNG chunk")
+		}
+		chunks = append(chunks, pngChunk{name: string(data[4:8]), data: data[8 : 8+length]})
+		data = data[12+length:]
+	}
+
+	return chunks, nil
+}
+
+// writeChunk writes a PNG chunk with its length and CRC
+func writeChunk(w io.Writer, name string, data []byte) error {
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], name)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+
+	footer := make([]byte, 4)
+	binary.BigEndian.PutUint32(footer, crc.Sum32())
+
+	for _, b := range [][]byte{header, data, footer} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeAPNG encodes the animation as a looping animated PNG
+// Each frame is encoded with image/png and its image data repackaged as APNG frame data
+func (a *Animation) EncodeAPNG(w io.Writer) error {
+	if len(a.Frames) == 0 {
+		return fmt.Errorf("Animation contains no frames")
+	}
+
+	delayNum, delayDen, err := apngDelay(a.Delay)
+	if err != nil {
+		return err
+	}
+
+	var header []byte
+	sequence := uint32(0)
+	buf := bytes.Buffer{}
+
+	for i, f := range a.Frames {
+		buf.Reset()
+		if err := png.Encode(&buf, apngFrame{f}); err != nil {
+			return err
+		}
+		chunks, err := readChunks(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		// All frames share the header of the first frame
+		if i == 0 {
+			if len(chunks) == 0 || chunks[0].name != "IHDR" {
+				return fmt.Errorf("Encoded frame is missing a header")
+			}
+			header = chunks[0].data
+
+			if _, err := w.Write(pngSignature); err != nil {
+				return err
+			}
+			if err := writeChunk(w, "IHDR", header); err != nil {
+				return err
+			}
+
+			// Animation control: frame count and zero plays (loop forever)
+			actl := make([]byte, 8)
+			binary.BigEndian.PutUint32(actl[0:], uint32(len(a.Frames)))
+			if err := writeChunk(w, "acTL", actl); err != nil {
+				return err
+			}
+		} else if !bytes.Equal(chunks[0].data, header) {
+			return fmt.Errorf("Frame %d does not match the size and colour type of the first frame", i)
+		}
+
+		// Frame control: full frame at the origin, no disposal and source blending
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], sequence)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(f.Bounds().Dx()))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(f.Bounds().Dy()))
+		binary.BigEndian.PutUint16(fctl[20:], delayNum)
+		binary.BigEndian.PutUint16(fctl[22:], delayDen)
+		if err := writeChunk(w, "fcTL", fctl); err != nil {
+			return err
+		}
+		sequence++
+
+		for _, c := range chunks {
+			if c.name != "IDAT" {
+				continue
+			}
+
+			// The first frame is the default image, later frames are stored as sequenced frame data
+			if i == 0 {
+				err = writeChunk(w, "IDAT", c.data)
+			} else {
+				seq := make([]byte, 4)
+				binary.BigEndian.PutUint32(seq, sequence)
+				err = writeChunk(w, "fdAT", append(seq, c.data...))
+				sequence++
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return writeChunk(w, "IEND", nil)
+}
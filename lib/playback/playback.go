@@ -0,0 +1,267 @@
+/**
+ * go-mapbox Playback Module
+ * Renders animated playback of timestamped traces over a basemap
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package playback
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/map_matching"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+const (
+	// DefaultSize is the default frame width and height in pixels
+	DefaultSize = 512
+	// DefaultPadding is the default padding between the trace and the frame edge in pixels
+	DefaultPadding = 32
+	// DefaultFrames is the default number of frames
+	DefaultFrames = 30
+	// DefaultFPS is the default frame rate
+	DefaultFPS = 10.0
+)
+
+// minResolution is the minimum frame resolution in metres per pixel, used for stationary traces
+const minResolution = 1.0
+
+// TracePoint is a timestamped location
+type TracePoint struct {
+	Location base.Location
+	Time     time.Time
+}
+
+// Trace is a list of timestamped locations
+type Trace []TracePoint
+
+// TraceFromMatching snaps the points of an input trace to their matched locations from a map matching
+// response for the same trace, dropping points that could not be matched
+func TraceFromMatching(input Trace, res *mapmatching.MatchingResponse) (Trace, error) {
+	if len(res.Tracepoint) != len(input) {
+		return nil, fmt.Errorf("Trace point count mismatch (input %d matched %d)", len(input), len(res.Tracepoint))
+	}
+
+	trace := make(Trace, 0, len(input))
+	for i, tp := range res.Tracepoint {
+		if len(tp.Location) < 2 {
+			continue
+		}
+		trace = append(trace, TracePoint{
+			Location: base.Location{Latitude: tp.Location[1], Longitude: tp.Location[0]},
+			Time:     input[i].Time,
+		})
+	}
+
+	return trace, nil
+}
+
+// Opts configures playback rendering
+type Opts struct {
+	MapID   maps.MapID       // Basemap, defaults to maps.MapIDStreets
+	Format  maps.MapFormat   // Basemap format, defaults to maps.MapFormatPng
+	Width   int              // Frame width, defaults to DefaultSize
+	Height  int              // Frame height, defaults to DefaultSize
+	Padding int              // Padding around the trace in pixels, defaults to DefaultPadding
+	Frames  int              // Number of frames, defaults to DefaultFrames
+	FPS     float64          // Frame rate, defaults to DefaultFPS
+	Trail   time.Duration    // Length of the trail behind the head, zero draws the entire trace so far
+	Line    maps.StrokeStyle // Trace line style
+	Head    maps.Marker      // Head marker
+}
+
+func withDefaults(opts *Opts) Opts {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MapID == "" {
+		o.MapID = maps.MapIDStreets
+	}
+	if o.Format == "" {
+		o.Format = maps.MapFormatPng
+	}
+	if o.Width <= 0 {
+		o.Width = DefaultSize
+	}
+	if o.Height <= 0 {
+		o.Height = DefaultSize
+	}
+	if o.Padding < 0 {
+		o.Padding = 0
+	} else if o.Padding == 0 {
+		o.Padding = DefaultPadding
+	}
+	if o.Frames <= 0 {
+		o.Frames = DefaultFrames
+	}
+	if o.FPS <= 0 {
+		o.FPS = DefaultFPS
+	}
+	if o.Line.Color == nil {
+		o.Line.Color = color.NRGBA{R: 0x3b, G: 0xb2, B: 0xd0, A: 0xff}
+	}
+	if o.Line.Width <= 0 {
+		o.Line.Width = 4
+		o.Line.Cap, o.Line.Join = maps.LineCapRound, maps.LineJoinRound
+	}
+	if o.Head.Shape == "" {
+		o.Head = maps.Marker{Shape: maps.MarkerCircle, Color: color.NRGBA{R: 0xe5, G: 0x3e, B: 0x3e, A: 0xff}, Size: 14}
+	}
+	return o
+}
+
+// Animation is a sequence of rendered frames
+type Animation struct {
+	Frames []*image.NRGBA
+	Delay  time.Duration // Delay between frames
+}
+
+// Playback renders trace animations using basemaps from the maps API
+type Playback struct {
+	maps *maps.Maps
+}
+
+// NewPlayback creates a playback renderer using the provided maps instance (and its cache)
+func NewPlayback(m *maps.Maps) *Playback {
+	return &Playback{maps: m}
+}
+
+// frameBBox computes a bounding box containing the trace with padding at the frame aspect ratio
+// Longitudes padded past the antimeridian are wrapped, giving a box that crosses it (west > east)
+func frameBBox(trace Trace, o Opts) base.BoundingBox {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range trace {
+		x, y := maps.MercatorLocationToMeters(p.Location.Latitude, p.Location.Longitude)
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+
+	// Fit the trace within the padded frame, preserving square pixels
+	innerW := math.Max(1, float64(o.Width-2*o.Padding))
+	innerH := math.Max(1, float64(o.Height-2*o.Padding))
+	res := math.Max(minResolution, math.Max((maxX-minX)/innerW, (maxY-minY)/innerH))
+
+	cx, cy := (minX+maxX)/2, (minY+maxY)/2
+	halfW, halfH := float64(o.Width)/2*res, float64(o.Height)/2*res
+
+	south, west := maps.MercatorMetersToLocation(cx-halfW, cy-halfH)
+	north, east := maps.MercatorMetersToLocation(cx+halfW, cy+halfH)
+	north = math.Min(north, maps.MaxLatitude)
+	south = math.Max(south, -maps.MaxLatitude)
+	if east-west >= 360 {
+		west, east = -180, 180
+	} else if west < -180 {
+		west += 360
+	} else if east > 180 {
+		east -= 360
+	}
+
+	return base.BoundingBox{west, south, east, north}
+}
+
+// position returns the interpolated location of the trace at a time
+func (tr Trace) position(t time.Time) base.Location {
+	i := sort.Search(len(tr), func(i int) bool { return !tr[i].Time.Before(t) })
+	if i == 0 {
+		return tr[0].Location
+	}
+	if i == len(tr) {
+		return tr[len(tr)-1].Location
+	}
+
+	a, b := tr[i-1], tr[i]
+	span := b.Time.Sub(a.Time)
+	if span <= 0 {
+		return b.Location
+	}
+	return base.Interpolate(a.Location, b.Location, float64(t.Sub(a.Time))/float64(span))
+}
+
+// between returns the trace locations from start to end, including interpolated end points
+func (tr Trace) between(start, end time.Time) []base.Location {
+	locs := []base.Location{tr.position(start)}
+	for _, p := range tr {
+		if p.Time.After(start) && p.Time.Before(end) {
+			locs = append(locs, p.Location)
+		}
+	}
+	return append(locs, tr.position(end))
+}
+
+// Render renders frames of the trace being progressively drawn with a head marker at the current position
+func (p *Playback) Render(trace Trace, opts *Opts) (*Animation, error) {
+	if len(trace) < 2 {
+		return nil, fmt.Errorf("Playback requires at least two trace points (received %d)", len(trace))
+	}
+
+	o := withDefaults(opts)
+
+	sorted := make(Trace, len(trace))
+	copy(sorted, trace)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	basemap, gt, err := p.maps.RenderBBox(o.MapID, frameBBox(sorted, o), o.Width, o.Height, o.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := sorted[0].Time, sorted[len(sorted)-1].Time
+	duration := end.Sub(start)
+
+	// Frames crossing the antimeridian start west of it, so locations east of it are shifted a world width
+	toLocal := func(locs []base.Location) []maps.Vec {
+		points := make([]maps.Vec, len(locs))
+		for i, l := range locs {
+			x, y := maps.MercatorLocationToMeters(l.Latitude, l.Longitude)
+			if x < gt.OriginX {
+				x += 2 * math.Pi * maps.MercatorRadius
+			}
+			points[i].X, points[i].Y = gt.CoordinateToPixel(x, y)
+		}
+		return points
+	}
+
+	anim := Animation{
+		Frames: make([]*image.NRGBA, o.Frames),
+		Delay:  time.Duration(float64(time.Second) / o.FPS),
+	}
+
+	for i := range anim.Frames {
+		// The final frame shows the complete trace
+		t := end
+		if o.Frames > 1 {
+			t = start.Add(time.Duration(float64(duration) * float64(i) / float64(o.Frames-1)))
+		}
+		from := start
+		if o.Trail > 0 && t.Sub(start) > o.Trail {
+			from = t.Add(-o.Trail)
+		}
+
+		frame := image.NewNRGBA(image.Rect(0, 0, o.Width, o.Height))
+		draw.Draw(frame, frame.Bounds(), basemap, basemap.Bounds().Min, draw.Src)
+		tile := maps.Tile{Image: frame}
+
+		tile.StrokeLocal(toLocal(sorted.between(from, t)), false, o.Line)
+
+		head := toLocal([]base.Location{sorted.position(t)})[0]
+		if err := tile.DrawMarkerLocal(o.Head, head.X, head.Y); err != nil {
+			return nil, err
+		}
+
+		anim.Frames[i] = frame
+	}
+
+	return &anim, nil
+}
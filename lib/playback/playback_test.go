@@ -0,0 +1,192 @@
+/**
+ * go-mapbox Playback Module Tests
+ * Uses a solid colour basemap so no API token is required
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package playback
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/map_matching"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+var basemapColor = color.NRGBA{R: 240, G: 240, B: 240, A: 255}
+
+// solidCache returns a solid colour tile for every request
+type solidCache struct{}
+
+func (c solidCache) Save(mapID maps.MapID, x, y, level uint64, format maps.MapFormat, highDPI bool, img image.Image) error {
+	return nil
+}
+
+func (c solidCache) Fetch(mapID maps.MapID, x, y, level uint64, format maps.MapFormat, highDPI bool) (image.Image, *image.Config, error) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	draw.Draw(img, img.Bounds(), image.NewUniform(basemapColor), image.Point{}, draw.Src)
+	cfg := image.Config{ColorModel: img.ColorModel(), Width: 256, Height: 256}
+	return img, &cfg, nil
+}
+
+func newPlayback(t *testing.T) *Playback {
+	b, err := base.NewBase("synthetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := maps.NewMaps(b)
+	m.SetCache(solidCache{})
+	return NewPlayback(m)
+}
+
+func colorAt(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestPlayback(t *testing.T) {
+	p := newPlayback(t)
+	start := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	trace := Trace{
+		{Location: base.Location{Latitude: 0, Longitude: 174.77}, Time: start},
+		{Location: base.Location{Latitude: 0, Longitude: 174.79}, Time: start.Add(time.Minute)},
+		{Location: base.Location{Latitude: 0.02, Longitude: 174.79}, Time: start.Add(2 * time.Minute)},
+	}
+	red := color.NRGBA{R: 255, A: 255}
+	opts := Opts{Width: 200, Height: 200, Frames: 5, FPS: 4, Head: maps.Marker{Shape: maps.MarkerSquare, Color: red, Outline: red, Size: 10}}
+
+	anim, err := p.Render(trace, &opts)
+	assert.Nil(t, err)
+	assert.Len(t, anim.Frames, 5)
+	assert.EqualValues(t, 250*time.Millisecond, anim.Delay)
+
+	// The trace fits within the padded frame, starting bottom left and ending top right
+	first, last := anim.Frames[0], anim.Frames[4]
+	assert.EqualValues(t, red, colorAt(first, DefaultPadding, 200-DefaultPadding-1))
+	assert.EqualValues(t, basemapColor, colorAt(first, 200-DefaultPadding-1, DefaultPadding))
+	assert.EqualValues(t, red, colorAt(last, 200-DefaultPadding-1, DefaultPadding))
+
+	// The head is at the corner half way through, and the trail is drawn behind it
+	assert.EqualValues(t, red, colorAt(anim.Frames[2], 200-DefaultPadding-1, 200-DefaultPadding-1))
+	assert.NotEqual(t, basemapColor, colorAt(last, 100, 200-DefaultPadding-1))
+
+	t.Run("Pads frames across the antimeridian", func(t *testing.T) {
+		shifted := make(Trace, len(trace))
+		for i, p := range trace {
+			shifted[i] = p
+			shifted[i].Location.Longitude += 179.999 - 174.79
+		}
+
+		bbox := frameBBox(shifted, withDefaults(&opts))
+		assert.True(t, bbox[0] > bbox[2], "bounding box crosses the antimeridian")
+
+		anim, err := p.Render(shifted, &opts)
+		assert.Nil(t, err)
+		assert.EqualValues(t, red, colorAt(anim.Frames[0], DefaultPadding, 200-DefaultPadding-1))
+		assert.EqualValues(t, red, colorAt(anim.Frames[4], 200-DefaultPadding-1, DefaultPadding))
+	})
+
+	t.Run("Limits trail length", func(t *testing.T) {
+		o := opts
+		o.Trail = 30 * time.Second
+		anim, err := p.Render(trace, &o)
+		assert.Nil(t, err)
+		assert.EqualValues(t, basemapColor, colorAt(anim.Frames[4], 100, 200-DefaultPadding-1))
+	})
+
+	t.Run("Encodes animated GIFs", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		assert.Nil(t, anim.EncodeGIF(&buf))
+
+		g, err := gif.DecodeAll(&buf)
+		assert.Nil(t, err)
+		assert.Len(t, g.Image, 5)
+		assert.EqualValues(t, []int{25, 25, 25, 25, 25}, g.Delay)
+	})
+
+	t.Run("Encodes animated PNGs", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		assert.Nil(t, anim.EncodeAPNG(&buf))
+
+		// The default image is the first frame
+		img, err := png.Decode(bytes.NewReader(buf.Bytes()))
+		assert.Nil(t, err)
+		assert.EqualValues(t, colorAt(first, 100, 100), colorAt(img, 100, 100))
+
+		chunks, err := readChunks(buf.Bytes())
+		assert.Nil(t, err)
+
+		counts := map[string]int{}
+		sequence := uint32(0)
+		for _, c := range chunks {
+			counts[c.name]++
+			switch c.name {
+			case "acTL":
+				assert.EqualValues(t, 5, binary.BigEndian.Uint32(c.data))
+			case "fcTL", "fdAT":
+				assert.EqualValues(t, sequence, binary.BigEndian.Uint32(c.data))
+				sequence++
+			}
+		}
+		assert.EqualValues(t, 1, counts["acTL"])
+		assert.EqualValues(t, 5, counts["fcTL"])
+		assert.True(t, counts["fdAT"] >= 4)
+		assert.EqualValues(t, "IEND", chunks[len(chunks)-1].name)
+	})
+
+	t.Run("Encodes frames of differing opacity", func(t *testing.T) {
+		clear := image.NewNRGBA(first.Bounds())
+		mixed := Animation{Frames: []*image.NRGBA{first, clear}, Delay: 90 * time.Second}
+
+		buf := bytes.Buffer{}
+		assert.Nil(t, mixed.EncodeAPNG(&buf))
+
+		chunks, err := readChunks(buf.Bytes())
+		assert.Nil(t, err)
+		for _, c := range chunks {
+			if c.name == "fcTL" {
+				assert.EqualValues(t, 9000, binary.BigEndian.Uint16(c.data[20:]))
+				assert.EqualValues(t, 100, binary.BigEndian.Uint16(c.data[22:]))
+			}
+		}
+
+		mixed.Delay = 100000 * time.Second
+		assert.NotNil(t, mixed.EncodeAPNG(&bytes.Buffer{}))
+	})
+
+	t.Run("Rejects short traces", func(t *testing.T) {
+		_, err := p.Render(trace[:1], nil)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTraceFromMatching(t *testing.T) {
+	start := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+	input := Trace{{Time: start}, {Time: start.Add(time.Second)}, {Time: start.Add(2 * time.Second)}}
+	res := mapmatching.MatchingResponse{Tracepoint: []mapmatching.TracePoint{
+		{Location: []float64{174.77, -41.29}},
+		{},
+		{Location: []float64{174.78, -41.28}},
+	}}
+
+	trace, err := TraceFromMatching(input, &res)
+	assert.Nil(t, err)
+	assert.Len(t, trace, 2)
+	assert.EqualValues(t, base.Location{Latitude: -41.28, Longitude: 174.78}, trace[1].Location)
+	assert.EqualValues(t, start.Add(2*time.Second), trace[1].Time)
+
+	_, err = TraceFromMatching(input[:2], &res)
+	assert.NotNil(t, err)
+}
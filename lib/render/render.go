@@ -0,0 +1,119 @@
+/**
+ * go-mapbox Render Module
+ * Draws GeoJSON feature collections onto map tiles and stitched tiles
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package render
+
+import (
+	"fmt"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/geojson"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// styledGeometry is a geometry and the resolved style of its feature
+type styledGeometry struct {
+	geometry   *geojson.Geometry
+	style      Style
+	properties map[string]interface{}
+}
+
+// Render draws a feature collection onto a (possibly stitched) tile
+// Polygons are drawn first, then lines, then point markers and labels, so points are never obscured
+func Render(t *maps.Tile, fc *geojson.FeatureCollection, style Style) error {
+	polygons, lines, points := make([]styledGeometry, 0), make([]styledGeometry, 0), make([]styledGeometry, 0)
+
+	var collect func(g *geojson.Geometry, s Style, props map[string]interface{}) error
+	collect = func(g *geojson.Geometry, s Style, props map[string]interface{}) error {
+		if g == nil {
+			return nil
+		}
+		sg := styledGeometry{g, s, props}
+
+		switch g.Type {
+		case geojson.GeometryPoint, geojson.GeometryMultiPoint:
+			points = append(points, sg)
+		case geojson.GeometryLineString, geojson.GeometryMultiLineString:
+			lines = append(lines, sg)
+		case geojson.GeometryPolygon, geojson.GeometryMultiPolygon:
+			polygons = append(polygons, sg)
+		case geojson.GeometryGeometryCollection:
+			for _, child := range g.Geometries {
+				if err := collect(child, s, props); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("Unsupported geometry type (%s)", g.Type)
+		}
+		return nil
+	}
+
+	for _, f := range fc.Features {
+		s, err := style.ForFeature(f)
+		if err != nil {
+			return err
+		}
+		if err := collect(f.Geometry, s, f.Properties); err != nil {
+			return err
+		}
+	}
+
+	for _, sg := range polygons {
+		rings := sg.geometry.Polygon
+		if sg.geometry.Type == geojson.GeometryPolygon {
+			drawPolygon(t, rings, sg.style.Polygon)
+			continue
+		}
+		for _, p := range sg.geometry.MultiPolygon {
+			drawPolygon(t, p, sg.style.Polygon)
+		}
+	}
+
+	for _, sg := range lines {
+		if sg.geometry.Type == geojson.GeometryLineString {
+			t.DrawPolyline(geojson.Locations(sg.geometry.LineString), sg.style.Line)
+			continue
+		}
+		for _, l := range sg.geometry.MultiLineString {
+			t.DrawPolyline(geojson.Locations(l), sg.style.Line)
+		}
+	}
+
+	layer := maps.NewLabelLayerForTile(t)
+	for _, sg := range points {
+		locs := []base.Location{geojson.Location(sg.geometry.Point)}
+		if sg.geometry.Type == geojson.GeometryMultiPoint {
+			locs = geojson.Locations(sg.geometry.MultiPoint)
+		}
+
+		text := ""
+		if p := sg.style.Point.LabelProperty; p != "" {
+			if v, ok := sg.properties[p]; ok && v != nil {
+				text = fmt.Sprint(v)
+			}
+		}
+
+		for _, loc := range locs {
+			marker := sg.style.Point.Marker
+			if _, err := layer.Add(maps.Label{Location: loc, Text: text, Style: sg.style.Point.LabelStyle, Marker: &marker}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return layer.Draw(t)
+}
+
+func drawPolygon(t *maps.Tile, rings [][][]float64, style PolygonStyle) {
+	locs := make([][]base.Location, len(rings))
+	for i, r := range rings {
+		locs[i] = geojson.Locations(r)
+	}
+	t.DrawPolygon(locs, style.Fill, style.Stroke)
+}
@@ -0,0 +1,160 @@
+/**
+ * go-mapbox Render Module Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/geojson"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+func colorAt(t *maps.Tile, loc base.Location) color.NRGBA {
+	p := t.LocationToLocal(loc)
+	return color.NRGBAModel.Convert(t.At(int(p.X), int(p.Y))).(color.NRGBA)
+}
+
+func TestStyle(t *testing.T) {
+	t.Run("Parses colours", func(t *testing.T) {
+		c, err := ParseColor("#f00")
+		assert.Nil(t, err)
+		assert.EqualValues(t, color.NRGBA{255, 0, 0, 255}, c)
+
+		c, err = ParseColor("00ff7f")
+		assert.Nil(t, err)
+		assert.EqualValues(t, color.NRGBA{0, 255, 127, 255}, c)
+
+		_, err = ParseColor("#ff00")
+		assert.NotNil(t, err)
+		_, err = ParseColor("#gggggg")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Applies simplestyle properties", func(t *testing.T) {
+		f := geojson.NewFeature(nil)
+		f.Properties[PropertyStroke] = "#0000ff"
+		f.Properties[PropertyStrokeWidth] = 5.0
+		f.Properties[PropertyStrokeOpacity] = 0.5
+		f.Properties[PropertyFill] = "#00ff00"
+		f.Properties[PropertyFillOpacity] = "0.25"
+		f.Properties[PropertyMarkerColor] = "#ff0000"
+		f.Properties[PropertyMarkerSize] = "large"
+		f.Properties[PropertyMarkerSymbol] = 7.0
+
+		s, err := DefaultStyle().ForFeature(f)
+		assert.Nil(t, err)
+		assert.EqualValues(t, color.NRGBA{0, 0, 255, 128}, s.Line.Color)
+		assert.EqualValues(t, 5, s.Line.Width)
+		assert.EqualValues(t, s.Line.Color, s.Polygon.Stroke.Color)
+		assert.EqualValues(t, color.NRGBA{0, 255, 0, 64}, s.Polygon.Fill)
+		assert.EqualValues(t, color.NRGBA{255, 0, 0, 255}, s.Point.Marker.Color)
+		assert.EqualValues(t, 28, s.Point.Marker.Size)
+		assert.EqualValues(t, "7", s.Point.Marker.Label)
+
+		// The base style is unchanged
+		assert.NotEqual(t, s.Line.Color, DefaultStyle().Line.Color)
+
+		// Properties are ignored unless enabled
+		plain := DefaultStyle()
+		plain.SimpleStyle = false
+		s, err = plain.ForFeature(f)
+		assert.Nil(t, err)
+		assert.EqualValues(t, plain.Line.Color, s.Line.Color)
+	})
+
+	t.Run("Keeps custom polygon outlines", func(t *testing.T) {
+		red := color.NRGBA{255, 0, 0, 255}
+		style := DefaultStyle()
+		style.Polygon.Stroke = &maps.StrokeStyle{Color: red, Width: 3}
+
+		f := geojson.NewFeature(nil)
+		f.Properties["name"] = "Springfield"
+		s, err := style.ForFeature(f)
+		assert.Nil(t, err)
+		assert.EqualValues(t, maps.StrokeStyle{Color: red, Width: 3}, *s.Polygon.Stroke)
+		assert.EqualValues(t, style.Line, s.Line)
+
+		// Only the properties present are applied
+		f.Properties[PropertyStrokeWidth] = 1.0
+		s, err = style.ForFeature(f)
+		assert.Nil(t, err)
+		assert.EqualValues(t, maps.StrokeStyle{Color: red, Width: 1}, *s.Polygon.Stroke)
+		assert.EqualValues(t, style.Line.Color, s.Line.Color)
+	})
+
+	t.Run("Rejects invalid properties", func(t *testing.T) {
+		for k, v := range map[string]interface{}{
+			PropertyStroke:      12.0,
+			PropertyStrokeWidth: "wide",
+			PropertyMarkerSize:  "huge",
+		} {
+			f := geojson.NewFeature(nil)
+			f.Properties[k] = v
+			_, err := DefaultStyle().ForFeature(f)
+			assert.NotNil(t, err, "property %s", k)
+		}
+	})
+}
+
+func TestRender(t *testing.T) {
+	level := uint64(12)
+	center := base.Location{Latitude: -41.2865, Longitude: 174.7762}
+	x, y := maps.LocationToTileID(center, level)
+
+	img := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	tile := maps.NewTile(x-1, y-1, level, maps.SizeStandard, img)
+
+	offset := func(dlat, dlng float64) base.Location {
+		return base.Location{Latitude: center.Latitude + dlat, Longitude: center.Longitude + dlng}
+	}
+
+	fc := geojson.NewFeatureCollection()
+
+	polygon := geojson.NewFeature(geojson.NewPolygonGeometry([][]base.Location{{
+		offset(-0.02, -0.02), offset(-0.02, 0.02), offset(0.02, 0.02), offset(0.02, -0.02),
+	}}))
+	polygon.Properties[PropertyFill] = "#00ff00"
+	polygon.Properties[PropertyFillOpacity] = 1.0
+	fc.AddFeature(polygon)
+
+	line := geojson.NewFeature(geojson.NewLineStringGeometry([]base.Location{offset(0, -0.04), offset(0, 0.04)}))
+	line.Properties[PropertyStroke] = "#0000ff"
+	line.Properties[PropertyStrokeWidth] = 6
+	fc.AddFeature(line)
+
+	point := geojson.NewFeature(geojson.NewPointGeometry(offset(-0.01, 0.01)))
+	point.Properties[PropertyMarkerColor] = "#ff0000"
+	point.Properties[PropertyMarkerSymbol] = "A"
+	point.Properties["title"] = "Wellington"
+	fc.AddFeature(point)
+
+	err := Render(&tile, fc, DefaultStyle())
+	assert.Nil(t, err)
+
+	// Polygon fill, with the line drawn over it and the marker over both
+	assert.EqualValues(t, color.NRGBA{0, 255, 0, 255}, colorAt(&tile, offset(0.01, -0.01)))
+	assert.EqualValues(t, color.NRGBA{0, 0, 255, 255}, colorAt(&tile, offset(0, -0.03)))
+	assert.EqualValues(t, color.NRGBA{255, 255, 255, 255}, colorAt(&tile, offset(0.03, 0.03)))
+
+	tip := tile.LocationToLocal(offset(-0.01, 0.01))
+	c := color.NRGBAModel.Convert(tile.At(int(tip.X)+4, int(tip.Y)-20)).(color.NRGBA)
+	assert.EqualValues(t, color.NRGBA{255, 0, 0, 255}, c)
+
+	t.Run("Rejects unsupported geometries", func(t *testing.T) {
+		bad := geojson.NewFeatureCollection()
+		bad.AddFeature(geojson.NewFeature(&geojson.Geometry{Type: "Circle"}))
+		assert.NotNil(t, Render(&tile, bad, DefaultStyle()))
+	})
+}
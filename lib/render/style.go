@@ -0,0 +1,253 @@
+/**
+ * go-mapbox Render Module Styles
+ * Style specifications and simplestyle-spec feature property support
+ * See https://github.com/mapbox/simplestyle-spec for property information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/tumasgiu/go-mapbox/lib/geojson"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// Simplestyle-spec feature properties
+const (
+	PropertyStroke        = "stroke"
+	PropertyStrokeWidth   = "stroke-width"
+	PropertyStrokeOpacity = "stroke-opacity"
+	PropertyFill          = "fill"
+	PropertyFillOpacity   = "fill-opacity"
+	PropertyMarkerColor   = "marker-color"
+	PropertyMarkerSize    = "marker-size"
+	PropertyMarkerSymbol  = "marker-symbol"
+)
+
+// MarkerSize is a simplestyle-spec marker size
+type MarkerSize string
+
+// Marker sizes
+const (
+	MarkerSizeSmall  MarkerSize = "small"
+	MarkerSizeMedium MarkerSize = "medium"
+	MarkerSizeLarge  MarkerSize = "large"
+)
+
+// markerSizes maps simplestyle marker sizes to marker widths in pixels
+var markerSizes = map[MarkerSize]float64{
+	MarkerSizeSmall:  14,
+	MarkerSizeMedium: maps.DefaultMarkerSize,
+	MarkerSizeLarge:  28,
+}
+
+// maxSymbolLength is the longest marker-symbol drawn as a label, longer symbols (such as maki icon names) are ignored
+const maxSymbolLength = 2
+
+// PointStyle configures drawing of point geometries
+type PointStyle struct {
+	Marker        maps.Marker
+	LabelProperty string         // Optional feature property drawn as a label beside the marker
+	LabelStyle    maps.TextStyle // Label text style
+}
+
+// PolygonStyle configures drawing of polygon geometries
+type PolygonStyle struct {
+	Fill   color.Color       // Optional fill colour
+	Stroke *maps.StrokeStyle // Optional outline
+}
+
+// Style configures drawing of each geometry type
+type Style struct {
+	Point       PointStyle
+	Line        maps.StrokeStyle
+	Polygon     PolygonStyle
+	SimpleStyle bool // Apply simplestyle-spec feature properties over the style
+}
+
+// DefaultStyle returns a style matching the simplestyle-spec defaults with feature properties enabled
+func DefaultStyle() Style {
+	gray := color.NRGBA{0x55, 0x55, 0x55, 0xff}
+	stroke := maps.StrokeStyle{Color: gray, Width: 2, Cap: maps.LineCapRound, Join: maps.LineJoinRound}
+
+	return Style{
+		Point: PointStyle{
+			Marker:        maps.Marker{Shape: maps.MarkerPin, Color: color.NRGBA{0x7e, 0x7e, 0x7e, 0xff}, Size: maps.DefaultMarkerSize},
+			LabelProperty: "title",
+			LabelStyle:    maps.TextStyle{Color: color.Black, HaloColor: color.White, HaloWidth: 1},
+		},
+		Line: stroke,
+		Polygon: PolygonStyle{
+			Fill:   withOpacity(gray, 0.6),
+			Stroke: &stroke,
+		},
+		SimpleStyle: true,
+	}
+}
+
+// ParseColor parses a hex colour in #rgb or #rrggbb form (the leading # is optional)
+func ParseColor(s string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return color.NRGBA{}, fmt.Errorf("Invalid colour (%s)", s)
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("Invalid colour (%s)", s)
+	}
+
+	return color.NRGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}, nil
+}
+
+// withOpacity scales the alpha of a colour by the provided opacity
+func withOpacity(c color.Color, opacity float64) color.Color {
+	if c == nil {
+		return nil
+	}
+	if opacity < 0 {
+		opacity = 0
+	} else if opacity > 1 {
+		opacity = 1
+	}
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(float64(n.A)*opacity + 0.5)
+	return n
+}
+
+// colorProperty fetches a colour property if present
+func colorProperty(props map[string]interface{}, key string) (color.Color, bool, error) {
+	v, ok := props[key]
+	if !ok || v == nil {
+		return nil, false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, false, fmt.Errorf("Property %s must be a string (received %T)", key, v)
+	}
+	c, err := ParseColor(s)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, true, nil
+}
+
+// numberProperty fetches a numeric property if present, accepting numeric strings
+func numberProperty(props map[string]interface{}, key string) (float64, bool, error) {
+	v, ok := props[key]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, true, nil
+	case float32:
+		return float64(n), true, nil
+	case int:
+		return float64(n), true, nil
+	case int64:
+		return float64(n), true, nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("Property %s must be a number (received %s)", key, n)
+		}
+		return f, true, nil
+	default:
+		return 0, false, fmt.Errorf("Property %s must be a number (received %T)", key, v)
+	}
+}
+
+// applyStroke applies the stroke properties present on a feature over a stroke style
+func applyStroke(props map[string]interface{}, stroke maps.StrokeStyle) (maps.StrokeStyle, error) {
+	if c, ok, err := colorProperty(props, PropertyStroke); err != nil {
+		return stroke, err
+	} else if ok {
+		stroke.Color = c
+	}
+	if w, ok, err := numberProperty(props, PropertyStrokeWidth); err != nil {
+		return stroke, err
+	} else if ok {
+		stroke.Width = w
+	}
+	if o, ok, err := numberProperty(props, PropertyStrokeOpacity); err != nil {
+		return stroke, err
+	} else if ok {
+		stroke.Color = withOpacity(stroke.Color, o)
+	}
+	return stroke, nil
+}
+
+// ForFeature returns the style for a feature, applying simplestyle-spec properties when enabled
+func (s Style) ForFeature(f *geojson.Feature) (Style, error) {
+	if !s.SimpleStyle || f.Properties == nil {
+		return s, nil
+	}
+	props := f.Properties
+
+	// Lines and polygon outlines, each keeping their own style where properties are not set
+	line, err := applyStroke(props, s.Line)
+	if err != nil {
+		return s, err
+	}
+	s.Line = line
+
+	if s.Polygon.Stroke != nil {
+		outline, err := applyStroke(props, *s.Polygon.Stroke)
+		if err != nil {
+			return s, err
+		}
+		s.Polygon.Stroke = &outline
+	}
+
+	// Polygon fills
+	if c, ok, err := colorProperty(props, PropertyFill); err != nil {
+		return s, err
+	} else if ok {
+		// Preserve the style fill opacity unless overridden
+		if s.Polygon.Fill != nil {
+			_, _, _, a := s.Polygon.Fill.RGBA()
+			c = withOpacity(c, float64(a)/0xffff)
+		}
+		s.Polygon.Fill = c
+	}
+	if o, ok, err := numberProperty(props, PropertyFillOpacity); err != nil {
+		return s, err
+	} else if ok && s.Polygon.Fill != nil {
+		n := color.NRGBAModel.Convert(s.Polygon.Fill).(color.NRGBA)
+		n.A = 0xff
+		s.Polygon.Fill = withOpacity(n, o)
+	}
+
+	// Point markers
+	if c, ok, err := colorProperty(props, PropertyMarkerColor); err != nil {
+		return s, err
+	} else if ok {
+		s.Point.Marker.Color = c
+	}
+	if v, ok := props[PropertyMarkerSize]; ok && v != nil {
+		size, ok := markerSizes[MarkerSize(fmt.Sprint(v))]
+		if !ok {
+			return s, fmt.Errorf("Unsupported marker size (%v)", v)
+		}
+		s.Point.Marker.Size = size
+	}
+	if v, ok := props[PropertyMarkerSymbol]; ok && v != nil {
+		symbol := fmt.Sprint(v)
+		if len([]rune(symbol)) <= maxSymbolLength {
+			s.Point.Marker.Label = symbol
+		}
+	}
+
+	return s, nil
+}
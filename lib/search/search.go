@@ -0,0 +1,323 @@
+/**
+ * go-mapbox Search Module
+ * Wraps the Search Box API for interactive POI and address search
+ * Suggest and retrieve requests are billed as sessions, grouped by a session token
+ * See https://docs.mapbox.com/api/search/search-box/ for API information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package search
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+	"github.com/tumasgiu/go-mapbox/lib/base"
+)
+
+const (
+	apiPath = "search/searchbox/v1"
+
+	// MaxSuggestLimit is the maximum number of suggestions
+	MaxSuggestLimit = 10
+	// MaxForwardLimit is the maximum number of forward search results
+	MaxForwardLimit = 10
+	// MaxCategoryLimit is the maximum number of category search results
+	MaxCategoryLimit = 25
+)
+
+// Type defines search result types
+type Type string
+
+const (
+	// Country level
+	Country Type = "country"
+	// Region level
+	Region Type = "region"
+	// Postcode level
+	Postcode Type = "postcode"
+	// District level
+	District Type = "district"
+	// Place level
+	Place Type = "place"
+	// City level
+	City Type = "city"
+	// Locality level
+	Locality Type = "locality"
+	// Neighborhood level
+	Neighborhood Type = "neighborhood"
+	// Street level
+	Street Type = "street"
+	// Address level
+	Address Type = "address"
+	// POI (Point of Interest) level
+	POI Type = "poi"
+	// Category suggestions, searched with Category
+	Category Type = "category"
+	// Brand suggestions
+	Brand Type = "brand"
+)
+
+// types is the set of supported result types
+var types = map[string]bool{
+	string(Country): true, string(Region): true, string(Postcode): true, string(District): true, string(Place): true,
+	string(City): true, string(Locality): true, string(Neighborhood): true, string(Street): true, string(Address): true,
+	string(POI): true, string(Category): true, string(Brand): true,
+}
+
+// validateTypes checks each type is supported
+func validateTypes(t []Type) error {
+	for _, v := range t {
+		if err := base.ValidateType(string(v), types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search api wrapper instance
+type Search struct {
+	base *base.Base
+}
+
+// NewSearch Create a new Search API wrapper
+func NewSearch(base *base.Base) *Search {
+	return &Search{base}
+}
+
+// validateFilter checks the embedded filter, the Search Box API accepts a single language
+func validateFilter(f *base.Filter) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	return f.ValidateSingleLanguage()
+}
+
+func validateLimit(limit, max uint) error {
+	if limit > max {
+		return fmt.Errorf("Limit (%d) exceeds maximum of %d", limit, max)
+	}
+	return nil
+}
+
+// SuggestOpts request options for suggestions
+type SuggestOpts struct {
+	base.Filter
+	Limit                 uint      `url:"limit,omitempty"`
+	Types                 []Type    `url:"types,comma,omitempty"`
+	POICategory           []string  `url:"poi_category,comma,omitempty"`
+	POICategoryExclusions []string  `url:"poi_category_exclusions,comma,omitempty"`
+	Origin                []float64 `url:"origin,comma,omitempty"`       // Location distances and ETAs are measured from
+	NavigationProfile     string    `url:"navigation_profile,omitempty"` // Routing profile for ETAs, eg. driving
+	ETAType               string    `url:"eta_type,omitempty"`           // Set to navigation to include ETAs
+	Worldview             string    `url:"worldview,omitempty"`
+}
+
+// Validate checks suggest options before a request is made
+func (o *SuggestOpts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := validateFilter(&o.Filter); err != nil {
+		return err
+	}
+	if err := validateLimit(o.Limit, MaxSuggestLimit); err != nil {
+		return err
+	}
+	if err := validateTypes(o.Types); err != nil {
+		return err
+	}
+	if err := base.ValidateLocation("Origin", o.Origin); err != nil {
+		return err
+	}
+	return base.ValidateWorldview(o.Worldview)
+}
+
+// Suggest fetches suggestions for a partial query, under a session token (see base.NewSessionToken)
+// Suggestions do not include locations, use Retrieve with the same session token to locate the selected suggestion
+func (s *Search) Suggest(q, sessionToken string, opts *SuggestOpts) (*SuggestResponse, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, fmt.Errorf("Suggest requires a search query")
+	}
+	if sessionToken == "" {
+		return nil, fmt.Errorf("Suggest requires a session token")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if opts != nil {
+		var err error
+		if v, err = opts.Filter.Values(opts); err != nil {
+			return nil, err
+		}
+	}
+	v.Set("q", q)
+	v.Set("session_token", sessionToken)
+
+	resp := SuggestResponse{}
+	err := s.base.QueryBase(fmt.Sprintf("%s/suggest", apiPath), &v, &resp)
+
+	return &resp, err
+}
+
+// RetrieveOpts request options for retrieving suggestions
+type RetrieveOpts struct {
+	Language  string `url:"language,omitempty"`
+	Worldview string `url:"worldview,omitempty"`
+}
+
+// Validate checks retrieve options before a request is made
+func (o *RetrieveOpts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if o.Language != "" {
+		if err := base.ValidateLanguages([]string{o.Language}); err != nil {
+			return err
+		}
+	}
+	return base.ValidateWorldview(o.Worldview)
+}
+
+// Retrieve fetches the feature for a suggestion, ending the session the token identifies
+func (s *Search) Retrieve(mapboxID, sessionToken string, opts *RetrieveOpts) (*FeatureCollection, error) {
+	if mapboxID == "" {
+		return nil, fmt.Errorf("Retrieve requires a Mapbox ID")
+	}
+	if sessionToken == "" {
+		return nil, fmt.Errorf("Retrieve requires a session token")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if opts != nil {
+		var err error
+		if v, err = query.Values(opts); err != nil {
+			return nil, err
+		}
+	}
+	v.Set("session_token", sessionToken)
+
+	resp := FeatureCollection{}
+	err := s.base.QueryBase(fmt.Sprintf("%s/retrieve/%s", apiPath, url.PathEscape(mapboxID)), &v, &resp)
+
+	return &resp, err
+}
+
+// CategoryOpts request options for category search
+type CategoryOpts struct {
+	base.Filter
+	Limit                 uint     `url:"limit,omitempty"`
+	POICategoryExclusions []string `url:"poi_category_exclusions,comma,omitempty"`
+}
+
+// Validate checks category search options before a request is made
+func (o *CategoryOpts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := validateFilter(&o.Filter); err != nil {
+		return err
+	}
+	return validateLimit(o.Limit, MaxCategoryLimit)
+}
+
+// Category searches for POIs in a category, using the canonical ID from ListCategories, eg. coffee
+func (s *Search) Category(categoryID string, opts *CategoryOpts) (*FeatureCollection, error) {
+	if categoryID == "" {
+		return nil, fmt.Errorf("Category search requires a category ID")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if opts != nil {
+		var err error
+		if v, err = opts.Filter.Values(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := FeatureCollection{}
+	err := s.base.QueryBase(fmt.Sprintf("%s/category/%s", apiPath, url.PathEscape(categoryID)), &v, &resp)
+
+	return &resp, err
+}
+
+// ListCategories fetches the categories supported by category search, with names in the (optional) language
+func (s *Search) ListCategories(language string) (*CategoryList, error) {
+	v := url.Values{}
+	if language != "" {
+		if err := base.ValidateLanguages([]string{language}); err != nil {
+			return nil, err
+		}
+		v.Set("language", language)
+	}
+
+	resp := CategoryList{}
+	err := s.base.QueryBase(fmt.Sprintf("%s/list/category", apiPath), &v, &resp)
+
+	return &resp, err
+}
+
+// ForwardOpts request options for forward search
+type ForwardOpts struct {
+	base.Filter
+	Limit             uint      `url:"limit,omitempty"`
+	Types             []Type    `url:"types,comma,omitempty"`
+	POICategory       []string  `url:"poi_category,comma,omitempty"`
+	Autocomplete      *bool     `url:"auto_complete,omitempty"`
+	Origin            []float64 `url:"origin,comma,omitempty"`
+	NavigationProfile string    `url:"navigation_profile,omitempty"`
+	ETAType           string    `url:"eta_type,omitempty"`
+}
+
+// Validate checks forward search options before a request is made
+func (o *ForwardOpts) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := validateFilter(&o.Filter); err != nil {
+		return err
+	}
+	if err := validateLimit(o.Limit, MaxForwardLimit); err != nil {
+		return err
+	}
+	if err := validateTypes(o.Types); err != nil {
+		return err
+	}
+	return base.ValidateLocation("Origin", o.Origin)
+}
+
+// Forward searches for located POIs and places in a single request, without a session
+func (s *Search) Forward(q string, opts *ForwardOpts) (*FeatureCollection, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, fmt.Errorf("Forward search requires a search query")
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if opts != nil {
+		var err error
+		if v, err = opts.Filter.Values(opts); err != nil {
+			return nil, err
+		}
+	}
+	v.Set("q", q)
+
+	resp := FeatureCollection{}
+	err := s.base.QueryBase(fmt.Sprintf("%s/forward", apiPath), &v, &resp)
+
+	return &resp, err
+}
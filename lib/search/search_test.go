@@ -0,0 +1,189 @@
+/**
+ * go-mapbox Search Module Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package search
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+)
+
+const testFeature = `{
+	"type": "Feature",
+	"geometry": {"type": "Point", "coordinates": [-77.0365, 38.8977]},
+	"properties": {
+		"name": "Blue Bottle Coffee",
+		"mapbox_id": "dXJuOm1ieHBvaTox",
+		"feature_type": "poi",
+		"address": "1046 Potomac St NW",
+		"full_address": "1046 Potomac St NW, Washington, District of Columbia 20007, United States",
+		"place_formatted": "Washington, District of Columbia 20007, United States",
+		"context": {
+			"postcode": {"mapbox_id": "postcode.1", "name": "20007"},
+			"place": {"mapbox_id": "place.1", "name": "Washington"},
+			"country": {"mapbox_id": "country.1", "name": "United States", "country_code": "US", "country_code_alpha_3": "USA"}
+		},
+		"coordinates": {"latitude": 38.8977, "longitude": -77.0365, "routable_points": [{"name": "default", "latitude": 38.8976, "longitude": -77.0366}]},
+		"language": "en",
+		"maki": "cafe",
+		"poi_category": ["coffee", "food"],
+		"poi_category_ids": ["coffee", "food"],
+		"brand": ["Blue Bottle Coffee"],
+		"brand_id": ["blue-bottle-coffee"],
+		"external_ids": {"foursquare": "4b1"},
+		"metadata": {
+			"phone": "+12025550100",
+			"website": "https://bluebottlecoffee.com",
+			"open_hours": {"periods": [{"open": {"day": 0, "time": "0700"}, "close": {"day": 0, "time": "1800"}}]}
+		}
+	}
+}`
+
+func TestSearch(t *testing.T) {
+	var last *http.Request
+	b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+		last = r
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/search/searchbox/v1/suggest":
+			w.Write([]byte(`{"suggestions": [{"name": "Blue Bottle Coffee", "mapbox_id": "dXJuOm1ieHBvaTox", "feature_type": "poi", "poi_category": ["coffee"], "distance": 120.5, "metadata": {}}], "attribution": "© Mapbox"}`))
+		case "/search/searchbox/v1/retrieve/dXJuOm1ieHBvaTox", "/search/searchbox/v1/category/coffee", "/search/searchbox/v1/forward":
+			w.Write([]byte(`{"type": "FeatureCollection", "features": [` + testFeature + `], "attribution": "© Mapbox"}`))
+		case "/search/searchbox/v1/list/category":
+			w.Write([]byte(`{"listItems": [{"canonical_id": "coffee", "icon": "cafe", "name": "Coffee"}], "version": "1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	})
+	s := NewSearch(b)
+	defer done()
+
+	checkFeature := func(t *testing.T, fc *FeatureCollection) {
+		require.Len(t, fc.Features, 1)
+		f := fc.Features[0]
+		assert.Equal(t, "Blue Bottle Coffee", f.Properties.Name)
+		assert.Equal(t, POI, f.Properties.FeatureType)
+		assert.Equal(t, base.Location{Latitude: 38.8977, Longitude: -77.0365}, f.Location())
+		assert.Equal(t, "US", f.Properties.Context.Country.CountryCode)
+		assert.Equal(t, "Washington", f.Properties.Context.Place.Name)
+		assert.EqualValues(t, []string{"Blue Bottle Coffee"}, f.Properties.Brand)
+		assert.Equal(t, "+12025550100", f.Properties.Metadata.Phone)
+		require.NotNil(t, f.Properties.Metadata.OpenHours)
+		assert.EqualValues(t, []OpenPeriod{{Open: OpenTime{Day: 0, Time: "0700"}, Close: OpenTime{Day: 0, Time: "1800"}}}, f.Properties.Metadata.OpenHours.Periods)
+		assert.Len(t, f.Properties.Coordinates.RoutablePoints, 1)
+	}
+
+	t.Run("Suggests and retrieves under a session token", func(t *testing.T) {
+		token, err := base.NewSessionToken()
+		require.Nil(t, err)
+
+		resp, err := s.Suggest("blue bottle", token, &SuggestOpts{
+			Filter: base.Filter{Proximity: []float64{-77.03, 38.9}, Country: "us", Language: []string{"en"}},
+			Limit:  5,
+			Types:  []Type{POI, Category},
+		})
+		require.Nil(t, err)
+		assert.EqualValues(t, url.Values{
+			"access_token":  {"synthetic"},
+			"q":             {"blue bottle"},
+			"session_token": {token},
+			"proximity":     {"-77.03,38.9"},
+			"country":       {"us"},
+			"language":      {"en"},
+			"limit":         {"5"},
+			"types":         {"poi,category"},
+		}, last.URL.Query())
+		require.Len(t, resp.Suggestions, 1)
+		sg := resp.Suggestions[0]
+		assert.Equal(t, "dXJuOm1ieHBvaTox", sg.MapboxID)
+		assert.Equal(t, 120.5, sg.Distance)
+
+		fc, err := s.Retrieve(sg.MapboxID, token, nil)
+		require.Nil(t, err)
+		assert.Equal(t, token, last.URL.Query().Get("session_token"))
+		checkFeature(t, fc)
+	})
+
+	t.Run("Searches categories", func(t *testing.T) {
+		list, err := s.ListCategories("en")
+		require.Nil(t, err)
+		assert.EqualValues(t, []CategoryItem{{CanonicalID: "coffee", Icon: "cafe", Name: "Coffee"}}, list.ListItems)
+
+		fc, err := s.Category(list.ListItems[0].CanonicalID, &CategoryOpts{Filter: base.Filter{ProximityIP: true, BBox: base.BoundingBox{-78, 38, -77, 39}}, Limit: 25})
+		require.Nil(t, err)
+		assert.Equal(t, "ip", last.URL.Query().Get("proximity"))
+		assert.Equal(t, "-78,38,-77,39", last.URL.Query().Get("bbox"))
+		checkFeature(t, fc)
+	})
+
+	t.Run("Searches forward", func(t *testing.T) {
+		fc, err := s.Forward("coffee", &ForwardOpts{Autocomplete: base.Bool(false), Types: []Type{POI}})
+		require.Nil(t, err)
+		assert.Equal(t, "false", last.URL.Query().Get("auto_complete"))
+		assert.Equal(t, "coffee", last.URL.Query().Get("q"))
+		checkFeature(t, fc)
+
+		_, err = s.Forward("coffee", nil)
+		require.Nil(t, err)
+	})
+
+	t.Run("Reports API errors", func(t *testing.T) {
+		_, err := s.Retrieve("missing", "token", nil)
+		assert.EqualError(t, err, "api error (404): Not Found")
+	})
+
+	t.Run("Validates requests", func(t *testing.T) {
+		requests := []struct {
+			name string
+			fn   func() error
+		}{
+			{"empty query", func() error { _, err := s.Suggest(" ", "token", nil); return err }},
+			{"missing token", func() error { _, err := s.Suggest("coffee", "", nil); return err }},
+			{"suggest limit", func() error { _, err := s.Suggest("coffee", "token", &SuggestOpts{Limit: 11}); return err }},
+			{"type", func() error {
+				_, err := s.Suggest("coffee", "token", &SuggestOpts{Types: []Type{"planet"}})
+				return err
+			}},
+			{"retrieve id", func() error { _, err := s.Retrieve("", "token", nil); return err }},
+			{"retrieve token", func() error { _, err := s.Retrieve("id", "", nil); return err }},
+			{"category id", func() error { _, err := s.Category("", nil); return err }},
+			{"category limit", func() error { _, err := s.Category("coffee", &CategoryOpts{Limit: 26}); return err }},
+			{"country", func() error {
+				_, err := s.Category("coffee", &CategoryOpts{Filter: base.Filter{Country: "usa"}})
+				return err
+			}},
+			{"proximity", func() error {
+				_, err := s.Forward("coffee", &ForwardOpts{Filter: base.Filter{Proximity: []float64{1, 2}, ProximityIP: true}})
+				return err
+			}},
+			{"bbox", func() error {
+				_, err := s.Forward("coffee", &ForwardOpts{Filter: base.Filter{BBox: base.BoundingBox{1, 2, 0, 3}}})
+				return err
+			}},
+			{"language", func() error { _, err := s.ListCategories("english!"); return err }},
+			{"single language", func() error {
+				_, err := s.Suggest("coffee", "token", &SuggestOpts{Filter: base.Filter{Language: []string{"en", "fr"}}})
+				return err
+			}},
+		}
+
+		last = nil
+		for _, r := range requests {
+			assert.NotNil(t, r.fn(), r.name)
+		}
+		assert.Nil(t, last)
+	})
+}
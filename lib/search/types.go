@@ -0,0 +1,119 @@
+/**
+ * go-mapbox Search Module Types
+ * Response types for the Search Box API
+ * See https://docs.mapbox.com/api/search/search-box/ for API information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package search
+
+import (
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/geocode"
+)
+
+// OpenTime is the day and time a period of opening hours starts or ends
+type OpenTime struct {
+	Day  int    `json:"day"`  // Day of the week from 0 to 6
+	Time string `json:"time"` // Local time as HHMM, eg. 0930
+}
+
+// OpenPeriod is a period a POI is open
+type OpenPeriod struct {
+	Open  OpenTime `json:"open"`
+	Close OpenTime `json:"close"`
+}
+
+// OpenHours are the opening hours of a POI
+type OpenHours struct {
+	Periods []OpenPeriod `json:"periods"`
+}
+
+// Metadata is additional POI information, where available
+type Metadata struct {
+	Phone                string     `json:"phone,omitempty"`
+	Website              string     `json:"website,omitempty"`
+	Rating               float64    `json:"rating,omitempty"`
+	ReviewCount          int        `json:"review_count,omitempty"`
+	Popularity           float64    `json:"popularity,omitempty"`
+	OpenHours            *OpenHours `json:"open_hours,omitempty"`
+	WheelchairAccessible *bool      `json:"wheelchair_accessible,omitempty"`
+	PrimaryPhoto         string     `json:"primary_photo,omitempty"`
+}
+
+// Details contains the fields common to suggestions and retrieved features
+type Details struct {
+	Name           string            `json:"name"`
+	NamePreferred  string            `json:"name_preferred,omitempty"`
+	MapboxID       string            `json:"mapbox_id"`
+	FeatureType    Type              `json:"feature_type"`
+	Address        string            `json:"address,omitempty"`
+	FullAddress    string            `json:"full_address,omitempty"`
+	PlaceFormatted string            `json:"place_formatted"`
+	Context        geocode.ContextV6 `json:"context"`
+	Language       string            `json:"language"`
+	Maki           string            `json:"maki,omitempty"`
+	POICategory    []string          `json:"poi_category,omitempty"`
+	POICategoryIDs []string          `json:"poi_category_ids,omitempty"`
+	Brand          []string          `json:"brand,omitempty"`
+	BrandID        []string          `json:"brand_id,omitempty"`
+	ExternalIDs    map[string]string `json:"external_ids,omitempty"`
+	Metadata       Metadata          `json:"metadata"`
+}
+
+// Suggestion is a result of a suggest request, which must be retrieved to obtain its location
+type Suggestion struct {
+	Details
+	Distance float64 `json:"distance,omitempty"` // Distance from the origin in metres
+	ETA      float64 `json:"eta,omitempty"`      // Travel time from the origin in minutes
+}
+
+// SuggestResponse is the response to a suggest request
+type SuggestResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+	Attribution string       `json:"attribution"`
+}
+
+// Properties are the properties of a search feature
+type Properties struct {
+	Details
+	Coordinates geocode.CoordinatesV6 `json:"coordinates"`
+	BBox        base.BoundingBox      `json:"bbox,omitempty"`
+}
+
+// Feature is a located search result
+type Feature struct {
+	Type       string        `json:"type"`
+	Geometry   base.Geometry `json:"geometry"`
+	Properties Properties    `json:"properties"`
+}
+
+// Location returns the location of the feature
+func (f *Feature) Location() base.Location {
+	return base.Location{Latitude: f.Properties.Coordinates.Latitude, Longitude: f.Properties.Coordinates.Longitude}
+}
+
+// FeatureCollection is the response to retrieve, category and forward requests
+type FeatureCollection struct {
+	Type        string    `json:"type"`
+	Features    []Feature `json:"features"`
+	Attribution string    `json:"attribution"`
+}
+
+// CategoryItem is a POI category that can be searched
+type CategoryItem struct {
+	CanonicalID string `json:"canonical_id"`
+	Icon        string `json:"icon"`
+	Name        string `json:"name"`
+	Version     string `json:"version,omitempty"`
+	UUID        string `json:"uuid,omitempty"`
+}
+
+// CategoryList is the response to a category list request
+type CategoryList struct {
+	ListItems   []CategoryItem `json:"listItems"`
+	Attribution string         `json:"attribution"`
+	Version     string         `json:"version"`
+}
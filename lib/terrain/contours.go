@@ -0,0 +1,228 @@
+/**
+ * go-mapbox Terrain Module Contours
+ * Contour line extraction using marching squares
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package terrain
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/geojson"
+)
+
+// ContourProperty is the feature property containing the contour elevation
+const ContourProperty = "elevation"
+
+// edgeKey identifies a cell edge in the grid, edges are shared between neighbouring cells
+type edgeKey struct {
+	x, y     int
+	vertical bool
+}
+
+type contourSegment struct {
+	keys   [2]edgeKey
+	points [2][2]float64
+	used   bool
+}
+
+// Contours extracts contour lines at every multiple of interval within the grid range
+// Each line is returned as a LineString feature with the elevation stored in ContourProperty
+func (g *Grid) Contours(interval float64) (*geojson.FeatureCollection, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Contour interval must be positive (received %f)", interval)
+	}
+
+	min, max := g.Range()
+
+	levels := make([]float64, 0)
+	for l := math.Ceil(min/interval) * interval; l <= max; l += interval {
+		levels = append(levels, l)
+	}
+
+	return g.ContoursAt(levels), nil
+}
+
+// ContoursAt extracts contour lines at the provided elevations
+func (g *Grid) ContoursAt(levels []float64) *geojson.FeatureCollection {
+	fc := geojson.NewFeatureCollection()
+
+	for _, level := range levels {
+		for _, line := range g.contourLines(level) {
+			locs := make([]base.Location, len(line))
+			for i, p := range line {
+				locs[i] = g.PixelToLocation(p[0], p[1])
+			}
+
+			f := geojson.NewFeature(geojson.NewLineStringGeometry(locs))
+			f.Properties[ContourProperty] = level
+			fc.AddFeature(f)
+		}
+	}
+
+	return fc
+}
+
+// contourLines runs marching squares over the grid for a single level and joins the resulting segments
+func (g *Grid) contourLines(level float64) [][][2]float64 {
+	segments := make([]*contourSegment, 0)
+	byEdge := make(map[edgeKey][]*contourSegment)
+
+	// Edge indices: 0 top, 1 right, 2 bottom, 3 left
+	addSegment := func(x, y int, e1, e2 int) {
+		s := &contourSegment{}
+		for i, e := range []int{e1, e2} {
+			s.keys[i], s.points[i] = g.contourEdge(x, y, e, level)
+			byEdge[s.keys[i]] = append(byEdge[s.keys[i]], s)
+		}
+		segments = append(segments, s)
+	}
+
+	for y := 0; y < g.Height-1; y++ {
+		for x := 0; x < g.Width-1; x++ {
+			tl, tr := g.At(x, y), g.At(x+1, y)
+			br, bl := g.At(x+1, y+1), g.At(x, y+1)
+
+			c := 0
+			if tl >= level {
+				c |= 8
+			}
+			if tr >= level {
+				c |= 4
+			}
+			if br >= level {
+				c |= 2
+			}
+			if bl >= level {
+				c |= 1
+			}
+
+			centreAbove := (tl+tr+br+bl)/4 >= level
+
+			switch c {
+			case 1, 14:
+				addSegment(x, y, 3, 2)
+			case 2, 13:
+				addSegment(x, y, 2, 1)
+			case 3, 12:
+				addSegment(x, y, 3, 1)
+			case 4, 11:
+				addSegment(x, y, 0, 1)
+			case 6, 9:
+				addSegment(x, y, 0, 2)
+			case 7, 8:
+				addSegment(x, y, 3, 0)
+			case 5:
+				if centreAbove {
+					addSegment(x, y, 3, 0)
+					addSegment(x, y, 2, 1)
+				} else {
+					addSegment(x, y, 0, 1)
+					addSegment(x, y, 3, 2)
+				}
+			case 10:
+				if centreAbove {
+					addSegment(x, y, 0, 1)
+					addSegment(x, y, 3, 2)
+				} else {
+					addSegment(x, y, 3, 0)
+					addSegment(x, y, 2, 1)
+				}
+			}
+		}
+	}
+
+	// next finds an unused segment sharing the provided edge
+	next := func(key edgeKey) *contourSegment {
+		for _, s := range byEdge[key] {
+			if !s.used {
+				return s
+			}
+		}
+		return nil
+	}
+
+	lines := make([][][2]float64, 0)
+
+	for _, start := range segments {
+		if start.used {
+			continue
+		}
+		start.used = true
+
+		line := [][2]float64{start.points[0], start.points[1]}
+
+		// Extend forwards from the end of the starting segment
+		for key := start.keys[1]; ; {
+			s := next(key)
+			if s == nil {
+				break
+			}
+			s.used = true
+			i := 0
+			if s.keys[0] == key {
+				i = 1
+			}
+			line = append(line, s.points[i])
+			key = s.keys[i]
+		}
+
+		// Then backwards from the start
+		for key := start.keys[0]; ; {
+			s := next(key)
+			if s == nil {
+				break
+			}
+			s.used = true
+			i := 0
+			if s.keys[0] == key {
+				i = 1
+			}
+			line = append([][2]float64{s.points[i]}, line...)
+			key = s.keys[i]
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// contourEdge returns the key and interpolated crossing point of a level on an edge of the cell at (x, y)
+// Points are in grid pixel space with values located at pixel centres
+func (g *Grid) contourEdge(x, y, edge int, level float64) (edgeKey, [2]float64) {
+	var x1, y1, x2, y2 int
+	var key edgeKey
+
+	switch edge {
+	case 0:
+		x1, y1, x2, y2 = x, y, x+1, y
+		key = edgeKey{x, y, false}
+	case 1:
+		x1, y1, x2, y2 = x+1, y, x+1, y+1
+		key = edgeKey{x + 1, y, true}
+	case 2:
+		x1, y1, x2, y2 = x, y+1, x+1, y+1
+		key = edgeKey{x, y + 1, false}
+	default:
+		x1, y1, x2, y2 = x, y, x, y+1
+		key = edgeKey{x, y, true}
+	}
+
+	v1, v2 := g.At(x1, y1), g.At(x2, y2)
+
+	t := 0.5
+	if v1 != v2 {
+		t = (level - v1) / (v2 - v1)
+	}
+
+	px := float64(x1) + t*float64(x2-x1) + 0.5
+	py := float64(y1) + t*float64(y2-y1) + 0.5
+
+	return key, [2]float64{px, py}
+}
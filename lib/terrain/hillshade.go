@@ -0,0 +1,125 @@
+/**
+ * go-mapbox Terrain Module Shading
+ * Hillshade, slope and aspect computed with Horn's method
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package terrain
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+const (
+	// DefaultAzimuth is the default sun azimuth in degrees clockwise from north
+	DefaultAzimuth = 315.0
+	// DefaultAltitude is the default sun altitude in degrees above the horizon
+	DefaultAltitude = 45.0
+	// FlatAspect is the aspect value returned for flat cells
+	FlatAspect = -1.0
+)
+
+// HillshadeOpts configures hillshade generation
+type HillshadeOpts struct {
+	Azimuth  float64 // Sun azimuth in degrees clockwise from north
+	Altitude float64 // Sun altitude in degrees above the horizon
+	ZFactor  float64 // Vertical exaggeration (defaults to 1)
+}
+
+// DefaultHillshade is the conventional north west, 45 degree illumination
+var DefaultHillshade = HillshadeOpts{Azimuth: DefaultAzimuth, Altitude: DefaultAltitude, ZFactor: 1}
+
+// gradient computes the east and south elevation gradients (rise over run) at a pixel using Horn's method
+// Edges are clamped so that the outermost pixels reuse their own values
+func (g *Grid) gradient(x, y int, cellSize float64) (float64, float64) {
+	a, b, c := g.At(x-1, y-1), g.At(x, y-1), g.At(x+1, y-1)
+	d, f := g.At(x-1, y), g.At(x+1, y)
+	gg, h, i := g.At(x-1, y+1), g.At(x, y+1), g.At(x+1, y+1)
+
+	dzdx := ((c + 2*f + i) - (a + 2*d + gg)) / (8 * cellSize)
+	dzdy := ((gg + 2*h + i) - (a + 2*b + c)) / (8 * cellSize)
+
+	return dzdx, dzdy
+}
+
+// Slope computes the slope of each cell in degrees
+func (g *Grid) Slope() *Grid {
+	slope := g.NewGrid()
+
+	for y := 0; y < g.Height; y++ {
+		cellSize := g.CellSize(y)
+		for x := 0; x < g.Width; x++ {
+			dzdx, dzdy := g.gradient(x, y, cellSize)
+			slope.Set(x, y, math.Atan(math.Hypot(dzdx, dzdy))*180/math.Pi)
+		}
+	}
+
+	return slope
+}
+
+// Aspect computes the downslope direction of each cell in degrees clockwise from north
+// Flat cells are set to FlatAspect
+func (g *Grid) Aspect() *Grid {
+	aspect := g.NewGrid()
+
+	for y := 0; y < g.Height; y++ {
+		cellSize := g.CellSize(y)
+		for x := 0; x < g.Width; x++ {
+			dzdx, dzdy := g.gradient(x, y, cellSize)
+			if dzdx == 0 && dzdy == 0 {
+				aspect.Set(x, y, FlatAspect)
+				continue
+			}
+			// Downslope points against the gradient, rows increase southwards
+			a := math.Atan2(-dzdx, dzdy) * 180 / math.Pi
+			if a < 0 {
+				a += 360
+			}
+			aspect.Set(x, y, a)
+		}
+	}
+
+	return aspect
+}
+
+// Hillshade renders a greyscale shaded relief image of the grid
+func (g *Grid) Hillshade(opts HillshadeOpts) *image.Gray {
+	zFactor := opts.ZFactor
+	if zFactor == 0 {
+		zFactor = 1
+	}
+
+	azimuth, altitude := opts.Azimuth*math.Pi/180, opts.Altitude*math.Pi/180
+
+	// Light vector in (east, north, up) space
+	lx := math.Sin(azimuth) * math.Cos(altitude)
+	ly := math.Cos(azimuth) * math.Cos(altitude)
+	lz := math.Sin(altitude)
+
+	img := image.NewGray(image.Rect(0, 0, g.Width, g.Height))
+
+	for y := 0; y < g.Height; y++ {
+		cellSize := g.CellSize(y)
+		for x := 0; x < g.Width; x++ {
+			dzdx, dzdy := g.gradient(x, y, cellSize)
+			dzdx, dzdy = dzdx*zFactor, dzdy*zFactor
+
+			// Surface normal in (east, north, up) space, north is against the row direction
+			nx, ny, nz := -dzdx, dzdy, 1.0
+			n := math.Sqrt(nx*nx + ny*ny + nz*nz)
+
+			shade := (nx*lx + ny*ly + nz*lz) / n
+			if shade < 0 {
+				shade = 0
+			}
+
+			img.SetGray(x, y, color.Gray{Y: uint8(math.Round(shade * 255))})
+		}
+	}
+
+	return img
+}
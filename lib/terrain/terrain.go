@@ -0,0 +1,122 @@
+/**
+ * go-mapbox Terrain Module
+ * Derivative products (hillshade, slope, aspect and contours) from Terrain-RGB tiles
+ * See https://www.mapbox.com/help/access-elevation-data/ for terrain information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package terrain
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// Grid is a raster of values positioned in web mercator tile space
+// Level, Size, X and Y match the maps.Tile the grid was derived from
+type Grid struct {
+	Width, Height int
+	Values        []float64 // Row major values
+	Level         uint64
+	Size          uint64
+	X, Y          uint64
+}
+
+// NewGrid creates an empty grid sharing the position of another grid
+func (g *Grid) NewGrid() *Grid {
+	return &Grid{
+		Width:  g.Width,
+		Height: g.Height,
+		Values: make([]float64, g.Width*g.Height),
+		Level:  g.Level,
+		Size:   g.Size,
+		X:      g.X,
+		Y:      g.Y,
+	}
+}
+
+// At fetches the value at a pixel, clamping coordinates to the grid edges
+func (g *Grid) At(x, y int) float64 {
+	if x < 0 {
+		x = 0
+	} else if x >= g.Width {
+		x = g.Width - 1
+	}
+	if y < 0 {
+		y = 0
+	} else if y >= g.Height {
+		y = g.Height - 1
+	}
+	return g.Values[y*g.Width+x]
+}
+
+// Set sets the value at a pixel
+func (g *Grid) Set(x, y int, v float64) {
+	g.Values[y*g.Width+x] = v
+}
+
+// PixelToLocation converts a (fractional) grid pixel position to a location
+// Pixel values apply to pixel centres, so (0.5, 0.5) is the centre of the top left pixel
+func (g *Grid) PixelToLocation(x, y float64) base.Location {
+	gx, gy := x+float64(g.X*g.Size), y+float64(g.Y*g.Size)
+	lat, lng := maps.MercatorPixelToLocation(gx, gy, g.Level, g.Size)
+	return base.Location{Latitude: lat, Longitude: lng}
+}
+
+// CellSize returns the ground size of a pixel in metres for the provided row
+func (g *Grid) CellSize(y int) float64 {
+	loc := g.PixelToLocation(0, float64(y)+0.5)
+	return maps.MercatorResolution(g.Level, g.Size) * math.Cos(loc.Latitude*maps.D2R)
+}
+
+// GeoTransform returns the EPSG:3857 transform for the grid
+func (g *Grid) GeoTransform() maps.GeoTransform {
+	return maps.MercatorGeoTransform(float64(g.X*g.Size), float64(g.Y*g.Size), g.Level, g.Size)
+}
+
+// Range returns the minimum and maximum values in the grid
+func (g *Grid) Range() (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, v := range g.Values {
+		min, max = math.Min(min, v), math.Max(max, v)
+	}
+	return min, max
+}
+
+// NewDEM decodes a Terrain-RGB tile into a grid of elevations in metres
+// Use maps.StitchTiles to combine tiles first so that derived products have no seams at tile edges
+func NewDEM(tile *maps.Tile) *Grid {
+	b := tile.Bounds()
+
+	dem := &Grid{
+		Width:  b.Dx(),
+		Height: b.Dy(),
+		Values: make([]float64, b.Dx()*b.Dy()),
+		Level:  tile.Level,
+		Size:   tile.Size,
+		X:      tile.X,
+		Y:      tile.Y,
+	}
+
+	nrgba, isNRGBA := tile.Image.(*image.NRGBA)
+
+	for y := 0; y < dem.Height; y++ {
+		for x := 0; x < dem.Width; x++ {
+			var c color.NRGBA
+			if isNRGBA {
+				c = nrgba.NRGBAAt(b.Min.X+x, b.Min.Y+y)
+			} else {
+				c = color.NRGBAModel.Convert(tile.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			}
+			dem.Values[y*dem.Width+x] = maps.PixelToHeight(c.R, c.G, c.B)
+		}
+	}
+
+	return dem
+}
@@ -0,0 +1,140 @@
+/**
+ * go-mapbox Terrain Module Tests
+ * Uses synthetic elevation grids
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package terrain
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+func newGrid(w, h int, f func(g *Grid, x, y int) float64) *Grid {
+	g := &Grid{Width: w, Height: h, Values: make([]float64, w*h), Level: 12, Size: 256, X: 2048, Y: 2047}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			g.Set(x, y, f(g, x, y))
+		}
+	}
+	return g
+}
+
+func TestTerrain(t *testing.T) {
+
+	t.Run("Decodes terrain RGB tiles", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 4; x++ {
+				r, g, b := maps.HeightToPixel(float64(x*100 + y))
+				img.Set(x, y, color.NRGBA{R: r, G: g, B: b, A: 255})
+			}
+		}
+		tile := maps.NewTile(1, 2, 3, 256, img)
+
+		dem := NewDEM(&tile)
+		assert.EqualValues(t, 4, dem.Width)
+		assert.EqualValues(t, 3, dem.Level)
+		assert.InDelta(t, 302, dem.At(3, 2), 0.05)
+		assert.InDelta(t, 302, dem.At(10, 2), 0.05)
+	})
+
+	// Plane rising towards the east at a 10% grade
+	plane := newGrid(32, 32, func(g *Grid, x, y int) float64 {
+		return 0.1 * float64(x) * g.CellSize(y)
+	})
+
+	t.Run("Computes slope", func(t *testing.T) {
+		slope := plane.Slope()
+		expected := math.Atan(0.1) * 180 / math.Pi
+		assert.InDelta(t, expected, slope.At(16, 16), 1e-6)
+	})
+
+	t.Run("Computes aspect", func(t *testing.T) {
+		aspect := plane.Aspect()
+		assert.InDelta(t, 270, aspect.At(16, 16), 1e-3)
+
+		flat := newGrid(4, 4, func(g *Grid, x, y int) float64 { return 10 }).Aspect()
+		assert.EqualValues(t, FlatAspect, flat.At(1, 1))
+	})
+
+	t.Run("Renders hillshade", func(t *testing.T) {
+		west := plane.Hillshade(HillshadeOpts{Azimuth: 270, Altitude: 45})
+		east := plane.Hillshade(HillshadeOpts{Azimuth: 90, Altitude: 45})
+		assert.True(t, west.GrayAt(16, 16).Y > east.GrayAt(16, 16).Y)
+
+		flat := newGrid(4, 4, func(g *Grid, x, y int) float64 { return 10 }).Hillshade(DefaultHillshade)
+		assert.InDelta(t, 255*math.Sin(math.Pi/4), float64(flat.GrayAt(1, 1).Y), 1)
+	})
+
+	t.Run("Extracts closed contours", func(t *testing.T) {
+		cone := newGrid(64, 64, func(g *Grid, x, y int) float64 {
+			return 1000 - 20*math.Hypot(float64(x)-31.5, float64(y)-31.5)
+		})
+
+		fc, err := cone.Contours(100)
+		assert.Nil(t, err)
+
+		// Levels 400 through 900 form complete rings within the grid
+		levels := make(map[float64]int)
+		for _, f := range fc.Features {
+			level := f.Properties[ContourProperty].(float64)
+			levels[level]++
+
+			if level < 400 {
+				continue
+			}
+			line := f.Geometry.LineString
+			assert.InDelta(t, line[0][0], line[len(line)-1][0], 1e-9)
+			assert.InDelta(t, line[0][1], line[len(line)-1][1], 1e-9)
+		}
+		for l := 400.0; l <= 900; l += 100 {
+			assert.EqualValues(t, 1, levels[l], "level %f", l)
+		}
+
+		_, err = cone.Contours(0)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Joins contours across stitched tile edges", func(t *testing.T) {
+		// Two 16px tiles side by side, with a plane rising to the east and south so that
+		// the 195m contour runs diagonally across the join at x = 16
+		const size = 16
+		tile := func(x uint64) maps.Tile {
+			img := image.NewNRGBA(image.Rect(0, 0, size, size))
+			for py := 0; py < size; py++ {
+				for px := 0; px < size; px++ {
+					gx := int(x-2048)*size + px
+					r, g, b := maps.HeightToPixel(float64(10*gx + 5*py))
+					img.Set(px, py, color.NRGBA{R: r, G: g, B: b, A: 255})
+				}
+			}
+			return maps.NewTile(x, 2047, 12, size, img)
+		}
+		stitched := maps.StitchTiles([][]maps.Tile{{tile(2048), tile(2049)}})
+
+		dem := NewDEM(&stitched)
+		assert.EqualValues(t, 2*size, dem.Width)
+
+		fc := dem.ContoursAt([]float64{195})
+		assert.Len(t, fc.Features, 1)
+
+		line := fc.Features[0].Geometry.LineString
+		join := dem.PixelToLocation(size, 0).Longitude
+		west, east := math.Min(line[0][0], line[len(line)-1][0]), math.Max(line[0][0], line[len(line)-1][0])
+		assert.True(t, west < join && east > join, "contour spans the join")
+		pixel := join - dem.PixelToLocation(size-1, 0).Longitude
+		for i := 1; i < len(line); i++ {
+			assert.True(t, math.Abs(line[i][0]-line[i-1][0]) <= pixel, "contour is continuous")
+		}
+	})
+}
@@ -0,0 +1,80 @@
+/**
+ * go-mapbox Tile Diff Module Image Comparison
+ * Content hashing and per-pixel differencing of tile images
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tilediff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// DefaultMaskColor is the colour used to mark changed pixels in diff masks
+var DefaultMaskColor = color.NRGBA{R: 255, A: 255}
+
+// toNRGBA converts an image to NRGBA with a zero origin
+func toNRGBA(img image.Image) *image.NRGBA {
+	b := img.Bounds()
+	if n, ok := img.(*image.NRGBA); ok && b.Min == (image.Point{}) {
+		return n
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(out, out.Bounds(), img, b.Min, draw.Src)
+	return out
+}
+
+// HashImage computes a content hash of the decoded pixels of an image
+// Hashing pixels rather than encoded data means the hash is independent of how an image was stored
+func HashImage(img image.Image) string {
+	n := toNRGBA(img)
+	h := sha256.New()
+	fmt.Fprintf(h, "%dx%d:", n.Rect.Dx(), n.Rect.Dy())
+	for y := 0; y < n.Rect.Dy(); y++ {
+		h.Write(n.Pix[y*n.Stride : y*n.Stride+n.Rect.Dx()*4])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Diff compares two images of the same size, returning a mask with changed pixels drawn in the mask colour
+// and the number of changed pixels. A pixel is changed when any channel differs by at least the threshold
+func Diff(a, b image.Image, threshold uint8, mask color.Color) (*image.NRGBA, int, error) {
+	if a.Bounds().Size() != b.Bounds().Size() {
+		return nil, 0, fmt.Errorf("Image sizes do not match (%v and %v)", a.Bounds().Size(), b.Bounds().Size())
+	}
+	if threshold == 0 {
+		threshold = 1
+	}
+
+	na, nb := toNRGBA(a), toNRGBA(b)
+	out := image.NewNRGBA(na.Rect)
+	c := color.NRGBAModel.Convert(mask).(color.NRGBA)
+
+	changed := 0
+	for y := 0; y < na.Rect.Dy(); y++ {
+		for x := 0; x < na.Rect.Dx(); x++ {
+			i := na.PixOffset(x, y)
+			j := nb.PixOffset(x, y)
+			for k := 0; k < 4; k++ {
+				d := int(na.Pix[i+k]) - int(nb.Pix[j+k])
+				if d < 0 {
+					d = -d
+				}
+				if d >= int(threshold) {
+					out.SetNRGBA(x, y, c)
+					changed++
+					break
+				}
+			}
+		}
+	}
+
+	return out, changed, nil
+}
@@ -0,0 +1,217 @@
+/**
+ * go-mapbox Tile Diff Module
+ * Detects changes in imagery and styles by re-fetching tiles and comparing them with cached versions
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tilediff
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// DefaultThreshold is the default per channel difference at which a pixel is considered changed
+// This is high enough to ignore JPG re-encoding noise from file caches
+const DefaultThreshold uint8 = 16
+
+// Fetcher fetches fresh tiles, bypassing any cache
+// This is implemented by maps.Maps
+type Fetcher interface {
+	FetchTile(mapID maps.MapID, x, y, z uint64, format maps.MapFormat, highDPI bool) (*maps.Tile, error)
+}
+
+// Opts configures change detection
+type Opts struct {
+	Threshold uint8       // Minimum per channel difference for a changed pixel, defaults to DefaultThreshold
+	MinRatio  float64     // Minimum ratio of changed pixels for a changed tile, zero reports any changed pixel
+	MaskColor color.Color // Colour of changed pixels in diff masks, defaults to DefaultMaskColor
+	Update    bool        // Replace changed and new tiles in the cache with the fetched versions
+}
+
+func withDefaults(opts *Opts) Opts {
+	o := Opts{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Threshold == 0 {
+		o.Threshold = DefaultThreshold
+	}
+	if o.MaskColor == nil {
+		o.MaskColor = DefaultMaskColor
+	}
+	return o
+}
+
+// Status is the change status of a tile
+type Status string
+
+// Tile change statuses
+const (
+	StatusUnchanged Status = "unchanged"
+	StatusChanged   Status = "changed"
+	StatusNew       Status = "new"
+)
+
+// TileChange describes the comparison of a fetched tile with its cached version
+type TileChange struct {
+	X, Y, Level   uint64
+	Status        Status
+	PreviousHash  string       // Hash of the cached tile, empty for new tiles
+	CurrentHash   string       // Hash of the fetched tile
+	ChangedPixels int          // Number of pixels exceeding the threshold
+	Ratio         float64      // Ratio of changed pixels to total pixels
+	Mask          *image.NRGBA // Diff mask for changed and new tiles, nil for unchanged tiles
+}
+
+// Report is the result of a change detection run
+type Report struct {
+	MapID   maps.MapID
+	Format  maps.MapFormat
+	HighDPI bool
+	Level   uint64
+	Tiles   []TileChange
+}
+
+// Changed returns the tiles that are changed or new
+func (r *Report) Changed() []TileChange {
+	changed := []TileChange{}
+	for _, t := range r.Tiles {
+		if t.Status != StatusUnchanged {
+			changed = append(changed, t)
+		}
+	}
+	return changed
+}
+
+// Detector compares freshly fetched tiles with a tile cache
+type Detector struct {
+	fetcher Fetcher
+	cache   maps.Cache
+	opts    Opts
+}
+
+// NewDetector creates a change detector fetching tiles with the fetcher and comparing them against the cache
+func NewDetector(fetcher Fetcher, cache maps.Cache, opts *Opts) (*Detector, error) {
+	if fetcher == nil || cache == nil {
+		return nil, fmt.Errorf("Change detection requires a fetcher and a cache")
+	}
+	return &Detector{fetcher: fetcher, cache: cache, opts: withDefaults(opts)}, nil
+}
+
+// Detect re-fetches the tiles enclosing a bounding box and reports which differ from the cache
+// Boxes crossing the antimeridian (minLng > maxLng) are supported
+func (d *Detector) Detect(mapID maps.MapID, bbox base.BoundingBox, level uint64, format maps.MapFormat, highDPI bool) (*Report, error) {
+	if len(bbox) != 4 {
+		return nil, fmt.Errorf("Bounding box must contain 4 values (received %d)", len(bbox))
+	}
+	west, south, east, north := bbox[0], bbox[1], bbox[2], bbox[3]
+	if south > north {
+		return nil, fmt.Errorf("Bounding box south (%f) must not exceed north (%f)", south, north)
+	}
+
+	xStart, yStart := maps.LocationToTileID(base.Location{Latitude: north, Longitude: west}, level)
+	xEnd, yEnd := maps.LocationToTileID(base.Location{Latitude: south, Longitude: east}, level)
+
+	// Boxes crossing the antimeridian continue past the last column and are wrapped below,
+	// limited to a single copy of each column
+	columns := uint64(1) << level
+	if west > east {
+		xEnd += columns
+	}
+	if xEnd-xStart >= columns {
+		xEnd = xStart + columns - 1
+	}
+
+	report := Report{MapID: mapID, Format: format, HighDPI: highDPI, Level: level}
+	for y := yStart; y <= yEnd; y++ {
+		for x := xStart; x <= xEnd; x++ {
+			xIndex, yIndex := maps.WrapTileID(x, y, level)
+
+			change, err := d.DetectTile(mapID, xIndex, yIndex, level, format, highDPI)
+			if err != nil {
+				return nil, err
+			}
+			report.Tiles = append(report.Tiles, *change)
+		}
+	}
+
+	return &report, nil
+}
+
+// DetectTile re-fetches a single tile and compares it with the cache
+func (d *Detector) DetectTile(mapID maps.MapID, x, y, level uint64, format maps.MapFormat, highDPI bool) (*TileChange, error) {
+	current, err := d.fetcher.FetchTile(mapID, x, y, level, format, highDPI)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, _, err := d.cache.Fetch(mapID, x, y, level, format, highDPI)
+	if err != nil {
+		return nil, fmt.Errorf("Cache fetch error (%s)", err)
+	}
+
+	change := d.compare(previous, current.Image)
+	change.X, change.Y, change.Level = x, y, level
+
+	if d.opts.Update && change.Status != StatusUnchanged {
+		if deleter, ok := d.cache.(maps.CacheDeleter); ok {
+			if err := deleter.Delete(mapID, x, y, level, format, highDPI); err != nil {
+				return nil, fmt.Errorf("Cache delete error (%s)", err)
+			}
+		}
+		if err := d.cache.Save(mapID, x, y, level, format, highDPI, current.Image); err != nil {
+			return nil, fmt.Errorf("Cache save error (%s)", err)
+		}
+	}
+
+	return change, nil
+}
+
+// compare compares a (possibly nil) cached image with a fetched image
+func (d *Detector) compare(previous, current image.Image) *TileChange {
+	change := TileChange{CurrentHash: HashImage(current)}
+	size := current.Bounds().Size()
+	total := size.X * size.Y
+
+	// New tiles, or tiles with a different size, are entirely changed
+	if previous == nil || previous.Bounds().Size() != size {
+		if previous != nil {
+			change.PreviousHash = HashImage(previous)
+			change.Status = StatusChanged
+		} else {
+			change.Status = StatusNew
+		}
+		change.Mask = image.NewNRGBA(image.Rect(0, 0, size.X, size.Y))
+		draw.Draw(change.Mask, change.Mask.Bounds(), image.NewUniform(d.opts.MaskColor), image.Point{}, draw.Src)
+		change.ChangedPixels, change.Ratio = total, 1
+		return &change
+	}
+
+	change.PreviousHash = HashImage(previous)
+	change.Status = StatusUnchanged
+	if change.PreviousHash == change.CurrentHash {
+		return &change
+	}
+
+	// Sizes are checked above so this cannot fail
+	mask, changed, _ := Diff(previous, current, d.opts.Threshold, d.opts.MaskColor)
+	change.ChangedPixels = changed
+	if total > 0 {
+		change.Ratio = float64(changed) / float64(total)
+	}
+
+	if changed > 0 && change.Ratio >= d.opts.MinRatio {
+		change.Status = StatusChanged
+		change.Mask = mask
+	}
+
+	return &change
+}
@@ -0,0 +1,155 @@
+/**
+ * go-mapbox Tile Diff Module Tests
+ * Uses a synthetic fetcher and memory cache so no API token is required
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tilediff
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// staticFetcher returns a solid tile, with an optional square drawn on a single tile
+type staticFetcher struct {
+	fill   color.NRGBA
+	edited image.Point
+	square image.Rectangle
+}
+
+func (f *staticFetcher) FetchTile(mapID maps.MapID, x, y, z uint64, format maps.MapFormat, highDPI bool) (*maps.Tile, error) {
+	img := solidImage(f.fill)
+	if int(x) == f.edited.X && int(y) == f.edited.Y {
+		draw.Draw(img, f.square, image.NewUniform(color.NRGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+	}
+	tile := maps.NewTile(x, y, z, maps.SizeStandard, img)
+	return &tile, nil
+}
+
+func solidImage(c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestDiff(t *testing.T) {
+	a := solidImage(color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	b := solidImage(color.NRGBA{R: 100, G: 100, B: 100, A: 255})
+	assert.EqualValues(t, HashImage(a), HashImage(b))
+
+	// Small differences fall below the threshold
+	b.SetNRGBA(1, 1, color.NRGBA{R: 105, G: 100, B: 100, A: 255})
+	b.SetNRGBA(2, 2, color.NRGBA{R: 100, G: 150, B: 100, A: 255})
+	assert.NotEqual(t, HashImage(a), HashImage(b))
+
+	mask, changed, err := Diff(a, b, DefaultThreshold, DefaultMaskColor)
+	assert.Nil(t, err)
+	assert.EqualValues(t, 1, changed)
+	assert.EqualValues(t, DefaultMaskColor, mask.NRGBAAt(2, 2))
+	assert.EqualValues(t, color.NRGBA{}, mask.NRGBAAt(1, 1))
+
+	_, changed, _ = Diff(a, b, 1, DefaultMaskColor)
+	assert.EqualValues(t, 2, changed)
+
+	_, _, err = Diff(a, image.NewNRGBA(image.Rect(0, 0, 512, 512)), 1, DefaultMaskColor)
+	assert.NotNil(t, err)
+}
+
+func TestDetector(t *testing.T) {
+	// Covers 2x2 tiles at level 4
+	bbox := base.BoundingBox{-20, -20, 20, 20}
+	x, y := maps.LocationToTileID(base.Location{Latitude: 10, Longitude: 10}, 4)
+	fill := color.NRGBA{R: 200, G: 200, B: 200, A: 255}
+
+	cache := maps.NewMemoryCache()
+	fetcher := &staticFetcher{fill: fill, edited: image.Point{X: -1, Y: -1}}
+
+	d, err := NewDetector(fetcher, cache, &Opts{Update: true})
+	assert.Nil(t, err)
+
+	t.Run("Reports new tiles", func(t *testing.T) {
+		report, err := d.Detect(maps.MapIDStreets, bbox, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.Len(t, report.Tiles, 4)
+		assert.Len(t, report.Changed(), 4)
+		for _, c := range report.Tiles {
+			assert.EqualValues(t, StatusNew, c.Status)
+			assert.EqualValues(t, 1, c.Ratio)
+		}
+	})
+
+	t.Run("Reports unchanged tiles", func(t *testing.T) {
+		report, err := d.Detect(maps.MapIDStreets, bbox, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.Len(t, report.Changed(), 0)
+		assert.Nil(t, report.Tiles[0].Mask)
+		assert.EqualValues(t, report.Tiles[0].PreviousHash, report.Tiles[0].CurrentHash)
+	})
+
+	t.Run("Reports changed tiles with masks", func(t *testing.T) {
+		fetcher.edited = image.Point{X: int(x), Y: int(y)}
+		fetcher.square = image.Rect(10, 10, 20, 20)
+
+		report, err := d.Detect(maps.MapIDStreets, bbox, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+
+		changed := report.Changed()
+		assert.Len(t, changed, 1)
+		assert.EqualValues(t, x, changed[0].X)
+		assert.EqualValues(t, y, changed[0].Y)
+		assert.EqualValues(t, StatusChanged, changed[0].Status)
+		assert.EqualValues(t, 100, changed[0].ChangedPixels)
+		assert.EqualValues(t, DefaultMaskColor, changed[0].Mask.NRGBAAt(15, 15))
+		assert.EqualValues(t, color.NRGBA{}, changed[0].Mask.NRGBAAt(5, 5))
+
+		// The cache was updated so the change is only reported once
+		report, err = d.Detect(maps.MapIDStreets, bbox, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.Len(t, report.Changed(), 0)
+	})
+
+	t.Run("Ignores changes below the minimum ratio", func(t *testing.T) {
+		fetcher.square = image.Rect(0, 0, 20, 20)
+
+		d, err := NewDetector(fetcher, cache, &Opts{MinRatio: 0.01})
+		assert.Nil(t, err)
+
+		change, err := d.DetectTile(maps.MapIDStreets, x, y, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.EqualValues(t, StatusUnchanged, change.Status)
+		assert.EqualValues(t, 300, change.ChangedPixels)
+
+		fetcher.square = image.Rect(0, 0, 40, 40)
+		change, err = d.DetectTile(maps.MapIDStreets, x, y, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.EqualValues(t, StatusChanged, change.Status)
+	})
+
+	t.Run("Covers bounding boxes across the antimeridian", func(t *testing.T) {
+		report, err := d.Detect(maps.MapIDStreets, base.BoundingBox{170, -20, -170, 20}, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+
+		columns := map[uint64]int{}
+		for _, c := range report.Tiles {
+			columns[c.X]++
+		}
+		assert.EqualValues(t, map[uint64]int{15: 2, 0: 2}, columns)
+
+		report, err = d.Detect(maps.MapIDStreets, base.BoundingBox{-180, -20, 180, 20}, 4, maps.MapFormatPng, false)
+		assert.Nil(t, err)
+		assert.Len(t, report.Tiles, 32)
+
+		_, err = d.Detect(maps.MapIDStreets, base.BoundingBox{-20, 20, 20, -20}, 4, maps.MapFormatPng, false)
+		assert.NotNil(t, err)
+	})
+}
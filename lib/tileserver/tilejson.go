@@ -0,0 +1,113 @@
+/**
+ * go-mapbox Tile Server Module TileJSON
+ * Generates TileJSON documents for served tilesets
+ * See https://github.com/mapbox/tilejson-spec/tree/master/3.0.0 for format information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tileserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+// TileJSONVersion is the TileJSON specification version generated
+const TileJSONVersion = "3.0.0"
+
+// Tileset describes a map ID and format published through TileJSON and WMTS
+type Tileset struct {
+	MapID   maps.MapID
+	Format  maps.MapFormat
+	MinZoom uint64
+	MaxZoom uint64
+	HighDPI bool
+	BBox    base.BoundingBox // Optional extent, defaults to the whole web mercator world
+}
+
+// bounds returns the tileset extent as west, south, east, north
+func (t *Tileset) bounds() []float64 {
+	if len(t.BBox) == 4 {
+		return []float64(t.BBox)
+	}
+	return []float64{-180, -maps.MaxLatitude, 180, maps.MaxLatitude}
+}
+
+// tileSize returns the pixel size of tiles in the tileset
+func (t *Tileset) tileSize() uint64 {
+	if t.HighDPI {
+		return maps.SizeHighDPI
+	}
+	return maps.SizeStandard
+}
+
+// TileURL returns the XYZ tile URL template for the tileset
+func (t *Tileset) TileURL(baseURL string) string {
+	dpi := ""
+	if t.HighDPI {
+		dpi = "@2x"
+	}
+	return fmt.Sprintf("%s/%s/{z}/{x}/{y}%s.%s", strings.TrimSuffix(baseURL, "/"), t.MapID, dpi, t.Format)
+}
+
+// TileJSON is a TileJSON 3.0 document
+type TileJSON struct {
+	TileJSON     string        `json:"tilejson"`
+	Name         string        `json:"name,omitempty"`
+	Description  string        `json:"description,omitempty"`
+	Attribution  string        `json:"attribution,omitempty"`
+	Scheme       string        `json:"scheme"`
+	Tiles        []string      `json:"tiles"`
+	MinZoom      uint64        `json:"minzoom"`
+	MaxZoom      uint64        `json:"maxzoom"`
+	Bounds       []float64     `json:"bounds"`
+	Center       []float64     `json:"center,omitempty"`
+	VectorLayers []interface{} `json:"vector_layers,omitempty"`
+}
+
+// Attribution is the attribution required for Mapbox tiles
+const Attribution = `<a href="https://www.mapbox.com/about/maps/">&copy; Mapbox</a> <a href="https://www.openstreetmap.org/copyright">&copy; OpenStreetMap</a>`
+
+// TileJSON generates a TileJSON document for the tileset served from the base URL
+func (t *Tileset) TileJSON(baseURL string) *TileJSON {
+	b := t.bounds()
+
+	west, east := b[0], b[2]
+	if east < west {
+		east += 360
+	}
+	centerLng := (west + east) / 2
+	if centerLng > 180 {
+		centerLng -= 360
+	}
+
+	return &TileJSON{
+		TileJSON:    TileJSONVersion,
+		Name:        string(t.MapID),
+		Attribution: Attribution,
+		Scheme:      "xyz",
+		Tiles:       []string{t.TileURL(baseURL)},
+		MinZoom:     t.MinZoom,
+		MaxZoom:     t.MaxZoom,
+		Bounds:      b,
+		Center:      []float64{centerLng, (b[1] + b[3]) / 2, float64(t.MinZoom)},
+	}
+}
+
+// requestBaseURL derives the server base URL from a request when one is not configured
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if s := r.Header.Get("X-Forwarded-Proto"); s != "" {
+		scheme = s
+	}
+	return fmt.Sprintf("%s://%s", scheme, r.Host)
+}
@@ -0,0 +1,299 @@
+/**
+ * go-mapbox Tile Server Module
+ * Serves XYZ map tiles over HTTP from the maps API and a tile cache
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tileserver
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+const (
+	// DefaultMaxAge is the default Cache-Control max age for tiles
+	DefaultMaxAge = 24 * time.Hour
+	// DefaultMaxZoom is the default maximum zoom level served
+	DefaultMaxZoom uint64 = 22
+	// HealthPath is the path of the health check endpoint
+	HealthPath = "/health"
+)
+
+// Config configures tile server restrictions and caching
+type Config struct {
+	MapIDs  []maps.MapID     // Allowed map IDs, empty allows all
+	MinZoom uint64           // Minimum zoom level served
+	MaxZoom uint64           // Maximum zoom level served, defaults to DefaultMaxZoom
+	BBox    base.BoundingBox // Optional bounding box, tiles outside of this are not served
+	MaxAge  time.Duration    // Cache-Control max age, defaults to DefaultMaxAge
+
+	Tilesets []Tileset // Tilesets published through TileJSON (/{mapID}.json) and WMTS (/wmts)
+	BaseURL  string    // Public base URL used in TileJSON and WMTS documents, defaults to the request host
+}
+
+// Server is an http.Handler serving tiles at /{mapID}/{z}/{x}/{y}{@2x}.{format}
+// along with TileJSON and WMTS endpoints for configured tilesets
+type Server struct {
+	maps   *maps.Maps
+	config Config
+}
+
+// NewServer creates a tile server using the provided maps instance (and its cache)
+func NewServer(m *maps.Maps, config Config) (*Server, error) {
+	if config.MaxZoom == 0 {
+		config.MaxZoom = DefaultMaxZoom
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = DefaultMaxAge
+	}
+	if config.MinZoom > config.MaxZoom {
+		return nil, fmt.Errorf("Minimum zoom (%d) exceeds maximum zoom (%d)", config.MinZoom, config.MaxZoom)
+	}
+	if config.BBox != nil {
+		if len(config.BBox) != 4 {
+			return nil, fmt.Errorf("Bounding box must contain 4 values (received %d)", len(config.BBox))
+		}
+		if config.BBox[1] > config.BBox[3] {
+			return nil, fmt.Errorf("Bounding box south (%f) must not exceed north (%f)", config.BBox[1], config.BBox[3])
+		}
+	}
+
+	for i := range config.Tilesets {
+		t := &config.Tilesets[i]
+		if t.MapID == "" || t.Format == "" {
+			return nil, fmt.Errorf("Tileset %d requires a map ID and format", i)
+		}
+		if t.MaxZoom == 0 {
+			t.MaxZoom = config.MaxZoom
+		}
+		if t.MinZoom > t.MaxZoom {
+			return nil, fmt.Errorf("Tileset %s minimum zoom (%d) exceeds maximum zoom (%d)", t.MapID, t.MinZoom, t.MaxZoom)
+		}
+	}
+
+	return &Server{maps: m, config: config}, nil
+}
+
+// TileRequest is a parsed tile request
+type TileRequest struct {
+	MapID   maps.MapID
+	Z, X, Y uint64
+	HighDPI bool
+	Format  maps.MapFormat
+}
+
+// ParseTilePath parses a tile path of the form /{mapID}/{z}/{x}/{y}{@2x}.{format}
+func ParseTilePath(path string) (*TileRequest, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("Tile path must be /{mapID}/{z}/{x}/{y}{@2x}.{format} (received %s)", path)
+	}
+
+	dot := strings.LastIndex(parts[3], ".")
+	if dot < 0 {
+		return nil, fmt.Errorf("Tile path is missing a format (received %s)", path)
+	}
+	yPart, format := parts[3][:dot], parts[3][dot+1:]
+
+	req := TileRequest{MapID: maps.MapID(parts[0]), Format: maps.MapFormat(format)}
+	if strings.HasSuffix(yPart, "@2x") {
+		req.HighDPI = true
+		yPart = strings.TrimSuffix(yPart, "@2x")
+	}
+
+	var err error
+	for _, v := range []struct {
+		name string
+		s    string
+		out  *uint64
+	}{{"z", parts[1], &req.Z}, {"x", parts[2], &req.X}, {"y", yPart, &req.Y}} {
+		if *v.out, err = strconv.ParseUint(v.s, 10, 64); err != nil {
+			return nil, fmt.Errorf("Invalid tile %s (%s)", v.name, v.s)
+		}
+	}
+
+	if req.Z > 63 || req.X >= 1<<req.Z || req.Y >= 1<<req.Z {
+		return nil, fmt.Errorf("Tile %d/%d/%d out of range", req.Z, req.X, req.Y)
+	}
+
+	return &req, nil
+}
+
+// Allowed checks a tile request against the server restrictions
+func (s *Server) Allowed(req *TileRequest) bool {
+	if len(s.config.MapIDs) > 0 {
+		found := false
+		for _, id := range s.config.MapIDs {
+			if id == req.MapID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if req.Z < s.config.MinZoom || req.Z > s.config.MaxZoom {
+		return false
+	}
+
+	if s.config.BBox != nil {
+		return tileIntersects(req.X, req.Y, req.Z, s.config.BBox)
+	}
+
+	return true
+}
+
+// tileIntersects checks whether a tile overlaps a bounding box, supporting boxes crossing the antimeridian
+func tileIntersects(x, y, z uint64, bbox base.BoundingBox) bool {
+	size := float64(maps.SizeStandard)
+	north, westLng := maps.MercatorPixelToLocation(float64(x)*size, float64(y)*size, z, maps.SizeStandard)
+	south, eastLng := maps.MercatorPixelToLocation(float64(x+1)*size, float64(y+1)*size, z, maps.SizeStandard)
+
+	if south > bbox[3] || north < bbox[1] {
+		return false
+	}
+
+	west, east := bbox[0], bbox[2]
+	if east < west {
+		east += 360
+	}
+	for _, offset := range []float64{-360, 0, 360} {
+		if westLng+offset < east && eastLng+offset > west {
+			return true
+		}
+	}
+	return false
+}
+
+// etag computes a strong entity tag for tile data
+func etag(data []byte) string {
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// matchesETag checks an If-None-Match header against an entity tag
+func matchesETag(header, tag string) bool {
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == tag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ServeHTTP serves tile, TileJSON, WMTS and health requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.URL.Path == HealthPath {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprint(w, `{"status":"ok"}`)
+		return
+	}
+
+	if r.URL.Path == WMTSPath || strings.HasPrefix(r.URL.Path, WMTSPath+"/") {
+		s.serveWMTS(w, r)
+		return
+	}
+
+	if id := strings.TrimPrefix(r.URL.Path, "/"); strings.HasSuffix(id, ".json") && !strings.Contains(id, "/") {
+		s.serveTileJSON(w, r, strings.TrimSuffix(id, ".json"))
+		return
+	}
+
+	req, err := ParseTilePath(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.Allowed(req) {
+		http.Error(w, "Tile not available", http.StatusNotFound)
+		return
+	}
+
+	s.serveTile(w, r, req)
+}
+
+// serveTileJSON writes the TileJSON document for a tileset
+func (s *Server) serveTileJSON(w http.ResponseWriter, r *http.Request, id string) {
+	t, ok := s.tileset(id)
+	if !ok {
+		http.Error(w, "Tileset not found", http.StatusNotFound)
+		return
+	}
+
+	data, err := json.Marshal(t.TileJSON(s.baseURL(r)))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// serveTile fetches and writes a tile with caching headers
+// Tiles are served as encoded by the API, or by the cache for caches not storing encoded data
+func (s *Server) serveTile(w http.ResponseWriter, r *http.Request, req *TileRequest) {
+	if req.Format == maps.MapFormatVectorTile {
+		http.Error(w, "Vector tiles are not supported", http.StatusBadRequest)
+		return
+	}
+	if maps.FormatContentType(req.Format) == "application/octet-stream" {
+		http.Error(w, fmt.Sprintf("Unsupported tile format (%s)", req.Format), http.StatusBadRequest)
+		return
+	}
+	if err := maps.CheckFormat(req.MapID, req.Format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.maps.GetTileData(req.MapID, req.X, req.Y, req.Z, req.Format, req.HighDPI)
+	if err == maps.ErrorTileNotFound {
+		http.Error(w, "Tile not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Tile fetch error (%s)", err)
+		http.Error(w, "Error fetching tile", http.StatusBadGateway)
+		return
+	}
+
+	tag := etag(data)
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(math.Round(s.config.MaxAge.Seconds()))))
+
+	if matchesETag(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", maps.FormatContentType(req.Format))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(data)
+}
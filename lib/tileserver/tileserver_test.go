@@ -0,0 +1,182 @@
+/**
+ * go-mapbox Tile Server Module Tests
+ * Uses a prefilled memory cache so no API token is required
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tileserver
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/base"
+	"github.com/tumasgiu/go-mapbox/lib/base/basetest"
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+func newTestServer(t *testing.T, config Config) *Server {
+	b, err := base.NewBase("synthetic")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 512, 512))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 10, G: 20, B: 30, A: 255}), image.Point{}, draw.Src)
+
+	cache := maps.NewMemoryCache()
+	cache.Save(maps.MapIDStreets, 1007, 641, 10, maps.MapFormatPng, true, img)
+	cache.Save(maps.MapIDStreets, 1007, 641, 10, maps.MapFormatJpg90, false, img.SubImage(image.Rect(0, 0, 256, 256)))
+
+	m := maps.NewMaps(b)
+	m.SetCache(cache)
+
+	s, err := NewServer(m, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func get(s *Server, path string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func TestServeUpstreamTiles(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 256))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 10, G: 20, B: 30, A: 255}), image.Point{}, draw.Src)
+	tile := bytes.Buffer{}
+	assert.Nil(t, jpeg.Encode(&tile, img, &jpeg.Options{Quality: 90}))
+
+	fetches := 0
+	b, done := basetest.NewBase(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4/mapbox.satellite/10/1007/641.jpg90":
+			fetches++
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(tile.Bytes())
+		case "/v4/mapbox.satellite/10/1007/642.jpg90":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"Tile not found"}`))
+		}
+	})
+	defer done()
+
+	m := maps.NewMaps(b)
+	m.SetCache(maps.NewMemoryCache())
+	s, err := NewServer(m, Config{})
+	assert.Nil(t, err)
+
+	// Tiles are served byte for byte, from the cache after the first fetch
+	for i := 0; i < 2; i++ {
+		w := get(s, "/mapbox.satellite/10/1007/641.jpg90", nil)
+		assert.EqualValues(t, http.StatusOK, w.Code)
+		assert.EqualValues(t, tile.Bytes(), w.Body.Bytes())
+	}
+	assert.EqualValues(t, 1, fetches)
+
+	assert.EqualValues(t, http.StatusNotFound, get(s, "/mapbox.satellite/10/0/0.jpg90", nil).Code)
+	assert.EqualValues(t, http.StatusBadGateway, get(s, "/mapbox.satellite/10/1007/642.jpg90", nil).Code)
+	assert.EqualValues(t, http.StatusBadRequest, get(s, "/mapbox.satellite/10/1007/641.png", nil).Code)
+	assert.EqualValues(t, http.StatusBadRequest, get(s, "/mapbox.satellite/10/1007/641.gif", nil).Code)
+}
+
+func TestParseTilePath(t *testing.T) {
+	req, err := ParseTilePath("/mapbox.streets/10/1007/641@2x.png")
+	assert.Nil(t, err)
+	assert.EqualValues(t, TileRequest{MapID: maps.MapIDStreets, Z: 10, X: 1007, Y: 641, HighDPI: true, Format: maps.MapFormatPng}, *req)
+
+	for _, p := range []string{"/mapbox.streets/10/1007.png", "/mapbox.streets/10/1007/641", "/mapbox.streets/a/1/1.png", "/mapbox.streets/1/2/0.png"} {
+		_, err := ParseTilePath(p)
+		assert.NotNil(t, err, "path %s", p)
+	}
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Serves tiles with caching headers", func(t *testing.T) {
+		s := newTestServer(t, Config{})
+
+		w := get(s, "/mapbox.streets/10/1007/641@2x.png", nil)
+		assert.EqualValues(t, http.StatusOK, w.Code)
+		assert.EqualValues(t, "image/png", w.Header().Get("Content-Type"))
+		assert.EqualValues(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+
+		img, err := png.Decode(w.Body)
+		assert.Nil(t, err)
+		assert.EqualValues(t, image.Rect(0, 0, 512, 512), img.Bounds())
+
+		w = get(s, "/mapbox.streets/10/1007/641.jpg90", nil)
+		assert.EqualValues(t, http.StatusOK, w.Code)
+		assert.EqualValues(t, "image/jpeg", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("Supports conditional requests", func(t *testing.T) {
+		s := newTestServer(t, Config{})
+
+		tag := get(s, "/mapbox.streets/10/1007/641@2x.png", nil).Header().Get("ETag")
+		assert.NotEmpty(t, tag)
+
+		w := get(s, "/mapbox.streets/10/1007/641@2x.png", http.Header{"If-None-Match": {tag}})
+		assert.EqualValues(t, http.StatusNotModified, w.Code)
+		assert.EqualValues(t, 0, w.Body.Len())
+
+		w = get(s, "/mapbox.streets/10/1007/641@2x.png", http.Header{"If-None-Match": {`"other"`}})
+		assert.EqualValues(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Applies restrictions", func(t *testing.T) {
+		// Tile 10/1007/641 covers part of the Wellington region
+		s := newTestServer(t, Config{
+			MapIDs:  []maps.MapID{maps.MapIDStreets},
+			MinZoom: 8,
+			MaxZoom: 12,
+			BBox:    base.BoundingBox{174.0, -41.6, 175.0, -41.0},
+		})
+
+		assert.EqualValues(t, http.StatusOK, get(s, "/mapbox.streets/10/1007/641@2x.png", nil).Code)
+		assert.EqualValues(t, http.StatusNotFound, get(s, "/mapbox.satellite/10/1007/641@2x.jpg90", nil).Code)
+		assert.EqualValues(t, http.StatusNotFound, get(s, "/mapbox.streets/13/8056/5128.png", nil).Code)
+		assert.EqualValues(t, http.StatusNotFound, get(s, "/mapbox.streets/10/0/0.png", nil).Code)
+		assert.EqualValues(t, http.StatusBadRequest, get(s, "/mapbox.streets/10/0.png", nil).Code)
+
+		_, err := NewServer(nil, Config{MinZoom: 5, MaxZoom: 2})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Reports health", func(t *testing.T) {
+		s := newTestServer(t, Config{})
+		w := get(s, HealthPath, nil)
+		assert.EqualValues(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	})
+
+	t.Run("Checks bounding boxes across the antimeridian", func(t *testing.T) {
+		bbox := base.BoundingBox{170, -20, -170, -10}
+		x, y := maps.LocationToTileID(base.Location{Latitude: -15, Longitude: 179}, 6)
+		assert.True(t, tileIntersects(x, y, 6, bbox))
+		x, y = maps.LocationToTileID(base.Location{Latitude: -15, Longitude: -175}, 6)
+		assert.True(t, tileIntersects(x, y, 6, bbox))
+		x, y = maps.LocationToTileID(base.Location{Latitude: -15, Longitude: 0}, 6)
+		assert.False(t, tileIntersects(x, y, 6, bbox))
+	})
+}
@@ -0,0 +1,346 @@
+/**
+ * go-mapbox Tile Server Module WMTS
+ * WMTS capabilities and KVP / REST GetTile requests for served tilesets
+ * See http://www.opengeospatial.org/standards/wmts for specification information
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tileserver
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+const (
+	// WMTSPath is the path prefix of WMTS requests
+	WMTSPath = "/wmts"
+	// WMTSCapabilitiesPath is the RESTful capabilities document path
+	WMTSCapabilitiesPath = WMTSPath + "/1.0.0/WMTSCapabilities.xml"
+	// WMTSVersion is the supported WMTS version
+	WMTSVersion = "1.0.0"
+)
+
+// Tile matrix set identifiers for standard and high DPI tiles
+const (
+	TileMatrixSetStandard = "WebMercatorQuad"
+	TileMatrixSetHighDPI  = "WebMercatorQuad@2x"
+)
+
+// standardizedPixelSize is the WMTS standardized rendering pixel size in metres
+const standardizedPixelSize = 0.00028
+
+// OWS exception codes
+const (
+	ExceptionMissingParameter      = "MissingParameterValue"
+	ExceptionInvalidParameter      = "InvalidParameterValue"
+	ExceptionOperationNotSupported = "OperationNotSupported"
+	ExceptionTileOutOfRange        = "TileOutOfRange"
+)
+
+// matrixSetID returns the tile matrix set identifier for a tileset
+func (t *Tileset) matrixSetID() string {
+	if t.HighDPI {
+		return TileMatrixSetHighDPI
+	}
+	return TileMatrixSetStandard
+}
+
+type tileMatrix struct {
+	Identifier       uint64
+	ScaleDenominator float64
+	TileSize         uint64
+	MatrixSize       uint64
+}
+
+type tileMatrixSet struct {
+	Identifier string
+	Matrices   []tileMatrix
+}
+
+type capabilitiesLayer struct {
+	Tileset
+	Bounds      []float64
+	MatrixSet   string
+	ContentType string
+	ResourceURL string
+}
+
+type capabilities struct {
+	BaseURL    string
+	Origin     float64
+	Layers     []capabilitiesLayer
+	MatrixSets []tileMatrixSet
+}
+
+var capabilitiesTemplate = template.Must(template.New("capabilities").Funcs(template.FuncMap{
+	"xml": func(s interface{}) (string, error) {
+		buf := bytes.Buffer{}
+		err := xml.EscapeText(&buf, []byte(fmt.Sprint(s)))
+		return buf.String(), err
+	},
+	"list": func(s ...string) []string { return s },
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<Capabilities xmlns="http://www.opengis.net/wmts/1.0" xmlns:ows="http://www.opengis.net/ows/1.1" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.0.0">
+  <ows:ServiceIdentification>
+    <ows:Title>go-mapbox tile server</ows:Title>
+    <ows:ServiceType>OGC WMTS</ows:ServiceType>
+    <ows:ServiceTypeVersion>1.0.0</ows:ServiceTypeVersion>
+  </ows:ServiceIdentification>
+  <ows:OperationsMetadata>
+{{- range $op := (list "GetCapabilities" "GetTile")}}
+    <ows:Operation name="{{$op}}">
+      <ows:DCP>
+        <ows:HTTP>
+          <ows:Get xlink:href="{{xml $.BaseURL}}/wmts?">
+            <ows:Constraint name="GetEncoding">
+              <ows:AllowedValues>
+                <ows:Value>KVP</ows:Value>
+              </ows:AllowedValues>
+            </ows:Constraint>
+          </ows:Get>
+        </ows:HTTP>
+      </ows:DCP>
+    </ows:Operation>
+{{- end}}
+  </ows:OperationsMetadata>
+  <Contents>
+{{- range .Layers}}
+    <Layer>
+      <ows:Title>{{xml .MapID}}</ows:Title>
+      <ows:Identifier>{{xml .MapID}}</ows:Identifier>
+      <ows:WGS84BoundingBox>
+        <ows:LowerCorner>{{index .Bounds 0}} {{index .Bounds 1}}</ows:LowerCorner>
+        <ows:UpperCorner>{{index .Bounds 2}} {{index .Bounds 3}}</ows:UpperCorner>
+      </ows:WGS84BoundingBox>
+      <Style isDefault="true">
+        <ows:Identifier>default</ows:Identifier>
+      </Style>
+      <Format>{{.ContentType}}</Format>
+      <TileMatrixSetLink>
+        <TileMatrixSet>{{.MatrixSet}}</TileMatrixSet>
+      </TileMatrixSetLink>
+      <ResourceURL format="{{.ContentType}}" resourceType="tile" template="{{xml .ResourceURL}}"/>
+    </Layer>
+{{- end}}
+{{- range .MatrixSets}}
+    <TileMatrixSet>
+      <ows:Identifier>{{.Identifier}}</ows:Identifier>
+      <ows:SupportedCRS>urn:ogc:def:crs:EPSG::3857</ows:SupportedCRS>
+{{- range .Matrices}}
+      <TileMatrix>
+        <ows:Identifier>{{.Identifier}}</ows:Identifier>
+        <ScaleDenominator>{{.ScaleDenominator}}</ScaleDenominator>
+        <TopLeftCorner>-{{$.Origin}} {{$.Origin}}</TopLeftCorner>
+        <TileWidth>{{.TileSize}}</TileWidth>
+        <TileHeight>{{.TileSize}}</TileHeight>
+        <MatrixWidth>{{.MatrixSize}}</MatrixWidth>
+        <MatrixHeight>{{.MatrixSize}}</MatrixHeight>
+      </TileMatrix>
+{{- end}}
+    </TileMatrixSet>
+{{- end}}
+  </Contents>
+</Capabilities>
+`))
+
+// WriteCapabilities writes a WMTS GetCapabilities document for the tilesets served from the base URL
+func WriteCapabilities(w io.Writer, baseURL string, tilesets []Tileset) error {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	c := capabilities{
+		BaseURL: baseURL,
+		Origin:  maps.MercatorRadius * math.Pi,
+	}
+
+	maxZoom := map[string]uint64{}
+	for _, t := range tilesets {
+		id := t.matrixSetID()
+		if z, ok := maxZoom[id]; !ok || t.MaxZoom > z {
+			maxZoom[id] = t.MaxZoom
+		}
+
+		c.Layers = append(c.Layers, capabilitiesLayer{
+			Tileset:     t,
+			Bounds:      t.bounds(),
+			MatrixSet:   id,
+			ContentType: maps.FormatContentType(t.Format),
+			ResourceURL: fmt.Sprintf("%s%s/%s/{TileMatrixSet}/{TileMatrix}/{TileCol}/{TileRow}.%s", baseURL, WMTSPath, t.MapID, t.Format),
+		})
+	}
+
+	ids := make([]string, 0, len(maxZoom))
+	for id := range maxZoom {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		size := maps.SizeStandard
+		if id == TileMatrixSetHighDPI {
+			size = maps.SizeHighDPI
+		}
+		set := tileMatrixSet{Identifier: id}
+		for z := uint64(0); z <= maxZoom[id]; z++ {
+			set.Matrices = append(set.Matrices, tileMatrix{
+				Identifier:       z,
+				ScaleDenominator: maps.MercatorResolution(z, size) / standardizedPixelSize,
+				TileSize:         size,
+				MatrixSize:       1 << z,
+			})
+		}
+		c.MatrixSets = append(c.MatrixSets, set)
+	}
+
+	return capabilitiesTemplate.Execute(w, &c)
+}
+
+// wmtsError writes an OWS exception report
+func wmtsError(w http.ResponseWriter, status int, code, locator, message string) {
+	text := bytes.Buffer{}
+	xml.EscapeText(&text, []byte(message))
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ExceptionReport xmlns="http://www.opengis.net/ows/1.1" version="1.1.0" xml:lang="en">
+  <Exception exceptionCode="%s" locator="%s">
+    <ExceptionText>%s</ExceptionText>
+  </Exception>
+</ExceptionReport>
+`, code, locator, text.String())
+}
+
+// tileset finds a published tileset by map ID
+func (s *Server) tileset(id string) (*Tileset, bool) {
+	for i := range s.config.Tilesets {
+		if string(s.config.Tilesets[i].MapID) == id {
+			return &s.config.Tilesets[i], true
+		}
+	}
+	return nil, false
+}
+
+// baseURL returns the configured base URL or derives one from the request
+func (s *Server) baseURL(r *http.Request) string {
+	if s.config.BaseURL != "" {
+		return strings.TrimSuffix(s.config.BaseURL, "/")
+	}
+	return requestBaseURL(r)
+}
+
+// serveCapabilities writes the WMTS capabilities document
+func (s *Server) serveCapabilities(w http.ResponseWriter, r *http.Request) {
+	buf := bytes.Buffer{}
+	if err := WriteCapabilities(&buf, s.baseURL(r), s.config.Tilesets); err != nil {
+		wmtsError(w, http.StatusInternalServerError, "NoApplicableCode", "", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write(buf.Bytes())
+}
+
+// serveWMTS handles KVP and RESTful WMTS requests
+func (s *Server) serveWMTS(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == WMTSCapabilitiesPath {
+		s.serveCapabilities(w, r)
+		return
+	}
+
+	// RESTful tiles: /wmts/{layer}/{TileMatrixSet}/{TileMatrix}/{TileCol}/{TileRow}.{format}
+	if rest := strings.Trim(strings.TrimPrefix(r.URL.Path, WMTSPath), "/"); rest != "" {
+		parts := strings.Split(rest, "/")
+		if len(parts) != 5 {
+			wmtsError(w, http.StatusNotFound, ExceptionInvalidParameter, "path", "Unrecognised WMTS path")
+			return
+		}
+		row := parts[4]
+		if dot := strings.LastIndex(row, "."); dot >= 0 {
+			row = row[:dot]
+		}
+		s.serveWMTSTile(w, r, parts[0], parts[1], parts[2], parts[3], row)
+		return
+	}
+
+	// KVP parameter names are case insensitive
+	params := map[string]string{}
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			params[strings.ToUpper(k)] = v[0]
+		}
+	}
+
+	if service := params["SERVICE"]; !strings.EqualFold(service, "WMTS") {
+		wmtsError(w, http.StatusBadRequest, ExceptionInvalidParameter, "service", fmt.Sprintf("Unsupported service (%s)", service))
+		return
+	}
+
+	switch request := params["REQUEST"]; request {
+	case "GetCapabilities":
+		s.serveCapabilities(w, r)
+	case "GetTile":
+		for _, p := range []string{"LAYER", "TILEMATRIXSET", "TILEMATRIX", "TILEROW", "TILECOL"} {
+			if params[p] == "" {
+				wmtsError(w, http.StatusBadRequest, ExceptionMissingParameter, strings.ToLower(p), fmt.Sprintf("Missing parameter %s", p))
+				return
+			}
+		}
+		s.serveWMTSTile(w, r, params["LAYER"], params["TILEMATRIXSET"], params["TILEMATRIX"], params["TILECOL"], params["TILEROW"])
+	case "":
+		wmtsError(w, http.StatusBadRequest, ExceptionMissingParameter, "request", "Missing parameter REQUEST")
+	default:
+		wmtsError(w, http.StatusBadRequest, ExceptionOperationNotSupported, "request", fmt.Sprintf("Unsupported request (%s)", request))
+	}
+}
+
+// serveWMTSTile maps a WMTS tile request onto a tile request
+func (s *Server) serveWMTSTile(w http.ResponseWriter, r *http.Request, layer, matrixSet, matrix, col, row string) {
+	t, ok := s.tileset(layer)
+	if !ok {
+		wmtsError(w, http.StatusBadRequest, ExceptionInvalidParameter, "layer", fmt.Sprintf("Unknown layer (%s)", layer))
+		return
+	}
+	if matrixSet != t.matrixSetID() {
+		wmtsError(w, http.StatusBadRequest, ExceptionInvalidParameter, "tilematrixset", fmt.Sprintf("Unknown tile matrix set (%s)", matrixSet))
+		return
+	}
+
+	values := make([]uint64, 3)
+	for i, v := range []struct{ name, s string }{{"tilematrix", matrix}, {"tilecol", col}, {"tilerow", row}} {
+		n, err := strconv.ParseUint(v.s, 10, 64)
+		if err != nil {
+			wmtsError(w, http.StatusBadRequest, ExceptionInvalidParameter, v.name, fmt.Sprintf("Invalid %s (%s)", v.name, v.s))
+			return
+		}
+		values[i] = n
+	}
+	z, x, y := values[0], values[1], values[2]
+
+	if z < t.MinZoom || z > t.MaxZoom {
+		wmtsError(w, http.StatusBadRequest, ExceptionInvalidParameter, "tilematrix", fmt.Sprintf("Tile matrix %d not available", z))
+		return
+	}
+	if x >= 1<<z || y >= 1<<z {
+		wmtsError(w, http.StatusBadRequest, ExceptionTileOutOfRange, "tilecol", fmt.Sprintf("Tile %d/%d/%d out of range", z, x, y))
+		return
+	}
+
+	req := TileRequest{MapID: t.MapID, Z: z, X: x, Y: y, HighDPI: t.HighDPI, Format: t.Format}
+	if !s.Allowed(&req) {
+		wmtsError(w, http.StatusNotFound, ExceptionTileOutOfRange, "tilecol", "Tile not available")
+		return
+	}
+
+	s.serveTile(w, r, &req)
+}
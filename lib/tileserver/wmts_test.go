@@ -0,0 +1,147 @@
+/**
+ * go-mapbox Tile Server Module TileJSON and WMTS Tests
+ *
+ * https://github.com/tumasgiu/go-mapbox
+ * Copyright 2017 Ryan Kurte
+ */
+
+package tileserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"image/png"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tumasgiu/go-mapbox/lib/maps"
+)
+
+func TestTileJSON(t *testing.T) {
+	s := newTestServer(t, Config{
+		BaseURL:  "https://tiles.example.com/",
+		Tilesets: []Tileset{{MapID: maps.MapIDStreets, Format: maps.MapFormatPng, MinZoom: 2, MaxZoom: 12, HighDPI: true}},
+	})
+
+	w := get(s, "/mapbox.streets.json", nil)
+	assert.EqualValues(t, http.StatusOK, w.Code)
+	assert.EqualValues(t, "application/json", w.Header().Get("Content-Type"))
+
+	doc := TileJSON{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.EqualValues(t, TileJSONVersion, doc.TileJSON)
+	assert.EqualValues(t, []string{"https://tiles.example.com/mapbox.streets/{z}/{x}/{y}@2x.png"}, doc.Tiles)
+	assert.EqualValues(t, 2, doc.MinZoom)
+	assert.EqualValues(t, 12, doc.MaxZoom)
+	assert.EqualValues(t, []float64{-180, -maps.MaxLatitude, 180, maps.MaxLatitude}, doc.Bounds)
+
+	assert.EqualValues(t, http.StatusNotFound, get(s, "/mapbox.satellite.json", nil).Code)
+
+	// Bounds crossing the antimeridian are centred correctly
+	ts := Tileset{MapID: maps.MapIDStreets, Format: maps.MapFormatPng, BBox: []float64{170, -20, -170, -10}}
+	assert.InDelta(t, 180, ts.TileJSON("").Center[0], 1e-9)
+	assert.InDelta(t, -15, ts.TileJSON("").Center[1], 1e-9)
+
+	_, err := NewServer(nil, Config{Tilesets: []Tileset{{MapID: maps.MapIDStreets}}})
+	assert.NotNil(t, err)
+}
+
+func TestWMTS(t *testing.T) {
+	tilesets := []Tileset{
+		{MapID: maps.MapIDStreets, Format: maps.MapFormatPng, MaxZoom: 12, HighDPI: true},
+		{MapID: maps.MapIDStreets + ".jpg", Format: maps.MapFormatJpg90, MaxZoom: 4},
+	}
+
+	t.Run("Writes capabilities", func(t *testing.T) {
+		buf := bytes.Buffer{}
+		assert.Nil(t, WriteCapabilities(&buf, "https://tiles.example.com", tilesets))
+
+		var doc struct {
+			Layers []struct {
+				Identifier  string `xml:"Identifier"`
+				MatrixSet   string `xml:"TileMatrixSetLink>TileMatrixSet"`
+				ResourceURL struct {
+					Template string `xml:"template,attr"`
+				} `xml:"ResourceURL"`
+			} `xml:"Contents>Layer"`
+			MatrixSets []struct {
+				Identifier string `xml:"Identifier"`
+				Matrices   []struct {
+					ScaleDenominator float64 `xml:"ScaleDenominator"`
+					TileWidth        uint64  `xml:"TileWidth"`
+				} `xml:"TileMatrix"`
+			} `xml:"Contents>TileMatrixSet"`
+		}
+		assert.Nil(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+		assert.Len(t, doc.Layers, 2)
+		assert.EqualValues(t, "mapbox.streets", doc.Layers[0].Identifier)
+		assert.EqualValues(t, TileMatrixSetHighDPI, doc.Layers[0].MatrixSet)
+		assert.EqualValues(t, "https://tiles.example.com/wmts/mapbox.streets/{TileMatrixSet}/{TileMatrix}/{TileCol}/{TileRow}.png", doc.Layers[0].ResourceURL.Template)
+
+		assert.Len(t, doc.MatrixSets, 2)
+		assert.EqualValues(t, TileMatrixSetStandard, doc.MatrixSets[0].Identifier)
+		assert.Len(t, doc.MatrixSets[0].Matrices, 5)
+		assert.InDelta(t, 559082264.03, doc.MatrixSets[0].Matrices[0].ScaleDenominator, 0.01)
+		assert.EqualValues(t, 256, doc.MatrixSets[0].Matrices[0].TileWidth)
+
+		assert.EqualValues(t, TileMatrixSetHighDPI, doc.MatrixSets[1].Identifier)
+		assert.Len(t, doc.MatrixSets[1].Matrices, 13)
+		assert.InDelta(t, 279541132.01, doc.MatrixSets[1].Matrices[0].ScaleDenominator, 0.01)
+	})
+
+	t.Run("Serves capabilities", func(t *testing.T) {
+		s := newTestServer(t, Config{Tilesets: tilesets})
+
+		for _, p := range []string{WMTSCapabilitiesPath, "/wmts?SERVICE=WMTS&REQUEST=GetCapabilities", "/wmts?service=wmts&request=GetCapabilities"} {
+			w := get(s, p, nil)
+			assert.EqualValues(t, http.StatusOK, w.Code, "path %s", p)
+			assert.EqualValues(t, "application/xml", w.Header().Get("Content-Type"))
+			assert.True(t, strings.Contains(w.Body.String(), `xlink:href="http://example.com/wmts?"`))
+		}
+	})
+
+	t.Run("Serves KVP and RESTful tiles", func(t *testing.T) {
+		s := newTestServer(t, Config{Tilesets: tilesets})
+
+		for _, p := range []string{
+			"/wmts?SERVICE=WMTS&REQUEST=GetTile&VERSION=1.0.0&LAYER=mapbox.streets&STYLE=default&TILEMATRIXSET=WebMercatorQuad@2x&TILEMATRIX=10&TILEROW=641&TILECOL=1007&FORMAT=image/png",
+			"/wmts/mapbox.streets/WebMercatorQuad@2x/10/1007/641.png",
+		} {
+			w := get(s, p, nil)
+			assert.EqualValues(t, http.StatusOK, w.Code, "path %s", p)
+			assert.EqualValues(t, "image/png", w.Header().Get("Content-Type"))
+			img, err := png.Decode(w.Body)
+			assert.Nil(t, err)
+			assert.EqualValues(t, 512, img.Bounds().Dx())
+		}
+	})
+
+	t.Run("Reports exceptions", func(t *testing.T) {
+		s := newTestServer(t, Config{Tilesets: tilesets})
+
+		for p, code := range map[string]string{
+			"/wmts?SERVICE=WMTS&REQUEST=GetTile&TILEMATRIXSET=WebMercatorQuad&TILEMATRIX=1&TILEROW=0&TILECOL=0":             ExceptionMissingParameter,
+			"/wmts?SERVICE=WMTS&REQUEST=GetTile&LAYER=other&TILEMATRIXSET=WebMercatorQuad&TILEMATRIX=1&TILEROW=0&TILECOL=0": ExceptionInvalidParameter,
+			"/wmts?SERVICE=WMTS&REQUEST=GetFeatureInfo":                                                                     ExceptionOperationNotSupported,
+			"/wmts/mapbox.streets/WebMercatorQuad/10/1007/641.png":                                                          ExceptionInvalidParameter,
+			"/wmts/mapbox.streets/WebMercatorQuad@2x/13/1007/641.png":                                                       ExceptionInvalidParameter,
+			"/wmts/mapbox.streets/WebMercatorQuad@2x/2/4/0.png":                                                             ExceptionTileOutOfRange,
+		} {
+			w := get(s, p, nil)
+			assert.EqualValues(t, http.StatusBadRequest, w.Code, "path %s", p)
+
+			var report struct {
+				Exception struct {
+					Code string `xml:"exceptionCode,attr"`
+				}
+			}
+			assert.Nil(t, xml.Unmarshal(w.Body.Bytes(), &report))
+			assert.EqualValues(t, code, report.Exception.Code, "path %s", p)
+		}
+	})
+}